@@ -0,0 +1,162 @@
+package uniqid
+
+import "fmt"
+
+// SortableAlphabet holds the default alphabet's characters in ASCII
+// order. Used as Config.Alphabet, it makes IDs sort byte-wise (e.g.
+// in a database index or an object store listing) in generation order
+// without decoding. The default alphabet keeps base64url's digit order
+// and does not.
+//
+// Example:
+//
+//	gen, err := uniqid.New(&uniqid.Config{ShardID: 1, Alphabet: uniqid.SortableAlphabet})
+const SortableAlphabet = "-0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZ_abcdefghijklmnopqrstuvwxyz"
+
+// Encoding selects how IDs are rendered as text; see Config.Encoding.
+type Encoding int
+
+const (
+	// EncodingBase64 uses one character of Config.Alphabet, by default
+	// the URL-safe base64 characters, per 6 bits: 11 characters for a
+	// 64-bit layout. This is the default.
+	EncodingBase64 Encoding = iota
+	// EncodingCrockford uses Crockford's Base32, one character of
+	// 0-9 and A-Z without I, L, O and U per 5 bits: 13 characters for
+	// a 64-bit layout. IDs are upper case, but decoding ignores case
+	// and reads the look-alikes I and L as 1 and O as 0, so IDs
+	// survive being read aloud or retyped. Its characters are in
+	// ASCII order, so IDs also sort as strings.
+	EncodingCrockford
+)
+
+// crockfordAlphabet is Crockford's Base32 digit set.
+const crockfordAlphabet = "0123456789ABCDEFGHJKMNPQRSTVWXYZ"
+
+// ValidateAlphabet reports whether a is usable as Config.Alphabet: it
+// must be exactly 64 bytes of printable, non-space ASCII with no
+// character repeated. Duplicates would make decoding ambiguous, and
+// control characters or whitespace could corrupt logs and URLs. The
+// error names the offending byte and its position.
+func ValidateAlphabet(a string) error {
+	if len(a) != 64 {
+		return fmt.Errorf("alphabet must be 64 characters, got %d", len(a))
+	}
+	var first [256]int
+	for i := 0; i < len(a); i++ {
+		c := a[i]
+		if c <= ' ' || c > '~' {
+			return fmt.Errorf("alphabet has illegal byte %#02x at position %d", c, i)
+		}
+		if first[c] != 0 {
+			return fmt.Errorf("alphabet has duplicate character %q at positions %d and %d", c, first[c]-1, i)
+		}
+		first[c] = i + 1
+	}
+	return nil
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// codec encodes and decodes packed values with one alphabet of 64
+// (6 bits per character) or 32 (5 bits) characters.
+type codec struct {
+	alphabet string
+	bits     int
+	// table maps an alphabet byte back to its value.
+	// Bytes outside the alphabet map to 0xFF.
+	table [256]byte
+}
+
+// defaultCodec uses the package's default alphabet.
+var defaultCodec = newCodec(alphabet)
+
+// crockfordCodec implements EncodingCrockford.
+var crockfordCodec = newCrockfordCodec()
+
+// newCodec builds the codec for a valid alphabet.
+func newCodec(a string) *codec {
+	c := &codec{alphabet: a, bits: 6}
+	if len(a) == 32 {
+		c.bits = 5
+	}
+	for i := range c.table {
+		c.table[i] = 0xFF
+	}
+	for i := 0; i < len(a); i++ {
+		c.table[a[i]] = byte(i)
+	}
+	return c
+}
+
+// newCrockfordCodec builds the codec for Crockford's Base32, which
+// also accepts lower case and the look-alikes I, L and O.
+func newCrockfordCodec() *codec {
+	c := newCodec(crockfordAlphabet)
+	for i := 0; i < len(crockfordAlphabet); i++ {
+		if ch := crockfordAlphabet[i]; ch >= 'A' {
+			c.table[ch+'a'-'A'] = byte(i)
+		}
+	}
+	for _, ch := range "IiLl" {
+		c.table[ch] = 1
+	}
+	c.table['O'], c.table['o'] = 0, 0
+	return c
+}
+
+// codecFor returns the codec for an encoding and a valid alphabet.
+func codecFor(e Encoding, a string) *codec {
+	switch {
+	case e == EncodingCrockford:
+		return crockfordCodec
+	case a != alphabet:
+		return newCodec(a)
+	}
+	return defaultCodec
+}
+
+// chars returns the number of characters needed to encode layout l.
+func (c *codec) chars(l Layout) int {
+	return (l.Bits() + c.bits - 1) / c.bits
+}
+
+// encode writes val into dst using one alphabet character per c.bits
+// bits, most significant first, filling all of dst. Small values are
+// left-padded with the zero character (alphabet[0]), so every ID of a
+// layout has the same width regardless of magnitude.
+func (c *codec) encode(dst []byte, val uint64) {
+	mask := uint64(len(c.alphabet) - 1)
+	for i := len(dst) - 1; i >= 0; i-- {
+		dst[i] = c.alphabet[val&mask]
+		val >>= uint(c.bits)
+	}
+}
+
+// decode converts an encoded ID of layout l back to its packed value.
+// Separators inserted by NextDelimited are ignored.
+func (c *codec) decode(id string, l Layout) (uint64, error) {
+	n := c.chars(l)
+	if len(id) != n {
+		var ok bool
+		if id, ok = c.undelimit(id, n); !ok {
+			return 0, ErrInvalidID
+		}
+	}
+	var val uint64
+	for i := 0; i < n; i++ {
+		v := c.table[id[i]]
+		if v == 0xFF {
+			return 0, ErrInvalidID
+		}
+		val = val<<uint(c.bits) | uint64(v)
+	}
+	// The first character only carries the bits left over above the
+	// remaining characters; anything more would overflow the layout.
+	if top := l.Bits() - c.bits*(n-1); c.table[id[0]]>>top != 0 {
+		return 0, ErrInvalidID
+	}
+	return val, nil
+}
@@ -0,0 +1,204 @@
+package uniqid
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestValidateAlphabet tests rejecting unusable alphabets
+func TestValidateAlphabet(t *testing.T) {
+	// Test case 1: Valid alphabets
+	for _, a := range []string{alphabet, SortableAlphabet} {
+		if err := ValidateAlphabet(a); err != nil {
+			t.Errorf("Expected %q to be valid, got %v", a, err)
+		}
+	}
+
+	// Test case 2: Invalid alphabets name the problem
+	cases := []struct {
+		name, alphabet, want string
+	}{
+		{"short", alphabet[:63], "64 characters, got 63"},
+		{"duplicate", "A" + alphabet[1:10] + "A" + alphabet[11:], `duplicate character 'A' at positions 0 and 10`},
+		{"space", alphabet[:5] + " " + alphabet[6:], "illegal byte 0x20 at position 5"},
+		{"tab", alphabet[:63] + "\t", "illegal byte 0x09 at position 63"},
+		{"control", "\x00" + alphabet[1:], "illegal byte 0x00 at position 0"},
+		{"non-ASCII", alphabet[:62] + "é", "illegal byte 0xc3 at position 62"},
+	}
+	for _, c := range cases {
+		err := ValidateAlphabet(c.alphabet)
+		if err == nil || !strings.Contains(err.Error(), c.want) {
+			t.Errorf("%s: expected error containing %q, got %v", c.name, c.want, err)
+		}
+		if _, err := New(&Config{ShardID: 1, Alphabet: c.alphabet}); err == nil {
+			t.Errorf("%s: expected New to reject the alphabet", c.name)
+		}
+	}
+}
+
+// TestCustomAlphabet tests generating and parsing IDs with a custom alphabet
+func TestCustomAlphabet(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	gen, err := New(&Config{ShardID: 5, Alphabet: SortableAlphabet, VersionPrefix: '-'})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	// Test case 1: IDs use only the custom alphabet and round-trip
+	id := gen.Next()
+	for i := 1; i < len(id); i++ {
+		if !strings.ContainsRune(SortableAlphabet, rune(id[i])) {
+			t.Errorf("ID %q has character %q outside the alphabet", id, id[i])
+		}
+	}
+	parts, err := gen.Parse(id)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if parts.Shard != 5 || parts.Time.UnixMilli() != mockTime {
+		t.Errorf("Unexpected parts: %+v", parts)
+	}
+
+	// Test case 2: The same value encodes differently than the default
+	def, _ := New(&Config{ShardID: 5, VersionPrefix: '-'})
+	def.deps.nowFunc = gen.deps.nowFunc
+	def.lastMs, def.seq = gen.lastMs, gen.seq
+	if gen.Next() == def.Next() {
+		t.Error("Expected custom alphabet to change the encoding")
+	}
+
+	// Test case 3: Version prefix must come from the custom alphabet
+	if _, err := New(&Config{ShardID: 1, Alphabet: SortableAlphabet[1:] + "!", VersionPrefix: '-'}); err == nil {
+		t.Error("Expected error for prefix outside the custom alphabet, got nil")
+	}
+
+	// Test case 4: Config and exported state carry the alphabet
+	if a := gen.Config().Alphabet; a != SortableAlphabet {
+		t.Errorf("Expected Config().Alphabet %q, got %q", SortableAlphabet, a)
+	}
+	if a := def.Config().Alphabet; a != alphabet {
+		t.Errorf("Expected default Config().Alphabet %q, got %q", alphabet, a)
+	}
+	b, _ := gen.ExportJSON()
+	imported, err := ImportJSON(b)
+	if err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+	if _, err := imported.Parse(id); err != nil {
+		t.Errorf("Expected imported generator to parse %q, got %v", id, err)
+	}
+
+	// Test case 5: Tokens use the custom alphabet too
+	tok, _ := New(&Config{ShardID: 5, Alphabet: SortableAlphabet, TokenKey: bytes.Repeat([]byte("k"), 16)})
+	s, _ := tok.NextToken()
+	back, err := tok.DecodeToken(s)
+	if err != nil {
+		t.Fatalf("DecodeToken failed: %v", err)
+	}
+	if _, err := tok.Parse(back); err != nil {
+		t.Errorf("Expected decoded token to parse, got %v", err)
+	}
+}
+
+// TestSortableAlphabet tests that IDs sort as strings in generation order
+func TestSortableAlphabet(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	gen, err := New(&Config{ShardID: 5, Alphabet: SortableAlphabet})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	var ids []string
+	for ms := 0; ms < 70; ms++ {
+		// Cross character boundaries of the sequence and timestamp.
+		for i := 0; i < 70; i++ {
+			ids = append(ids, gen.Next())
+		}
+		mockTime++
+	}
+	if !slices.IsSorted(ids) {
+		t.Error("Expected IDs to sort as strings in generation order")
+	}
+}
+
+// TestCrockfordEncoding tests rendering IDs in Crockford's Base32
+func TestCrockfordEncoding(t *testing.T) {
+	gen, err := New(&Config{ShardID: 5, Encoding: EncodingCrockford})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	// Test case 1: IDs are 13 upper-case Crockford characters
+	id := gen.Next()
+	if len(id) != 13 || gen.Len() != 13 {
+		t.Fatalf("Expected 13 characters, got %q (Len %d)", id, gen.Len())
+	}
+	for i := 0; i < len(id); i++ {
+		if !strings.ContainsRune(crockfordAlphabet, rune(id[i])) {
+			t.Errorf("ID %q has character %q outside the alphabet", id, id[i])
+		}
+	}
+	want, err := gen.Parse(id)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if want.Shard != 5 {
+		t.Errorf("Unexpected parts: %+v", want)
+	}
+
+	// Test case 2: Decoding forgives case, look-alikes and hyphens
+	misread := strings.NewReplacer("0", "o", "1", "L").Replace(strings.ToLower(id))
+	grouped, _ := gen.NextDelimited(4, '-')
+	for _, s := range []string{strings.ToLower(id), misread, strings.ReplaceAll(id, "1", "I")} {
+		if p, err := gen.Parse(s); err != nil || p != want {
+			t.Errorf("Parse(%q) = %+v, %v; expected %+v", s, p, err, want)
+		}
+	}
+	if _, err := gen.Parse(grouped); err != nil {
+		t.Errorf("Parse(%q) failed: %v", grouped, err)
+	}
+	if _, err := gen.Parse(id[:12] + "U"); err != ErrInvalidID {
+		t.Errorf("Expected ErrInvalidID for U, got %v", err)
+	}
+
+	// Test case 3: IDs sort as strings
+	if err := CheckSortable(&Config{Encoding: EncodingCrockford}); err != nil {
+		t.Errorf("Expected Crockford IDs to be sortable, got %v", err)
+	}
+
+	// Test case 4: Config, ParseWith and exported state carry the encoding
+	cfg := gen.Config()
+	if p, err := ParseWith(id, &cfg); err != nil || p != want {
+		t.Errorf("ParseWith = %+v, %v; expected %+v", p, err, want)
+	}
+	if _, err := New(&cfg); err != nil {
+		t.Errorf("New(Config()) failed: %v", err)
+	}
+	b, _ := gen.ExportJSON()
+	imported, err := ImportJSON(b)
+	if err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+	if imported.Len() != 13 {
+		t.Errorf("Expected imported generator to use Crockford, got Len %d", imported.Len())
+	}
+
+	// Test case 5: Invalid combinations
+	for _, bad := range []*Config{
+		{ShardID: 1, Encoding: EncodingCrockford, Alphabet: SortableAlphabet},
+		{ShardID: 1, Encoding: EncodingCrockford, VersionPrefix: 'v'},
+		{ShardID: 1, Encoding: Encoding(9)},
+	} {
+		if _, err := New(bad); err == nil {
+			t.Errorf("Expected error for %+v, got nil", bad)
+		}
+	}
+	if _, err := New(&Config{ShardID: 1, Encoding: EncodingCrockford, VersionPrefix: 'V'}); err != nil {
+		t.Errorf("Expected upper-case prefix to be accepted, got %v", err)
+	}
+}
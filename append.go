@@ -0,0 +1,73 @@
+package uniqid
+
+import "errors"
+
+// The Next variants below all generate one ID and differ only in how
+// it is returned; they share one encoder, so for the same generator
+// state they produce the same characters:
+//
+//   - Next, NextString: a string, the usual choice.
+//   - NextBytes: an [11]byte array, no heap allocation, for fixed-size
+//     keys and struct fields with the default 11-character format.
+//   - AppendNext: appended to a caller-owned buffer, no allocation
+//     once the buffer has capacity; for building output in hot loops.
+//   - NextRaw: a freshly allocated []byte the caller may modify.
+//   - NextDecoded: a string together with its Parts, for callers that
+//     need the fields right away.
+
+// ErrNotElevenChars is returned by NextBytes when the generator's IDs
+// are not 11 characters long.
+var ErrNotElevenChars = errors.New("IDs are not 11 characters long")
+
+// NextString is an alias for Next, for symmetry with NextBytes and
+// NextRaw.
+func (g *Generator) NextString() string {
+	return g.Next()
+}
+
+// NextBytes generates a new ID as a fixed-size array. It is meant for
+// generators producing the default 11-character IDs and returns
+// ErrNotElevenChars, without generating, if g.Len() is not 11, e.g.
+// with a Prefix, Checksum or EncodingCrockford; use AppendNext for
+// those.
+func (g *Generator) NextBytes() ([11]byte, error) {
+	var out [11]byte
+	if g.Len() != 11 {
+		return out, ErrNotElevenChars
+	}
+	val, _ := g.next(true)
+	g.appendID(out[:0], val)
+	return out, nil
+}
+
+// AppendNext generates a new ID and appends it to dst, returning the
+// extended slice like the built-in append.
+//
+// Example:
+//
+//	buf = gen.AppendNext(buf[:0])
+func (g *Generator) AppendNext(dst []byte) []byte {
+	val, _ := g.next(true)
+	return g.appendID(dst, val)
+}
+
+// NextRaw generates a new ID as a newly allocated byte slice of length
+// g.Len(), owned by the caller.
+func (g *Generator) NextRaw() []byte {
+	return g.AppendNext(make([]byte, 0, g.Len()))
+}
+
+// NextDecoded generates a new ID and returns it together with its
+// components, taken from the value just encoded rather than by parsing
+// the string, so they always match what Generator.Parse would return.
+//
+// Example:
+//
+//	id, p := gen.NextDecoded()
+//	event := Event{ID: id, CreatedAt: p.Time, Shard: p.Shard}
+func (g *Generator) NextDecoded() (string, Parts) {
+	val, _ := g.next(true)
+	// g's own values always carry its salt, so partsOf cannot fail.
+	p, _ := g.partsOf(val)
+	return g.format(val), p
+}
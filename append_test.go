@@ -0,0 +1,117 @@
+package uniqid
+
+import (
+	"testing"
+	"time"
+)
+
+// TestNextVariants tests that all Next variants encode the same state identically
+func TestNextVariants(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	newGen := func(cfg *Config) *Generator {
+		gen, _ := New(cfg)
+		gen.deps.nowFunc = func() int64 { return mockTime }
+		return gen
+	}
+
+	// Test case 1: Same state, same encoding
+	gens := make([]*Generator, 5)
+	for i := range gens {
+		gens[i] = newGen(&Config{ShardID: 7})
+	}
+	for round := 0; round < 3; round++ {
+		want := gens[0].Next()
+		b, err := gens[2].NextBytes()
+		if err != nil {
+			t.Fatalf("NextBytes failed: %v", err)
+		}
+		got := []string{
+			gens[1].NextString(),
+			string(b[:]),
+			string(gens[3].AppendNext(nil)),
+			string(gens[4].NextRaw()),
+		}
+		for i, s := range got {
+			if s != want {
+				t.Errorf("Round %d: variant %d produced %q, expected %q", round, i, s, want)
+			}
+		}
+		mockTime++
+	}
+
+	// Test case 2: AppendNext appends to existing content
+	buf := []byte("id=")
+	buf = gens[3].AppendNext(buf)
+	if len(buf) != 3+11 || string(buf[:3]) != "id=" {
+		t.Errorf("Unexpected AppendNext result %q", buf)
+	}
+	if _, err := Parse(string(buf[3:])); err != nil {
+		t.Errorf("Appended ID does not parse: %v", err)
+	}
+
+	// Test case 3: Prefixed IDs via NextRaw and AppendNext
+	v := newGen(&Config{ShardID: 7, VersionPrefix: 'v'})
+	if raw := v.NextRaw(); len(raw) != 12 || raw[0] != 'v' || cap(raw) != 12 {
+		t.Errorf("Unexpected NextRaw result %q (cap %d)", raw, cap(raw))
+	}
+
+	// Test case 4: NextBytes reports other lengths
+	for _, gen := range []*Generator{v, newGen(&Config{ShardID: 7, Checksum: true}), newGen(&Config{ShardID: 7, Encoding: EncodingCrockford})} {
+		if _, err := gen.NextBytes(); err != ErrNotElevenChars {
+			t.Errorf("Expected ErrNotElevenChars for %d-character IDs, got %v", gen.Len(), err)
+		}
+	}
+}
+
+// TestNextDecoded tests returning an ID's components alongside it
+func TestNextDecoded(t *testing.T) {
+	for _, cfg := range []*Config{
+		{ShardID: 12},
+		{ShardID: 3, VersionPrefix: 'v', TimestampUnit: 4 * time.Millisecond},
+		{ShardID: 1, Layout: Layout{TimestampBits: 39, ShardBits: 8, SequenceBits: 9, CounterBits: 4, ReservedBits: 4}, Salt: 6},
+	} {
+		gen, err := New(cfg)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		for i := 0; i < 100; i++ {
+			id, got := gen.NextDecoded()
+			want, err := gen.Parse(id)
+			if err != nil {
+				t.Fatalf("Parse(%q) failed: %v", id, err)
+			}
+			if got != want {
+				t.Fatalf("NextDecoded returned %+v for %q, Parse gives %+v", got, id, want)
+			}
+		}
+	}
+}
+
+// TestAppendNextAllocs tests that AppendNext and NextBytes do not allocate
+func TestAppendNextAllocs(t *testing.T) {
+	gen, err := New(&Config{ShardID: 1})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	buf := make([]byte, 0, 64)
+	if n := testing.AllocsPerRun(1000, func() { buf = gen.AppendNext(buf[:0]) }); n != 0 {
+		t.Errorf("Expected AppendNext not to allocate, got %v allocations per call", n)
+	}
+	var out [11]byte
+	if n := testing.AllocsPerRun(1000, func() { out, _ = gen.NextBytes() }); n != 0 {
+		t.Errorf("Expected NextBytes not to allocate, got %v allocations per call", n)
+	}
+	if _, err := Parse(string(out[:])); err != nil {
+		t.Errorf("NextBytes result does not parse: %v", err)
+	}
+}
+
+func BenchmarkAppendNext(b *testing.B) {
+	gen, _ := New(&Config{ShardID: 1})
+	buf := make([]byte, 0, 64)
+	b.ReportAllocs()
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		buf = gen.AppendNext(buf[:0])
+	}
+}
@@ -0,0 +1,167 @@
+package uniqid
+
+import (
+	"encoding/binary"
+	"errors"
+)
+
+// ErrInt64Overflow is returned by NextInt64 and BigIntID.Value for a
+// value with the top bit set, which a signed 64-bit integer would hold
+// as negative.
+var ErrInt64Overflow = errors.New("ID value overflows int64")
+
+// NextBinary generates a new ID as its 8-byte packed value, written in
+// the generator's Config.ByteOrder. It is the compact form for binary
+// protocols and BINARY(8) columns; the version prefix is not included.
+//
+// With the default big-endian order the bytes sort in generation order
+// (e.g. with bytes.Compare), like the string form. Little-endian bytes
+// do not.
+//
+// Example:
+//
+//	b := gen.NextBinary()
+//	_, err := db.Exec("INSERT INTO events (id) VALUES (?)", b[:])
+func (g *Generator) NextBinary() [8]byte {
+	var b [8]byte
+	val, _ := g.next(true)
+	g.order.PutUint64(b[:], val)
+	return b
+}
+
+// NextUint64 generates a new ID as its packed 64-bit value, skipping
+// the string encoding, e.g. for BIGINT columns or systems that consume
+// Snowflake-style integers. Values increase in generation order; the
+// version prefix is not included.
+//
+// Example:
+//
+//	v := gen.NextUint64()
+//	_, err := db.Exec("INSERT INTO events (id) VALUES ($1)", v)
+func (g *Generator) NextUint64() uint64 {
+	val, _ := g.next(true)
+	return val
+}
+
+// NextInt64 is like NextUint64 but returns a signed value, for
+// databases without unsigned integers such as PostgreSQL. Layouts of
+// at most 63 bits (see Layout.SQLType) never fill the sign bit, so it
+// never fails for them. The 64-bit DefaultLayout fills it from
+// September 2028: NextInt64 then returns ErrInt64Overflow rather than
+// a negative value, which would no longer sort in generation order,
+// and the slot is used up. BigIntID.Value behaves the same way.
+//
+// Example:
+//
+//	gen, _ := uniqid.New(&uniqid.Config{
+//	    ShardID: 1,
+//	    Layout:  uniqid.Layout{TimestampBits: 41, ShardBits: 10, SequenceBits: 12},
+//	})
+//	v, err := gen.NextInt64()
+func (g *Generator) NextInt64() (int64, error) {
+	return toInt64(g.NextUint64())
+}
+
+// ParseBinary decodes an 8-byte ID produced by NextBinary, reading it
+// in the generator's Config.ByteOrder. It returns ErrInvalidID if b is
+// not 8 bytes long or holds a value that does not fit the layout, and
+// ErrSaltMismatch if the salt differs from g's.
+func (g *Generator) ParseBinary(b []byte) (Parts, error) {
+	if len(b) != 8 {
+		return Parts{}, ErrInvalidID
+	}
+	val := g.order.Uint64(b)
+	if bits := g.layout.Bits(); bits < 64 && val>>uint(bits) != 0 {
+		return Parts{}, ErrInvalidID
+	}
+	return g.partsOf(val)
+}
+
+// NextUUIDBytes generates a new ID as 16 bytes for storage in a native
+// UUID column: the packed value in the high 8 bytes, big-endian, and
+// zero padding in the low 8. The bytes sort in generation order, so
+// the column's index stays append-friendly.
+//
+// The result is not a standard UUID: it sets no version or variant
+// bits, so tools that validate UUIDs may reject it. Use it only where
+// the column type, not UUID semantics, is wanted.
+//
+// Example:
+//
+//	u := gen.NextUUIDBytes()
+//	_, err := db.Exec("INSERT INTO events (id) VALUES ($1)", u[:])
+func (g *Generator) NextUUIDBytes() [16]byte {
+	var u [16]byte
+	val, _ := g.next(true)
+	binary.BigEndian.PutUint64(u[:8], val)
+	return u
+}
+
+// FromUUIDBytes recovers the ID stored by NextUUIDBytes. It assumes
+// the default format (DefaultLayout, no version prefix) and returns
+// ErrInvalidID if the low 8 bytes are not zero, i.e. if u did not come
+// from NextUUIDBytes.
+func FromUUIDBytes(u [16]byte) (string, error) {
+	if binary.BigEndian.Uint64(u[8:]) != 0 {
+		return "", ErrInvalidID
+	}
+	var out [11]byte
+	encodeTo(out[:], binary.BigEndian.Uint64(u[:8]))
+	return string(out[:]), nil
+}
+
+// EncodeUint64 returns the 11-character default-format ID for the
+// packed value v, as Next returns it: the inverse of DecodeString. It
+// converts values read from a BIGINT column (see NextUint64) to their
+// presentation form without a generator. Use Generator.EncodeUint64
+// for other alphabets and formats.
+//
+// Example:
+//
+//	var v uint64
+//	err := db.QueryRow("SELECT id FROM events LIMIT 1").Scan(&v)
+//	fmt.Println(uniqid.EncodeUint64(v))
+func EncodeUint64(v uint64) string {
+	var out [11]byte
+	encodeTo(out[:], v)
+	return string(out[:])
+}
+
+// DecodeString returns the packed value of a default-format ID, e.g. to
+// store it in a BIGINT column: the inverse of EncodeUint64. It returns
+// ErrInvalidID if s is not a valid ID. Use Generator.DecodeString for
+// other alphabets and formats.
+func DecodeString(s string) (uint64, error) {
+	return decode(s, DefaultLayout)
+}
+
+// EncodeUint64 returns the ID for the packed value v in g's format
+// (alphabet or encoding, prefixes, checksum, signature and
+// obfuscation), as Next would return it for that value. It returns
+// ErrInvalidID if v does not fit g's layout.
+func (g *Generator) EncodeUint64(v uint64) (string, error) {
+	if bits := g.layout.Bits(); bits < 64 && v>>uint(bits) != 0 {
+		return "", ErrInvalidID
+	}
+	return g.format(v), nil
+}
+
+// DecodeString returns the packed value of an ID in g's format, the
+// inverse of Generator.EncodeUint64. It checks the ID as Parse does,
+// except for the salt.
+func (g *Generator) DecodeString(s string) (uint64, error) {
+	return g.decodeString(s)
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// toInt64 converts a packed value for a signed 64-bit column, failing
+// with ErrInt64Overflow instead of going negative.
+func toInt64(val uint64) (int64, error) {
+	if val>>63 != 0 {
+		return 0, ErrInt64Overflow
+	}
+	return int64(val), nil
+}
@@ -0,0 +1,212 @@
+package uniqid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestNextBinary tests generating and parsing binary IDs in both byte orders
+func TestNextBinary(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	clock := func() int64 { return mockTime }
+
+	// Test case 1: Default is big-endian and round-trips
+	gen, _ := New(&Config{ShardID: 9})
+	gen.deps.nowFunc = clock
+	if gen.Config().ByteOrder != binary.BigEndian {
+		t.Errorf("Expected default ByteOrder to be big-endian, got %v", gen.Config().ByteOrder)
+	}
+	b := gen.NextBinary()
+	parts, err := gen.ParseBinary(b[:])
+	if err != nil {
+		t.Fatalf("ParseBinary failed: %v", err)
+	}
+	if parts.Shard != 9 || parts.Time.UnixMilli() != mockTime {
+		t.Errorf("Unexpected parts: %+v", parts)
+	}
+
+	// Test case 2: Big-endian bytes sort in generation order
+	prev := b
+	for i := 0; i < 300; i++ {
+		if i%100 == 0 {
+			mockTime++
+		}
+		b := gen.NextBinary()
+		if bytes.Compare(prev[:], b[:]) >= 0 {
+			t.Fatalf("Expected big-endian bytes to sort in order at %d", i)
+		}
+		prev = b
+	}
+
+	// Test case 3: Little-endian round-trips and matches the integer
+	le, _ := New(&Config{ShardID: 9, ByteOrder: binary.LittleEndian})
+	le.deps.nowFunc = clock
+	b = le.NextBinary()
+	parts, err = le.ParseBinary(b[:])
+	if err != nil {
+		t.Fatalf("ParseBinary failed: %v", err)
+	}
+	if parts.Shard != 9 || parts.Time.UnixMilli() != mockTime {
+		t.Errorf("Unexpected parts: %+v", parts)
+	}
+	val := binary.LittleEndian.Uint64(b[:])
+	if got := le.layout.pack(le.lastMs, le.shard, le.seq, 0); got != val {
+		t.Errorf("Expected little-endian value %d, got %d", got, val)
+	}
+
+	// Test case 4: Invalid input
+	if _, err := gen.ParseBinary(b[:7]); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Expected ErrInvalidID for short input, got %v", err)
+	}
+	ns, _ := New(&Config{NoShard: true})
+	full := [8]byte{0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF, 0xFF}
+	if _, err := ns.ParseBinary(full[:]); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Expected ErrInvalidID for out-of-range value, got %v", err)
+	}
+}
+
+// TestNextUint64 tests generating IDs as integers
+func TestNextUint64(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	gen, _ := New(&Config{ShardID: 9})
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	// Test case 1: The value is the packed form of the string ID
+	v := gen.NextUint64()
+	id := gen.Next()
+	want, _ := decode(id, DefaultLayout)
+	if want != v+1 {
+		t.Errorf("Expected value %d before %q (%d)", v, id, want)
+	}
+	if p := DefaultLayout.parts(v, defaultEpochMs, 1); p.Shard != 9 || p.Time.UnixMilli() != mockTime {
+		t.Errorf("Unexpected parts: %+v", p)
+	}
+
+	// Test case 2: Values increase
+	prev := gen.NextUint64()
+	for i := 0; i < 1000; i++ {
+		if i%100 == 0 {
+			mockTime++
+		}
+		next := gen.NextUint64()
+		if next <= prev {
+			t.Fatalf("Value %d does not increase over %d", next, prev)
+		}
+		prev = next
+	}
+
+	// Test case 3: NextInt64 with a 63-bit layout is never negative
+	signed, _ := New(&Config{ShardID: 1023, Layout: Layout{TimestampBits: 41, ShardBits: 10, SequenceBits: 12}})
+	signed.deps.nowFunc = func() int64 { return defaultEpochMs + 1<<41 - 1 }
+	if n, err := signed.NextInt64(); err != nil || n <= 0 {
+		t.Errorf("Expected positive value, got %d, %v", n, err)
+	}
+
+	// Test case 4: 64-bit layouts work until the sign bit fills, then
+	// fail instead of going negative
+	if n, err := gen.NextInt64(); err != nil || n <= 0 {
+		t.Errorf("Expected positive value, got %d, %v", n, err)
+	}
+	mockTime = defaultEpochMs + 1<<38
+	if n, err := gen.NextInt64(); err != ErrInt64Overflow {
+		t.Errorf("Expected ErrInt64Overflow, got %d, %v", n, err)
+	}
+}
+
+// TestUUIDBytes tests storing IDs in UUID-sized byte arrays
+func TestUUIDBytes(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	gen, _ := New(&Config{ShardID: 3})
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	// Test case 1: Round trip recovers the ID
+	u := gen.NextUUIDBytes()
+	id, err := FromUUIDBytes(u)
+	if err != nil {
+		t.Fatalf("FromUUIDBytes failed: %v", err)
+	}
+	parts, err := Parse(id)
+	if err != nil {
+		t.Fatalf("Parse(%q) failed: %v", id, err)
+	}
+	if parts.Shard != 3 || parts.Seq != 0 || parts.Time.UnixMilli() != mockTime {
+		t.Errorf("Unexpected parts: %+v", parts)
+	}
+	if p, _ := Parse(gen.Next()); p.Seq != 1 {
+		t.Errorf("Expected Next to continue the sequence, got %+v", p)
+	}
+
+	// Test case 2: Bytes sort in generation order
+	prev := gen.NextUUIDBytes()
+	for i := 0; i < 100; i++ {
+		if i%10 == 0 {
+			mockTime++
+		}
+		u := gen.NextUUIDBytes()
+		if bytes.Compare(prev[:], u[:]) >= 0 {
+			t.Fatalf("Expected UUID bytes to sort in order at %d", i)
+		}
+		prev = u
+	}
+
+	// Test case 3: Non-zero padding is rejected
+	u[15] = 1
+	if _, err := FromUUIDBytes(u); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Expected ErrInvalidID, got %v", err)
+	}
+}
+
+// TestEncodeUint64 tests converting between packed values and IDs
+func TestEncodeUint64(t *testing.T) {
+	// Test case 1: Package functions round-trip with Next and NextUint64
+	gen, _ := New(&Config{ShardID: 3})
+	id := gen.Next()
+	v, err := DecodeString(id)
+	if err != nil || EncodeUint64(v) != id {
+		t.Errorf("Round trip of %q gave %d, %v", id, v, err)
+	}
+	v = gen.NextUint64()
+	if got, err := DecodeString(EncodeUint64(v)); err != nil || got != v {
+		t.Errorf("Round trip of %d gave %d, %v", v, got, err)
+	}
+	if EncodeUint64(0) != "AAAAAAAAAAA" || EncodeUint64(1<<64-1) != "P__________" {
+		t.Errorf("Unexpected extremes %q, %q", EncodeUint64(0), EncodeUint64(1<<64-1))
+	}
+	if _, err := DecodeString("Q__________"); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Expected ErrInvalidID for an overflowing ID, got %v", err)
+	}
+
+	// Test case 2: Generator methods follow its format
+	gen, _ = New(&Config{
+		ShardID:        3,
+		Alphabet:       SortableAlphabet,
+		Prefix:         "ev_",
+		Checksum:       true,
+		ObfuscationKey: []byte("0123456789abcdef"),
+	})
+	v = gen.NextUint64()
+	id, err = gen.EncodeUint64(v)
+	if err != nil {
+		t.Fatalf("EncodeUint64 failed: %v", err)
+	}
+	if got, err := gen.DecodeString(id); err != nil || got != v {
+		t.Errorf("DecodeString(%q) = %d, %v, want %d", id, got, err, v)
+	}
+	if p, err := gen.Parse(id); err != nil || p.Shard != 3 {
+		t.Errorf("Parse(%q) = %+v, %v", id, p, err)
+	}
+	bad := []byte(id)
+	bad[len(bad)-1] ^= 1
+	if _, err := gen.DecodeString(string(bad)); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Expected ErrInvalidID for a bad check character, got %v", err)
+	}
+
+	// Test case 3: Values wider than the layout are rejected
+	narrow, _ := New(&Config{ShardID: 1, Layout: Layout{TimestampBits: 41, ShardBits: 10, SequenceBits: 12}})
+	if _, err := narrow.EncodeUint64(1 << 63); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Expected ErrInvalidID, got %v", err)
+	}
+}
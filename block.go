@@ -0,0 +1,163 @@
+package uniqid
+
+import (
+	"errors"
+	"fmt"
+	"iter"
+	"time"
+)
+
+// Block is a contiguous range of sequence slots within one timestamp
+// tick, reserved with ReserveRange. Its IDs are computed on demand
+// from the range, so a worker can mint them without touching the
+// generator again. A Block is immutable and safe for concurrent use.
+type Block struct {
+	g       *Generator
+	ms      int64
+	shard   uint16
+	seq     uint32
+	counter uint64
+	n       int
+}
+
+// ReserveRange reserves n consecutive sequence slots in a single
+// timestamp tick and returns them as a Block. Unlike ReserveBlock it
+// does not encode the IDs up front, and holds the generator's lock
+// only to claim the range, so a batch job can assign millions of IDs
+// by reserving one tick's worth at a time. If the current tick has
+// fewer than n slots left, ReserveRange waits for the next one.
+//
+// It returns an error if n is not positive or exceeds the layout's
+// capacity per tick (Layout.MaxSequence()+1, half that with
+// Config.RandomizeSequence), or if the generator is Config.LockFree.
+// With Config.BannedSubstrings, banned IDs in the range have their
+// reserved bits re-rolled as by Next; if one cannot be, the range is
+// given up and ReserveRange returns ErrTooManyBanned.
+//
+// Example:
+//
+//	block, err := gen.ReserveRange(10000)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	for i, row := range rows[:block.Len()] {
+//	    row.ID = block.ID(i)
+//	}
+func (g *Generator) ReserveRange(n int) (Block, error) {
+	if n <= 0 {
+		return Block{}, errors.New("range size must be positive")
+	}
+	slots := g.layout.MaxSequence() + 1
+	if g.randSeq {
+		// A new tick may start anywhere in the lower half.
+		slots -= slots / 2
+	}
+	if n > slots {
+		return Block{}, fmt.Errorf("range size %d exceeds the %d slots per tick", n, slots)
+	}
+	if g.lockFree {
+		return Block{}, errors.New("reserveRange is not supported with lockFree")
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	var nowMs int64
+	for {
+		nowMs = g.tick()
+		if nowMs > g.lastMs || g.room() >= n {
+			break
+		}
+		g.stats.Rollovers++
+		lastMs, nowFunc := g.lastMs, g.deps.nowFunc
+		g.mu.Unlock()
+		g.wait(g.baseEpoch, lastMs*g.unit+g.unit-1, nowFunc, g.spinSleep)
+		g.mu.Lock()
+	}
+	// Claim the first slot as Next would, then the rest of the range.
+	// Skipping banned slots would move the range past the room checked
+	// above, so they are only re-rolled.
+	g.claimLocked(true, nowMs)
+	b := Block{g: g, ms: g.lastMs, shard: g.shard, seq: g.seq, counter: g.counter - 1, n: n}
+	g.seq += uint32(n - 1)
+	g.counter += uint64(n - 1)
+	g.issued += uint64(n - 1)
+	g.stats.Generated += uint64(n - 1)
+	for i := 0; g.banned != nil && i < n; i++ {
+		if _, ok := g.allowed(b.packed(i)); !ok {
+			g.counter = b.counter
+			g.issued -= uint64(n)
+			g.stats.Generated -= uint64(n)
+			g.stats.Skipped += uint64(n)
+			return Block{}, ErrTooManyBanned
+		}
+	}
+	for i := 0; g.hist != nil && i < n; i++ {
+		g.hist.record(g.lastMs)
+	}
+	return b, nil
+}
+
+// Len returns the number of IDs in b.
+func (b Block) Len() int {
+	return b.n
+}
+
+// Time returns the timestamp shared by every ID in b.
+func (b Block) Time() time.Time {
+	return time.UnixMilli(b.g.baseEpoch + b.ms*b.g.unit)
+}
+
+// Uint64 returns the packed value of the i-th ID in b. It panics if i
+// is out of range.
+func (b Block) Uint64(i int) uint64 {
+	if i < 0 || i >= b.n {
+		panic(fmt.Sprintf("uniqid: block index %d out of range [0, %d)", i, b.n))
+	}
+	// ReserveRange checked that every ID in b is allowed.
+	val, _ := b.g.allowed(b.packed(i))
+	return val
+}
+
+// ID returns the i-th ID in b, encoded as the generator's Next would.
+// It panics if i is out of range.
+func (b Block) ID(i int) string {
+	return b.g.format(b.Uint64(i))
+}
+
+// AppendID appends the i-th ID in b to dst and returns the extended
+// slice, without allocating if dst has room. It panics if i is out of
+// range.
+func (b Block) AppendID(dst []byte, i int) []byte {
+	return b.g.appendID(dst, b.Uint64(i))
+}
+
+// All returns the IDs in b in order, for use with range.
+func (b Block) All() iter.Seq2[int, string] {
+	return func(yield func(int, string) bool) {
+		for i := range b.n {
+			if !yield(i, b.ID(i)) {
+				return
+			}
+		}
+	}
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// packed returns the packed value of the i-th ID in b before
+// Config.BannedSubstrings is applied.
+func (b Block) packed(i int) uint64 {
+	return b.g.layout.pack(b.ms, b.shard, b.seq+uint32(i), b.counter+uint64(i)) | uint64(b.g.salt)
+}
+
+// room returns how many slots are left in the current tick, counting
+// a rolled-back slot waiting to be reissued. It must be called with
+// g.mu held.
+func (g *Generator) room() int {
+	free := g.layout.MaxSequence() - int(g.seq)
+	if g.reuse {
+		free++
+	}
+	return free
+}
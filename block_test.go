@@ -0,0 +1,83 @@
+package uniqid
+
+import (
+	"testing"
+	"time"
+)
+
+// TestReserveRange tests reserving ranges of sequence slots as a Block
+func TestReserveRange(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	gen, err := New(&Config{ShardID: 5, TrackHistogram: true})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	// Test case 1: A block's IDs are consecutive and decode to its tick
+	before := gen.Next()
+	block, err := gen.ReserveRange(1000)
+	if err != nil {
+		t.Fatalf("ReserveRange failed: %v", err)
+	}
+	if block.Len() != 1000 || block.Time().UnixMilli() != mockTime {
+		t.Errorf("Unexpected block: len %d, time %v", block.Len(), block.Time())
+	}
+	prev, _ := decode(before, DefaultLayout)
+	for i, id := range block.All() {
+		p, err := Parse(id)
+		if err != nil || p.Shard != 5 || int(p.Seq) != i+1 || p.Time.UnixMilli() != mockTime {
+			t.Fatalf("Unexpected ID %d: %+v, %v", i, p, err)
+		}
+		if val := block.Uint64(i); val != prev+1<<15 && val != prev+1 {
+			t.Fatalf("ID %d does not follow the previous one", i)
+		}
+		prev = block.Uint64(i)
+		if got := string(block.AppendID(nil, i)); got != id {
+			t.Errorf("AppendID(%d) = %q, want %q", i, got, id)
+		}
+	}
+
+	// Test case 2: The generator continues after the block
+	if p, _ := Parse(gen.Next()); p.Seq != 1001 {
+		t.Errorf("Expected Next to continue at sequence 1001, got %d", p.Seq)
+	}
+	if s := gen.Stats(); s.Generated != 1002 {
+		t.Errorf("Expected 1002 generated, got %d", s.Generated)
+	}
+
+	// Test case 3: A range that does not fit waits for the next tick
+	reads := 0
+	gen.deps.nowFunc = func() int64 {
+		reads++
+		return mockTime + int64(reads/50)
+	}
+	block, err = gen.ReserveRange(1 << 15)
+	if err != nil {
+		t.Fatalf("ReserveRange failed: %v", err)
+	}
+	if p, _ := Parse(block.ID(0)); p.Seq != 0 || block.Len() != 1<<15 {
+		t.Errorf("Expected a full tick, got %+v", p)
+	}
+
+	// Test case 4: Out of range indexes panic
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Error("Expected a panic for an out of range index")
+			}
+		}()
+		block.ID(1 << 15)
+	}()
+
+	// Test case 5: Invalid sizes and LockFree generators are rejected
+	for _, n := range []int{0, -1, 1<<15 + 1} {
+		if _, err := gen.ReserveRange(n); err == nil {
+			t.Errorf("ReserveRange(%d): expected error, got nil", n)
+		}
+	}
+	lf, _ := New(&Config{ShardID: 1, LockFree: true})
+	if _, err := lf.ReserveRange(4); err == nil {
+		t.Error("Expected ReserveRange to fail with LockFree, got nil")
+	}
+}
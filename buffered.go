@@ -0,0 +1,89 @@
+package uniqid
+
+import (
+	"errors"
+	"sync"
+)
+
+// BufferedGenerator hands out IDs generated ahead of time by a
+// background goroutine, so Next is a buffer read that never waits for
+// the clock or for the generator's lock. It suits request paths with
+// tight tail-latency budgets. It is safe for concurrent use by
+// multiple goroutines.
+//
+// A buffered ID's timestamp is when it was generated, not when Next
+// returned it, so it may lag by however long the buffer sat full. IDs
+// from Next are still unique, but only roughly time-ordered relative
+// to IDs taken directly from the underlying generator.
+type BufferedGenerator struct {
+	gen      *Generator
+	ids      chan string
+	stop     chan struct{}
+	stopped  chan struct{}
+	stopOnce sync.Once
+}
+
+// NewBuffered starts prefetching up to size IDs from gen. Call Close
+// to stop the background goroutine.
+//
+// Example:
+//
+//	bg, err := uniqid.NewBuffered(gen, 4096)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer bg.Close()
+//	id := bg.Next()
+func NewBuffered(gen *Generator, size int) (*BufferedGenerator, error) {
+	if gen == nil {
+		return nil, errors.New("generator is required")
+	}
+	if size < 1 {
+		return nil, errors.New("buffer size must be positive")
+	}
+	b := &BufferedGenerator{
+		gen:     gen,
+		ids:     make(chan string, size),
+		stop:    make(chan struct{}),
+		stopped: make(chan struct{}),
+	}
+	go b.fill()
+	return b, nil
+}
+
+// Next returns a prefetched ID, or one generated directly if the
+// buffer has run dry.
+func (b *BufferedGenerator) Next() string {
+	select {
+	case id := <-b.ids:
+		return id
+	default:
+		return b.gen.Next()
+	}
+}
+
+// Close stops prefetching and waits for the background goroutine to
+// exit. IDs already buffered are still returned by Next, which falls
+// back to the underlying generator once they run out. Calling Close
+// more than once is a no-op.
+func (b *BufferedGenerator) Close() {
+	b.stopOnce.Do(func() { close(b.stop) })
+	<-b.stopped
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// fill keeps b.ids topped up until Close is called.
+func (b *BufferedGenerator) fill() {
+	defer close(b.stopped)
+	for {
+		id := b.gen.Next()
+		select {
+		case b.ids <- id:
+		case <-b.stop:
+			return
+		}
+	}
+}
@@ -0,0 +1,75 @@
+package uniqid
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestBufferedGenerator tests prefetching IDs into a buffer
+func TestBufferedGenerator(t *testing.T) {
+	gen, err := New(&Config{ShardID: 1})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	bg, err := NewBuffered(gen, 64)
+	if err != nil {
+		t.Fatalf("NewBuffered failed: %v", err)
+	}
+
+	// Test case 1: The buffer fills in the background
+	deadline := time.Now().Add(time.Second)
+	for len(bg.ids) < cap(bg.ids) && time.Now().Before(deadline) {
+		time.Sleep(time.Millisecond)
+	}
+	if len(bg.ids) != cap(bg.ids) {
+		t.Errorf("Expected a full buffer, got %d of %d", len(bg.ids), cap(bg.ids))
+	}
+
+	// Test case 2: Concurrent callers and direct generation get unique IDs
+	var (
+		mu   sync.Mutex
+		seen = make(map[string]struct{})
+		wg   sync.WaitGroup
+	)
+	for w := 0; w < 4; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 1000; i++ {
+				id := bg.Next()
+				if i%10 == 0 {
+					id = gen.Next()
+				}
+				mu.Lock()
+				if _, dup := seen[id]; dup {
+					t.Errorf("Duplicate ID %q", id)
+				}
+				seen[id] = struct{}{}
+				mu.Unlock()
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Test case 3: After Close, buffered IDs drain and Next falls back
+	bg.Close()
+	bg.Close()
+	for i := 0; i < 2*cap(bg.ids); i++ {
+		if id := bg.Next(); id == "" {
+			t.Fatal("Expected an ID after Close")
+		} else if _, dup := seen[id]; dup {
+			t.Fatalf("Duplicate ID %q after Close", id)
+		} else {
+			seen[id] = struct{}{}
+		}
+	}
+
+	// Test case 4: Invalid arguments are rejected
+	if _, err := NewBuffered(nil, 8); err == nil {
+		t.Error("Expected error for a nil generator, got nil")
+	}
+	if _, err := NewBuffered(gen, 0); err == nil {
+		t.Error("Expected error for a zero size, got nil")
+	}
+}
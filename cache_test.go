@@ -0,0 +1,129 @@
+package uniqid
+
+import (
+	"bytes"
+	"testing"
+)
+
+// TestCachingDecoder tests that cached results match fresh decodes
+func TestCachingDecoder(t *testing.T) {
+	gen, _ := New(&Config{ShardID: 9})
+	ids := make([]string, 10)
+	for i := range ids {
+		ids[i] = gen.Next()
+	}
+
+	dec := NewCachingDecoder(4, gen)
+	for round := 0; round < 2; round++ {
+		for _, id := range ids {
+			got, err := dec.Parse(id)
+			if err != nil {
+				t.Fatalf("CachingDecoder.Parse(%q) failed: %v", id, err)
+			}
+			want, _ := gen.Parse(id)
+			if got != want {
+				t.Errorf("Cached parts %+v differ from fresh decode %+v", got, want)
+			}
+		}
+	}
+
+	// The cache stays within capacity
+	if dec.Len() != 4 {
+		t.Errorf("Expected 4 cached entries, got %d", dec.Len())
+	}
+
+	// Invalid IDs return an error and are not cached
+	if _, err := dec.Parse("bad"); err != ErrInvalidID {
+		t.Errorf("Expected ErrInvalidID, got %v", err)
+	}
+	if dec.Len() != 4 {
+		t.Errorf("Invalid ID should not be cached, got %d entries", dec.Len())
+	}
+
+	// Default capacity and package-level Parse
+	dec = NewCachingDecoder(0, nil)
+	if dec.capacity != defaultCacheCapacity {
+		t.Errorf("Expected default capacity %d, got %d", defaultCacheCapacity, dec.capacity)
+	}
+	if _, err := dec.Parse(ids[0]); err != nil {
+		t.Errorf("CachingDecoder.Parse failed: %v", err)
+	}
+
+	// Signed and obfuscated IDs are verified once, then served cached
+	keyed, _ := New(&Config{ShardID: 9, SigningKey: bytes.Repeat([]byte("s"), 32), ObfuscationKey: bytes.Repeat([]byte("o"), 16)})
+	id := keyed.Next()
+	dec = NewCachingDecoder(4, keyed)
+	for round := 0; round < 2; round++ {
+		if got, err := dec.Parse(id); err != nil || got.Shard != 9 {
+			t.Errorf("CachingDecoder.Parse(%q) = %+v, %v", id, got, err)
+		}
+	}
+	forged := id[:len(id)-1] + "A"
+	if forged == id {
+		forged = id[:len(id)-1] + "B"
+	}
+	if _, err := dec.Parse(forged); err == nil || dec.Len() != 1 {
+		t.Errorf("Expected forged ID %q to fail uncached, got %v with %d entries", forged, err, dec.Len())
+	}
+}
+
+func BenchmarkParse(b *testing.B) {
+	gen, _ := New(&Config{ShardID: 1})
+	ids := make([]string, 64)
+	for i := range ids {
+		ids[i] = gen.Next()
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = Parse(ids[i&63])
+	}
+}
+
+func BenchmarkCachingDecoder(b *testing.B) {
+	gen, _ := New(&Config{ShardID: 1})
+	ids := make([]string, 64)
+	for i := range ids {
+		ids[i] = gen.Next()
+	}
+	dec := NewCachingDecoder(64, nil)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		_, _ = dec.Parse(ids[i&63])
+	}
+}
+
+func BenchmarkParseSigned(b *testing.B) {
+	benchmarkKeyedParse(b, &Config{ShardID: 1, SigningKey: bytes.Repeat([]byte("s"), 32)}, false)
+}
+
+func BenchmarkCachingDecoderSigned(b *testing.B) {
+	benchmarkKeyedParse(b, &Config{ShardID: 1, SigningKey: bytes.Repeat([]byte("s"), 32)}, true)
+}
+
+func BenchmarkParseObfuscated(b *testing.B) {
+	benchmarkKeyedParse(b, &Config{ShardID: 1, ObfuscationKey: bytes.Repeat([]byte("o"), 32)}, false)
+}
+
+func BenchmarkCachingDecoderObfuscated(b *testing.B) {
+	benchmarkKeyedParse(b, &Config{ShardID: 1, ObfuscationKey: bytes.Repeat([]byte("o"), 32)}, true)
+}
+
+// benchmarkKeyedParse parses 64 hot IDs of a keyed generator, through
+// a CachingDecoder if cached is set.
+func benchmarkKeyedParse(b *testing.B, cfg *Config, cached bool) {
+	gen, _ := New(cfg)
+	ids := make([]string, 64)
+	for i := range ids {
+		ids[i] = gen.Next()
+	}
+	parse := gen.Parse
+	if cached {
+		parse = NewCachingDecoder(64, gen).Parse
+	}
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := parse(ids[i&63]); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
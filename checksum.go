@@ -0,0 +1,66 @@
+package uniqid
+
+import "fmt"
+
+// ErrChecksumMismatch is returned by Generator.Parse and ParseWith for
+// an ID whose check character does not match, e.g. one mistyped by a
+// person. It wraps ErrInvalidID.
+var ErrChecksumMismatch = fmt.Errorf("%w: checksum mismatch", ErrInvalidID)
+
+// Verify reports whether id is a well-formed ID in g's format,
+// including its check character with Config.Checksum. It is a cheap
+// sanity check for IDs typed by people before looking them up.
+//
+// Example:
+//
+//	if !gen.Verify(input) {
+//	    return errors.New("invoice number mistyped")
+//	}
+func (g *Generator) Verify(id string) bool {
+	_, err := g.Parse(id)
+	return err == nil
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// checkChar returns the Luhn mod N check character for the encoded
+// characters in s, N being the alphabet size. It detects any single
+// mistyped character and most swaps of adjacent characters.
+func (c *codec) checkChar(s []byte) byte {
+	n := len(c.alphabet)
+	sum, double := 0, true
+	for i := len(s) - 1; i >= 0; i-- {
+		v := int(c.table[s[i]])
+		if double {
+			v *= 2
+			v = v/n + v%n
+		}
+		sum += v
+		double = !double
+	}
+	return c.alphabet[(n-sum%n)%n]
+}
+
+// stripCheck verifies the check character ending id, which holds the
+// encoded characters of layout l, possibly with separators from
+// NextDelimited, and returns id without separators or check character.
+func (c *codec) stripCheck(id string, l Layout) (string, error) {
+	n := c.chars(l) + 1
+	if len(id) != n {
+		var ok bool
+		if id, ok = c.undelimit(id, n); !ok {
+			return "", ErrInvalidID
+		}
+	}
+	for i := 0; i < n; i++ {
+		if c.table[id[i]] == 0xFF {
+			return "", ErrInvalidID
+		}
+	}
+	if c.table[c.checkChar([]byte(id[:n-1]))] != c.table[id[n-1]] {
+		return "", ErrChecksumMismatch
+	}
+	return id[:n-1], nil
+}
@@ -0,0 +1,82 @@
+package uniqid
+
+import (
+	"errors"
+	"testing"
+)
+
+// TestChecksum tests appending and verifying check characters
+func TestChecksum(t *testing.T) {
+	for _, cfg := range []*Config{
+		{ShardID: 3, Checksum: true},
+		{ShardID: 3, Checksum: true, Encoding: EncodingCrockford, Prefix: "inv_"},
+	} {
+		gen, err := New(cfg)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		alphabet := gen.codec.alphabet
+
+		// Test case 1: IDs get one extra character and verify
+		id := gen.Next()
+		if len(id) != gen.Len() || len(id) != len(cfg.Prefix)+gen.codec.chars(gen.layout)+1 {
+			t.Fatalf("Unexpected length of %q, Len %d", id, gen.Len())
+		}
+		if !gen.Verify(id) {
+			t.Fatalf("Verify(%q) = false", id)
+		}
+		if p, err := ParseWith(id, cfg); err != nil || p.Shard != 3 {
+			t.Errorf("ParseWith(%q) = %+v, %v", id, p, err)
+		}
+
+		// Test case 2: Every single mistyped character is caught
+		body := len(cfg.Prefix)
+		for i := body; i < len(id); i++ {
+			for j := 0; j < len(alphabet); j++ {
+				b := []byte(id)
+				if gen.codec.table[b[i]] == byte(j) {
+					continue
+				}
+				b[i] = alphabet[j]
+				if _, err := gen.Parse(string(b)); err == nil {
+					t.Fatalf("Typo %q of %q was not caught", b, id)
+				}
+			}
+		}
+
+		// Test case 3: Swapping adjacent differing characters is caught,
+		// except for the pairs Luhn cannot tell apart
+		missed := 0
+		for i := body; i+1 < len(id); i++ {
+			b := []byte(id)
+			if b[i] == b[i+1] {
+				continue
+			}
+			b[i], b[i+1] = b[i+1], b[i]
+			_, err := gen.Parse(string(b))
+			if errors.Is(err, ErrChecksumMismatch) || err == ErrInvalidID {
+				continue
+			}
+			missed++
+		}
+		if missed > 1 {
+			t.Errorf("%d adjacent swaps of %q went unnoticed", missed, id)
+		}
+
+		// Test case 4: Delimited IDs keep the check character
+		d, err := gen.NextDelimited(4, '.')
+		if err != nil {
+			t.Fatalf("NextDelimited failed: %v", err)
+		}
+		if !gen.Verify(d) {
+			t.Errorf("Verify(%q) = false", d)
+		}
+	}
+
+	// Test case 5: Generators without Checksum reject checked IDs
+	checked, _ := New(&Config{ShardID: 3, Checksum: true})
+	plain, _ := New(&Config{ShardID: 3})
+	if plain.Verify(checked.Next()) || checked.Verify(plain.Next()) {
+		t.Error("Expected checked and plain IDs not to verify across formats")
+	}
+}
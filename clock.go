@@ -0,0 +1,163 @@
+package uniqid
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Clock is a source of the current time in Unix milliseconds, for
+// injecting frozen or simulated time through Config.Clock, e.g. in
+// tests of code that generates IDs. A Clock must be safe for
+// concurrent use. The generator stays monotonic whatever the clock
+// does: a clock that stands still exhausts the sequence and blocks
+// Next until it moves, and one that goes backwards is treated like a
+// system clock adjustment.
+//
+// Example:
+//
+//	clock := uniqid.NewManualClock(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC))
+//	gen, _ := uniqid.New(&uniqid.Config{ShardID: 1, Clock: clock})
+//	id := gen.Next() // timestamped 2030-01-01
+//	clock.Advance(time.Second)
+type Clock interface {
+	NowMs() int64
+}
+
+// ClockFunc adapts a function to the Clock interface.
+type ClockFunc func() int64
+
+// NowMs returns f().
+func (f ClockFunc) NowMs() int64 { return f() }
+
+// SystemClock reads the system wall clock, as generators do with
+// Config.WallClock.
+var SystemClock Clock = ClockFunc(func() int64 { return time.Now().UnixMilli() })
+
+// ManualClock is a Clock that only moves when told to, for
+// deterministic tests. It is safe for concurrent use.
+type ManualClock struct {
+	ms atomic.Int64
+}
+
+// NewManualClock returns a ManualClock set to t.
+func NewManualClock(t time.Time) *ManualClock {
+	c := &ManualClock{}
+	c.Set(t)
+	return c
+}
+
+// NowMs implements Clock.
+func (c *ManualClock) NowMs() int64 { return c.ms.Load() }
+
+// Set moves the clock to t, forwards or backwards.
+func (c *ManualClock) Set(t time.Time) { c.ms.Store(t.UnixMilli()) }
+
+// Advance moves the clock forward by d, truncated to the millisecond.
+func (c *ManualClock) Advance(d time.Duration) { c.ms.Add(d.Milliseconds()) }
+
+// monotonicClock returns a millisecond clock that reads the wall
+// clock once and then advances with the monotonic clock, so it never
+// goes backwards. Generators use it unless configured otherwise.
+// Not exported.
+func monotonicClock() func() int64 {
+	start := time.Now()
+	startNs := start.UnixNano()
+	return func() int64 {
+		return (startNs + int64(time.Since(start))) / int64(time.Millisecond)
+	}
+}
+
+// cachedClockInterval is how often the cached clock is refreshed.
+const cachedClockInterval = 250 * time.Microsecond
+
+// cachedClock is a process-wide millisecond clock refreshed by a
+// background ticker, so readers pay for an atomic load instead of a
+// clock read. It is started on first use and runs for the lifetime of
+// the process (about 4000 wake-ups per second).
+// Not exported.
+var cachedClock struct {
+	once sync.Once
+	ms   atomic.Int64
+}
+
+// cachedNowMs returns the cached wall clock in Unix milliseconds,
+// starting the refresh goroutine on first use.
+// Not exported.
+func cachedNowMs() int64 {
+	cachedClock.once.Do(func() {
+		cachedClock.ms.Store(time.Now().UnixMilli())
+		go func() {
+			t := time.NewTicker(cachedClockInterval)
+			for now := range t.C {
+				cachedClock.ms.Store(now.UnixMilli())
+			}
+		}()
+	})
+	return cachedClock.ms.Load()
+}
+
+// ErrCoarseClock is returned by New with Config.CheckClockResolution
+// when the clock advances in steps much larger than a millisecond.
+var ErrCoarseClock = errors.New("clock resolution too coarse")
+
+const (
+	// maxClockStep is the largest clock step CheckClockResolution
+	// accepts.
+	maxClockStep = 2 * time.Millisecond
+	// clockProbeSteps is how many clock steps the probe measures; the
+	// smallest counts, so one preemption does not fail the check.
+	clockProbeSteps = 3
+	// clockProbeTimeout bounds how long the probe runs.
+	clockProbeTimeout = 200 * time.Millisecond
+)
+
+// clockProbe measures the resolution of a millisecond clock; replaced
+// in tests.
+// Not exported.
+var clockProbe = probeClockResolution
+
+// probeClockResolution measures the smallest step between successive
+// distinct readings of nowFunc over clockProbeSteps steps, giving up
+// with an error if the clock does not move for timeout of real time.
+// Not exported.
+func probeClockResolution(nowFunc func() int64, timeout time.Duration) (time.Duration, error) {
+	deadline := time.Now().Add(timeout)
+	prev := nowFunc()
+	// Wait for a tick boundary so the first step is a whole one.
+	for nowFunc() == prev {
+		if time.Now().After(deadline) {
+			return 0, fmt.Errorf("%w: clock did not advance in %v", ErrCoarseClock, timeout)
+		}
+	}
+	prev = nowFunc()
+	best := time.Duration(1<<63 - 1)
+	for i := 0; i < clockProbeSteps; i++ {
+		now := nowFunc()
+		for now == prev {
+			if time.Now().After(deadline) {
+				return 0, fmt.Errorf("%w: clock did not advance in %v", ErrCoarseClock, timeout)
+			}
+			now = nowFunc()
+		}
+		best = min(best, time.Duration(now-prev)*time.Millisecond)
+		prev = now
+	}
+	return best, nil
+}
+
+// checkClockResolution runs the clock probe for New and reports a
+// clock coarser than maxClockStep.
+// Not exported.
+func checkClockResolution(nowFunc func() int64) error {
+	step, err := clockProbe(nowFunc, clockProbeTimeout)
+	if err != nil {
+		return err
+	}
+	if step > maxClockStep {
+		return fmt.Errorf("%w: clock advances in %v steps, more than %v", ErrCoarseClock, step, maxClockStep)
+	}
+	return nil
+}
@@ -0,0 +1,218 @@
+package uniqid
+
+import (
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestCachedClock tests that the cached clock tracks the wall clock
+func TestCachedClock(t *testing.T) {
+	first := cachedNowMs()
+	if first > time.Now().UnixMilli() {
+		t.Errorf("Cached clock %d is ahead of the wall clock", first)
+	}
+
+	// The cached clock catches up with the wall clock
+	target := time.Now().UnixMilli() + 5
+	deadline := time.Now().Add(time.Second)
+	for cachedNowMs() < target {
+		if time.Now().After(deadline) {
+			t.Fatal("Cached clock did not advance")
+		}
+		time.Sleep(time.Millisecond)
+	}
+}
+
+// TestCachedClockMonotonic tests that IDs stay unique and ordered
+// when generated concurrently with the cached clock
+func TestCachedClockMonotonic(t *testing.T) {
+	gen, err := New(&Config{ShardID: 1, CachedClock: true})
+	if err != nil {
+		t.Fatalf("New with CachedClock failed: %v", err)
+	}
+
+	const workers, perWorker = 8, 20000
+	results := make([][]string, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			ids := make([]string, perWorker)
+			for i := range ids {
+				ids[i] = gen.Next()
+			}
+			results[w] = ids
+		}(w)
+	}
+	wg.Wait()
+
+	seen := make(map[string]struct{}, workers*perWorker)
+	for _, ids := range results {
+		var prev uint64
+		for i, id := range ids {
+			if _, dup := seen[id]; dup {
+				t.Fatalf("Duplicate ID %q", id)
+			}
+			seen[id] = struct{}{}
+			val, _ := decode(id, DefaultLayout)
+			if i > 0 && val <= prev {
+				t.Fatalf("IDs not monotonic within a goroutine: %q after previous", id)
+			}
+			prev = val
+		}
+	}
+}
+
+func BenchmarkNextParallel(b *testing.B) {
+	gen, _ := New(&Config{ShardID: 1})
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = gen.Next()
+		}
+	})
+}
+
+func BenchmarkNextParallelCachedClock(b *testing.B) {
+	gen, _ := New(&Config{ShardID: 1, CachedClock: true})
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = gen.Next()
+		}
+	})
+}
+
+// steppingClock returns a clock that advances by step ms every reads calls.
+func steppingClock(step int64, reads int) func() int64 {
+	var now int64 = 1_700_000_000_000
+	n := 0
+	return func() int64 {
+		n++
+		if n%reads == 0 {
+			now += step
+		}
+		return now
+	}
+}
+
+// TestProbeClockResolution tests measuring the clock's granularity
+func TestProbeClockResolution(t *testing.T) {
+	// Test case 1: Millisecond clock
+	step, err := probeClockResolution(steppingClock(1, 10), time.Second)
+	if err != nil || step != time.Millisecond {
+		t.Errorf("Expected 1ms step, got %v, %v", step, err)
+	}
+
+	// Test case 2: Coarse clock
+	step, err = probeClockResolution(steppingClock(15, 10), time.Second)
+	if err != nil || step != 15*time.Millisecond {
+		t.Errorf("Expected 15ms step, got %v, %v", step, err)
+	}
+
+	// Test case 3: A frozen clock times out
+	if _, err := probeClockResolution(func() int64 { return 1 }, 10*time.Millisecond); !errors.Is(err, ErrCoarseClock) {
+		t.Errorf("Expected ErrCoarseClock for a frozen clock, got %v", err)
+	}
+
+	// Test case 4: The real clock has millisecond resolution
+	if step, err := probeClockResolution(func() int64 { return time.Now().UnixMilli() }, time.Second); err != nil || step > maxClockStep {
+		t.Errorf("Expected fine system clock, got %v, %v", step, err)
+	}
+}
+
+// TestCheckClockResolution tests rejecting a coarse clock in New
+func TestCheckClockResolution(t *testing.T) {
+	original := clockProbe
+	defer func() { clockProbe = original }()
+
+	// Test case 1: A coarse clock is flagged
+	clockProbe = func(_ func() int64, timeout time.Duration) (time.Duration, error) {
+		return probeClockResolution(steppingClock(16, 100), timeout)
+	}
+	if _, err := New(&Config{ShardID: 1, CheckClockResolution: true}); !errors.Is(err, ErrCoarseClock) {
+		t.Errorf("Expected ErrCoarseClock, got %v", err)
+	}
+
+	// Test case 2: The check is opt-in
+	if _, err := New(&Config{ShardID: 1}); err != nil {
+		t.Errorf("Expected no check without CheckClockResolution, got %v", err)
+	}
+
+	// Test case 3: A fine clock passes
+	clockProbe = original
+	if _, err := New(&Config{ShardID: 1, CheckClockResolution: true}); err != nil {
+		t.Errorf("Expected system clock to pass, got %v", err)
+	}
+}
+
+// TestClockInjection tests generating IDs with an injected Clock
+func TestClockInjection(t *testing.T) {
+	start := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC)
+	clock := NewManualClock(start)
+	gen, err := New(&Config{ShardID: 1, Clock: clock, Layout: Layout{TimestampBits: 41, ShardBits: 10, SequenceBits: 1}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	// Test case 1: IDs carry the injected time
+	if p, _ := gen.Parse(gen.Next()); !p.Time.Equal(start) {
+		t.Errorf("Expected time %v, got %v", start, p.Time)
+	}
+
+	// Test case 2: A frozen clock exhausts the sequence
+	gen.Next()
+	if _, err := gen.TryNext(); err != ErrSequenceExhausted {
+		t.Errorf("Expected ErrSequenceExhausted with a frozen clock, got %v", err)
+	}
+
+	// Test case 3: Advancing and setting the clock move the timestamps
+	clock.Advance(1500 * time.Millisecond)
+	if p, _ := gen.Parse(gen.Next()); p.Time.UnixMilli() != start.UnixMilli()+1500 {
+		t.Errorf("Expected time after Advance, got %v", p.Time)
+	}
+	clock.Set(start)
+	if p, _ := gen.Parse(gen.Next()); p.Time.UnixMilli() != start.UnixMilli()+1500 || gen.Stats().ClockBackwards != 1 {
+		t.Errorf("Expected a backwards clock to keep the last timestamp, got %v", p.Time)
+	}
+
+	// Test case 4: ClockFunc adapts functions, and CachedClock conflicts
+	if ClockFunc(func() int64 { return 42 }).NowMs() != 42 {
+		t.Error("ClockFunc does not return the function's value")
+	}
+	if now := SystemClock.NowMs(); time.Since(time.UnixMilli(now)).Abs() > time.Second {
+		t.Errorf("Unexpected system clock reading %d", now)
+	}
+	if _, err := New(&Config{Clock: clock, CachedClock: true}); err == nil {
+		t.Error("Expected error for Clock with CachedClock, got nil")
+	}
+}
+
+// TestMonotonicClock tests the default clock anchored at creation
+func TestMonotonicClock(t *testing.T) {
+	// Test case 1: It starts at wall time and never goes backwards
+	clock := monotonicClock()
+	var prev int64
+	for i := 0; i < 10000; i++ {
+		now := clock()
+		if now < prev {
+			t.Fatalf("Clock went backwards: %d after %d", now, prev)
+		}
+		prev = now
+	}
+	if d := time.Now().UnixMilli() - clock(); d < -1 || d > 1 {
+		t.Errorf("Expected the clock within 1ms of wall time, off by %dms", d)
+	}
+
+	// Test case 2: WallClock generators read the wall clock
+	gen, err := New(&Config{ShardID: 1, WallClock: true})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if p, _ := gen.Parse(gen.Next()); time.Since(p.Time).Abs() > time.Second {
+		t.Errorf("Expected a wall-clock timestamp, got %v", p.Time)
+	}
+}
@@ -0,0 +1,137 @@
+package uniqid
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Instance metadata endpoints queried by the cloud shard sources.
+const (
+	awsMetadataURL   = "http://169.254.169.254/latest"
+	gcpMetadataURL   = "http://metadata.google.internal/computeMetadata/v1/instance"
+	azureMetadataURL = "http://169.254.169.254/metadata/instance/compute"
+)
+
+// metadataTimeout bounds each instance metadata request, so New fails
+// quickly outside the expected cloud.
+const metadataTimeout = 2 * time.Second
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// metadataClient performs instance metadata requests.
+var metadataClient = &http.Client{Timeout: metadataTimeout}
+
+// fetchMetadata performs a metadata request and returns the body of a
+// 200 response.
+func fetchMetadata(method, url string, header http.Header) (string, error) {
+	req, err := http.NewRequest(method, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header = header
+	resp, err := metadataClient.Do(req)
+	if err != nil {
+		return "", err
+	}
+	defer resp.Body.Close()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	if err != nil {
+		return "", err
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("%s %s: %s", method, url, resp.Status)
+	}
+	return strings.TrimSpace(string(body)), nil
+}
+
+// cloudShard derives the shard for the cloud source d.source. With
+// d.tag set it reads the shard number from that instance tag;
+// otherwise it hashes the instance's (or ECS task's) unique ID.
+func cloudShard(d deps) (shard uint16, source, detail string, err error) {
+	var cloud, id string
+	switch d.source {
+	case ShardSourceAWS:
+		cloud, id, err = awsMetadata(d)
+	case ShardSourceGCP:
+		cloud = "gcp"
+		header := http.Header{"Metadata-Flavor": {"Google"}}
+		if d.tag != "" {
+			id, err = d.metadata(http.MethodGet, gcpMetadataURL+"/attributes/"+d.tag, header)
+		} else {
+			id, err = d.metadata(http.MethodGet, gcpMetadataURL+"/id", header)
+		}
+	case ShardSourceAzure:
+		cloud, id, err = azureMetadata(d)
+	}
+	if err != nil {
+		return 0, "", "", fmt.Errorf("reading %s instance metadata: %w", cloud, err)
+	}
+	if d.tag != "" {
+		n, err := strconv.ParseUint(id, 10, 16)
+		if err != nil || n > 0x3FF {
+			return 0, "", "", fmt.Errorf("%s tag %s=%q is not a shard in 0..1023", cloud, d.tag, id)
+		}
+		return uint16(n), cloud + "-tag", d.tag + "=" + id, nil
+	}
+	if id == "" {
+		return 0, "", "", fmt.Errorf("empty %s instance ID", cloud)
+	}
+	shard, detail = hashShard(nil, id)
+	return shard, cloud + "-id", detail, nil
+}
+
+// awsMetadata returns the ECS task ARN when running as an ECS task,
+// else the EC2 instance ID or d.tag's value, read through IMDSv2.
+func awsMetadata(d deps) (cloud, id string, err error) {
+	if uri := d.getenv("ECS_CONTAINER_METADATA_URI_V4"); uri != "" && d.tag == "" {
+		body, err := d.metadata(http.MethodGet, uri+"/task", nil)
+		if err != nil {
+			return "ecs", "", err
+		}
+		var task struct{ TaskARN string }
+		if err := json.Unmarshal([]byte(body), &task); err != nil {
+			return "ecs", "", err
+		}
+		return "ecs", task.TaskARN, nil
+	}
+	token, err := d.metadata(http.MethodPut, awsMetadataURL+"/api/token",
+		http.Header{"X-Aws-Ec2-Metadata-Token-Ttl-Seconds": {"60"}})
+	if err != nil {
+		return "aws", "", err
+	}
+	header := http.Header{"X-Aws-Ec2-Metadata-Token": {token}}
+	if d.tag != "" {
+		id, err = d.metadata(http.MethodGet, awsMetadataURL+"/meta-data/tags/instance/"+d.tag, header)
+	} else {
+		id, err = d.metadata(http.MethodGet, awsMetadataURL+"/meta-data/instance-id", header)
+	}
+	return "aws", id, err
+}
+
+// azureMetadata returns the VM ID, or d.tag's value from the tag list
+// ("key1:value1;key2:value2").
+func azureMetadata(d deps) (cloud, id string, err error) {
+	header := http.Header{"Metadata": {"true"}}
+	const query = "?api-version=2021-02-01&format=text"
+	if d.tag == "" {
+		id, err = d.metadata(http.MethodGet, azureMetadataURL+"/vmId"+query, header)
+		return "azure", id, err
+	}
+	tags, err := d.metadata(http.MethodGet, azureMetadataURL+"/tags"+query, header)
+	if err != nil {
+		return "azure", "", err
+	}
+	for _, kv := range strings.Split(tags, ";") {
+		if k, v, ok := strings.Cut(kv, ":"); ok && k == d.tag {
+			return "azure", v, nil
+		}
+	}
+	return "azure", "", fmt.Errorf("no tag %q", d.tag)
+}
@@ -0,0 +1,90 @@
+package uniqid
+
+import (
+	"errors"
+	"net/http"
+	"testing"
+)
+
+// TestCloudShard tests deriving the shard from cloud instance metadata
+func TestCloudShard(t *testing.T) {
+	// fakeMetadata serves bodies by URL and records the headers sent.
+	var headers []http.Header
+	fakeMetadata := func(bodies map[string]string) func(string, string, http.Header) (string, error) {
+		return func(method, url string, header http.Header) (string, error) {
+			headers = append(headers, header)
+			body, ok := bodies[method+" "+url]
+			if !ok {
+				return "", errors.New("404 Not Found")
+			}
+			return body, nil
+		}
+	}
+	newDeps := func(source ShardSource, tag string, bodies map[string]string, env map[string]string) deps {
+		return deps{
+			source:   source,
+			tag:      tag,
+			metaFunc: fakeMetadata(bodies),
+			envFunc:  func(k string) string { return env[k] },
+		}
+	}
+	ec2 := map[string]string{
+		"PUT " + awsMetadataURL + "/api/token":                  "tok",
+		"GET " + awsMetadataURL + "/meta-data/instance-id":      "i-0abc123",
+		"GET " + awsMetadataURL + "/meta-data/tags/instance/id": "17",
+	}
+
+	// Test case 1: Each cloud hashes its instance ID
+	idShard, _ := hashShard(nil, "i-0abc123")
+	for _, tc := range []struct {
+		d      deps
+		source string
+		detail string
+	}{
+		{newDeps(ShardSourceAWS, "", ec2, nil), "aws-id", "i-0abc123"},
+		{newDeps(ShardSourceGCP, "", map[string]string{"GET " + gcpMetadataURL + "/id": "i-0abc123"}, nil), "gcp-id", "i-0abc123"},
+		{newDeps(ShardSourceAzure, "", map[string]string{"GET " + azureMetadataURL + "/vmId?api-version=2021-02-01&format=text": "i-0abc123"}, nil), "azure-id", "i-0abc123"},
+	} {
+		shard, source, detail, err := autoShardExplain(tc.d)
+		if err != nil || shard != idShard || source != tc.source || detail != tc.detail {
+			t.Errorf("Got %d %q %q %v, want %d %q", shard, source, detail, err, idShard, tc.source)
+		}
+	}
+	if got := headers[1].Get("X-Aws-Ec2-Metadata-Token"); got != "tok" {
+		t.Errorf("Expected the IMDSv2 token to be sent, got %q", got)
+	}
+
+	// Test case 2: ECS tasks hash their task ARN
+	ecs := map[string]string{"GET http://169.254.170.2/v4/x/task": `{"TaskARN":"arn:aws:ecs:task/1"}`}
+	d := newDeps(ShardSourceAWS, "", ecs, map[string]string{"ECS_CONTAINER_METADATA_URI_V4": "http://169.254.170.2/v4/x"})
+	want, _ := hashShard(nil, "arn:aws:ecs:task/1")
+	if shard, source, _, err := autoShardExplain(d); err != nil || shard != want || source != "ecs-id" {
+		t.Errorf("Got %d %q %v, want %d from the task ARN", shard, source, err, want)
+	}
+
+	// Test case 3: A tag supplies the shard number directly
+	if shard, source, detail, err := autoShardExplain(newDeps(ShardSourceAWS, "id", ec2, nil)); err != nil || shard != 17 || source != "aws-tag" || detail != "id=17" {
+		t.Errorf("Got %d %q %q %v, want shard 17 from the tag", shard, source, detail, err)
+	}
+	azureTags := map[string]string{"GET " + azureMetadataURL + "/tags?api-version=2021-02-01&format=text": "env:prod;shard:9"}
+	if shard, _, _, err := autoShardExplain(newDeps(ShardSourceAzure, "shard", azureTags, nil)); err != nil || shard != 9 {
+		t.Errorf("Got %d %v, want shard 9 from the Azure tag list", shard, err)
+	}
+
+	// Test case 4: Missing metadata and bad tag values are errors
+	for _, d := range []deps{
+		newDeps(ShardSourceGCP, "", nil, nil),
+		newDeps(ShardSourceAzure, "missing", azureTags, nil),
+		newDeps(ShardSourceAzure, "env", azureTags, nil),
+		{source: ShardSourceAWS},
+	} {
+		if _, err := autoShardWithDeps(d); err == nil {
+			t.Errorf("Expected error for source %d tag %q, got nil", d.source, d.tag)
+		}
+	}
+
+	// Test case 5: A tag without a cloud source is rejected
+	if _, err := New(&Config{ShardID: -1, ShardMetadataTag: "shard"}); err == nil {
+		t.Error("Expected error for ShardMetadataTag without a cloud source, got nil")
+	}
+}
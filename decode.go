@@ -0,0 +1,324 @@
+package uniqid
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// ErrInvalidID is returned when a string is not a well-formed ID.
+var ErrInvalidID = errors.New("invalid ID")
+
+// ErrSaltMismatch is returned by Generator.Parse and ParseWith for an
+// ID whose salt differs from the configured Config.Salt, i.e. an ID
+// from another environment. It wraps ErrInvalidID.
+var ErrSaltMismatch = fmt.Errorf("%w: salt mismatch", ErrInvalidID)
+
+// ErrAmbiguousID is returned by ParseAuto when a string is valid in
+// more than one encoding.
+var ErrAmbiguousID = errors.New("ambiguous ID encoding")
+
+// Parts holds the components encoded in an ID.
+//
+// Fields:
+//   - Time: Generation time, millisecond precision.
+//   - Shard: Shard ID of the generator that produced the ID; always 0
+//     for layouts without a shard field.
+//   - Seq: Sequence number within the millisecond.
+//   - Tag: Application-defined tag set by NextTagged; always 0 for
+//     layouts without a tag field.
+//   - Counter: Low bits of the generator's issue counter; always 0
+//     for layouts without a counter field.
+//   - Salt: Environment marker from Config.Salt, held in the
+//     reserved bits; 0 if none.
+//   - Overflow: Burst overflow count from Config.BurstOverflow, held
+//     in the reserved bits instead of a salt; 0 outside bursts.
+type Parts struct {
+	Time     time.Time
+	Shard    uint16
+	Seq      uint16
+	Tag      uint64
+	Counter  uint64
+	Salt     uint16
+	Overflow uint16
+}
+
+// Parse decomposes an ID produced with the default epoch into its
+// components. Use Generator.Parse for IDs from a generator configured
+// with CustomEpochMs.
+//
+// Example:
+//
+//	p, err := uniqid.Parse("Ab3Xyz0LmN_")
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	fmt.Println(p.Time, p.Shard, p.Seq)
+func Parse(id string) (Parts, error) {
+	val, err := decode(id, DefaultLayout)
+	if err != nil {
+		return Parts{}, err
+	}
+	return DefaultLayout.parts(val, defaultEpochMs, 1), nil
+}
+
+// Parse decomposes an ID produced by g, or by any generator sharing
+// its epoch, into its components. If g has a Prefix or VersionPrefix,
+// id must start with them. With Config.Checksum the check character
+// must match, with Config.SigningKey the signature must, and the ID's
+// salt must match g's Config.Salt. With Config.ObfuscationKey the
+// value is decrypted first.
+func (g *Generator) Parse(id string) (Parts, error) {
+	val, err := g.decodeString(id)
+	if err != nil {
+		return Parts{}, err
+	}
+	return g.partsOf(val)
+}
+
+// ParseWith decomposes an ID using the format settings of cfg: Layout
+// (or NoShard), CustomEpochMs (or UnixEpoch), TimestampUnit, Prefix,
+// Checksum, SigningKey, ObfuscationKey, VersionPrefix, Alphabet,
+// Encoding, Salt, BurstOverflow and BannedSubstrings (for its
+// re-rolled reserved bits). Other fields are ignored, and no
+// generator is created. It decodes IDs minted under a configuration
+// other than the current one, e.g. historical IDs after a layout
+// migration. A nil cfg selects the defaults, like Parse.
+//
+// Example:
+//
+//	old := &uniqid.Config{
+//	    Layout: uniqid.Layout{TimestampBits: 39, ShardBits: 10, SequenceBits: 15},
+//	}
+//	p, err := uniqid.ParseWith(legacyID, old)
+func ParseWith(id string, cfg *Config) (Parts, error) {
+	g, err := newDecoder(cfg)
+	if err != nil {
+		return Parts{}, err
+	}
+	return g.Parse(id)
+}
+
+// ParseAuto is like Parse but also accepts the textual forms a
+// default-format ID's packed value takes outside this package, for
+// readers fed by a mix of sources during a storage or encoding
+// migration. It infers the encoding from length and character set:
+//
+//   - The 11-character ID, as from Next (separators from
+//     NextDelimited allowed).
+//   - Hexadecimal: exactly 16 hex digits, either case, optionally
+//     prefixed by "0x" or "0X", e.g. a BINARY(8) column as shown by
+//     SQL tools.
+//   - Decimal: up to 20 digits, e.g. a BIGINT column (see
+//     Layout.SQLType).
+//
+// A string of exactly 16 decimal digits is both valid hex and valid
+// decimal and returns ErrAmbiguousID; add the "0x" prefix to mark hex.
+// No other string is ambiguous: the first character of an 11-character
+// ID is always one of A-P, so a string of digits is never one.
+// ErrInvalidID is returned if no encoding matches.
+//
+// Base62 is not detected. The package has no base62 encoding, and an
+// 11-character base62 string uses only characters of the default
+// alphabet, so it could not be told apart from an ID and would decode
+// to a different value.
+func ParseAuto(s string) (Parts, error) {
+	var val uint64
+	matches := 0
+	if v, err := decode(s, DefaultLayout); err == nil {
+		val = v
+		matches++
+	}
+	hex := s
+	if len(s) > 2 && s[0] == '0' && (s[1] == 'x' || s[1] == 'X') {
+		hex = s[2:]
+	}
+	if len(hex) == 16 {
+		if v, err := strconv.ParseUint(hex, 16, 64); err == nil {
+			val = v
+			matches++
+		}
+	}
+	if len(s) <= 20 {
+		if v, err := strconv.ParseUint(s, 10, 64); err == nil {
+			val = v
+			matches++
+		}
+	}
+	switch matches {
+	case 0:
+		return Parts{}, ErrInvalidID
+	case 1:
+		return DefaultLayout.parts(val, defaultEpochMs, 1), nil
+	}
+	return Parts{}, ErrAmbiguousID
+}
+
+// DecodeAll decodes IDs in the default format to their packed 64-bit
+// values, e.g. to build a compact in-memory index. It stops at the
+// first invalid ID and returns an error naming its index.
+//
+// Packed values sort in generation order, unlike the default-alphabet
+// strings.
+func DecodeAll(ids []string) ([]uint64, error) {
+	vals := make([]uint64, len(ids))
+	for i, id := range ids {
+		val, err := decode(id, DefaultLayout)
+		if err != nil {
+			return nil, fmt.Errorf("index %d: %w", i, err)
+		}
+		vals[i] = val
+	}
+	return vals, nil
+}
+
+// Age returns how long ago the given ID was generated, measured
+// against the current wall clock.
+//
+// baseEpoch must be the CustomEpochMs the ID was generated with;
+// 0 selects the default epoch, matching New.
+//
+// Example:
+//
+//	if age, err := uniqid.Age(id, 0); err == nil && age > ttl {
+//	    evict(id)
+//	}
+func Age(id string, baseEpoch int64) (time.Duration, error) {
+	val, err := decode(id, DefaultLayout)
+	if err != nil {
+		return 0, err
+	}
+	if baseEpoch == 0 {
+		baseEpoch = defaultEpochMs
+	}
+	return time.Since(DefaultLayout.parts(val, baseEpoch, 1).Time), nil
+}
+
+// TimeOf returns the generation time of a default-format ID, e.g. for
+// retention jobs that expire records purely from their IDs. Like
+// Parse it assumes the default epoch; use Generator.TimeOf otherwise.
+//
+// Example:
+//
+//	cutoff := time.Now().AddDate(0, 0, -90)
+//	if t, err := uniqid.TimeOf(id); err == nil && t.Before(cutoff) {
+//	    expire(id)
+//	}
+func TimeOf(id string) (time.Time, error) {
+	p, err := Parse(id)
+	return p.Time, err
+}
+
+// TimeOf returns the generation time of an ID in g's format, as
+// Generator.Parse decodes it.
+func (g *Generator) TimeOf(id string) (time.Time, error) {
+	p, err := g.Parse(id)
+	return p.Time, err
+}
+
+// Age is like the package-level Age for IDs in g's format, measured
+// against the current wall clock.
+func (g *Generator) Age(id string) (time.Duration, error) {
+	t, err := g.TimeOf(id)
+	if err != nil {
+		return 0, err
+	}
+	return time.Since(t), nil
+}
+
+// decode converts an ID in the default alphabet back to its packed
+// value for layout l.
+// Not exported.
+func decode(id string, l Layout) (uint64, error) {
+	return defaultCodec.decode(id, l)
+}
+
+// decodeString converts an ID in g's format back to its packed value,
+// checking the prefixes, check character and signature.
+// Not exported.
+func (g *Generator) decodeString(id string) (uint64, error) {
+	id, ok := strings.CutPrefix(id, g.prefix)
+	if !ok {
+		return 0, ErrInvalidID
+	}
+	if g.version != 0 {
+		if len(id) == 0 || id[0] != g.version {
+			return 0, ErrInvalidID
+		}
+		id = id[1:]
+	}
+	var sig string
+	if len(g.signKey) > 0 {
+		var err error
+		if id, sig, err = g.splitSignature(id); err != nil {
+			return 0, err
+		}
+	}
+	if g.checksum {
+		var err error
+		if id, err = g.codec.stripCheck(id, g.layout); err != nil {
+			return 0, err
+		}
+	}
+	val, err := g.codec.decode(id, g.layout)
+	if err != nil {
+		return 0, err
+	}
+	if len(g.obfKey) > 0 {
+		val = permute(g.obfKey, val, g.layout.Bits(), true)
+	}
+	if len(g.signKey) > 0 && !g.checkSignature(sig, val) {
+		return 0, ErrSignatureMismatch
+	}
+	return val, nil
+}
+
+// partsOf splits a packed value of g's layout into its components,
+// reading the reserved bits as an overflow count or a salt, and checks
+// the salt.
+// Not exported.
+func (g *Generator) partsOf(val uint64) (Parts, error) {
+	p := g.layout.parts(val, g.baseEpoch, g.unit)
+	if g.overflow {
+		p.Overflow, p.Salt = p.Salt, 0
+	}
+	if g.reroll {
+		// The reserved bits were re-rolled for Config.BannedSubstrings.
+		p.Salt = 0
+	}
+	if p.Salt != g.salt {
+		return Parts{}, ErrSaltMismatch
+	}
+	return p, nil
+}
+
+// newDecoder returns a generator carrying only the format settings of
+// cfg, for decoding and encoding without generating IDs.
+// Not exported.
+func newDecoder(cfg *Config) (*Generator, error) {
+	c := Config{}
+	if cfg != nil {
+		c = *cfg
+	}
+	c.ShardID = 0
+	if err := c.Validate(); err != nil {
+		return nil, err
+	}
+	g := &Generator{
+		baseEpoch: c.epoch(),
+		layout:    c.layout(),
+		prefix:    c.Prefix,
+		checksum:  c.Checksum,
+		signKey:   c.SigningKey,
+		obfKey:    c.ObfuscationKey,
+		version:   c.VersionPrefix,
+		unit:      max(c.TimestampUnit.Milliseconds(), 1),
+		codec:     codecFor(c.Encoding, c.alphabet()),
+		salt:      c.Salt,
+		overflow:  c.BurstOverflow,
+		reroll:    c.reroll(),
+	}
+	return g, nil
+}
@@ -0,0 +1,435 @@
+package uniqid
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestDecode tests decoding IDs back to their packed values
+func TestDecode(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	gen, _ := New(&Config{ShardID: 7})
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	id := gen.Next()
+	val, err := decode(id, DefaultLayout)
+	if err != nil {
+		t.Fatalf("decode(%q) failed: %v", id, err)
+	}
+	if got := int64(val >> 25); got != mockTime-gen.baseEpoch {
+		t.Errorf("Expected timestamp %d, got %d", mockTime-gen.baseEpoch, got)
+	}
+	if got := (val >> 15) & 0x3FF; got != 7 {
+		t.Errorf("Expected shard 7, got %d", got)
+	}
+
+	// Invalid inputs
+	for _, bad := range []string{"", "short", "Ab3Xyz0LmN_x", "Ab3Xyz0Lm*_", "zzzzzzzzzzz"} {
+		if _, err := decode(bad, DefaultLayout); err != ErrInvalidID {
+			t.Errorf("decode(%q): expected ErrInvalidID, got %v", bad, err)
+		}
+	}
+}
+
+// TestParse tests decomposing IDs into their components
+func TestParse(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	gen, _ := New(&Config{ShardID: 42})
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	_ = gen.Next()
+	id := gen.Next()
+	p, err := Parse(id)
+	if err != nil {
+		t.Fatalf("Parse(%q) failed: %v", id, err)
+	}
+	if p.Time.UnixMilli() != mockTime {
+		t.Errorf("Expected time %d, got %d", mockTime, p.Time.UnixMilli())
+	}
+	if p.Shard != 42 {
+		t.Errorf("Expected shard 42, got %d", p.Shard)
+	}
+	if p.Seq != 1 {
+		t.Errorf("Expected seq 1, got %d", p.Seq)
+	}
+
+	// Custom epoch requires the generator's Parse
+	customEpoch := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	gen, _ = New(&Config{ShardID: 3, CustomEpochMs: customEpoch})
+	gen.deps.nowFunc = func() int64 { return mockTime }
+	p, err = gen.Parse(gen.Next())
+	if err != nil {
+		t.Fatalf("Generator.Parse failed: %v", err)
+	}
+	if p.Time.UnixMilli() != mockTime || p.Shard != 3 || p.Seq != 0 {
+		t.Errorf("Unexpected parts for custom epoch: %+v", p)
+	}
+
+	// Invalid ID
+	if _, err := Parse("bad"); err != ErrInvalidID {
+		t.Errorf("Expected ErrInvalidID, got %v", err)
+	}
+	if _, err := gen.Parse("bad"); err != ErrInvalidID {
+		t.Errorf("Expected ErrInvalidID from Generator.Parse, got %v", err)
+	}
+}
+
+// TestVersionPrefix tests generating and parsing version-prefixed IDs
+func TestVersionPrefix(t *testing.T) {
+	gen, err := New(&Config{ShardID: 5, VersionPrefix: '1'})
+	if err != nil {
+		t.Fatalf("New with VersionPrefix failed: %v", err)
+	}
+	id := gen.Next()
+	if len(id) != 12 || id[0] != '1' {
+		t.Fatalf("Expected 12-character ID starting with '1', got %q", id)
+	}
+	p, err := gen.Parse(id)
+	if err != nil {
+		t.Fatalf("Parse(%q) failed: %v", id, err)
+	}
+	if p.Shard != 5 {
+		t.Errorf("Expected shard 5, got %d", p.Shard)
+	}
+
+	// A wrong or missing prefix is rejected
+	for _, bad := range []string{"2" + id[1:], id[1:], ""} {
+		if _, err := gen.Parse(bad); err != ErrInvalidID {
+			t.Errorf("Parse(%q): expected ErrInvalidID, got %v", bad, err)
+		}
+	}
+
+	// The prefix must come from the alphabet
+	if _, err := New(&Config{ShardID: 5, VersionPrefix: '/'}); err == nil {
+		t.Error("Expected error for prefix outside the alphabet, got nil")
+	}
+}
+
+// TestPrefix tests generating and parsing IDs with a type prefix
+func TestPrefix(t *testing.T) {
+	gen, err := New(&Config{ShardID: 5, Prefix: "ord_", VersionPrefix: '1'})
+	if err != nil {
+		t.Fatalf("New with Prefix failed: %v", err)
+	}
+
+	// Test case 1: The prefix leads every ID and counts in Len
+	id := gen.Next()
+	if !strings.HasPrefix(id, "ord_1") || len(id) != 16 || gen.Len() != 16 {
+		t.Fatalf("Expected a 16-character ID starting with \"ord_1\", got %q", id)
+	}
+	if p, err := gen.Parse(id); err != nil || p.Shard != 5 {
+		t.Errorf("Parse(%q) = %+v, %v", id, p, err)
+	}
+	if _, err := ParseWith(id, &Config{Prefix: "ord_", VersionPrefix: '1'}); err != nil {
+		t.Errorf("ParseWith(%q) failed: %v", id, err)
+	}
+
+	// Test case 2: IDs with another or no prefix are rejected
+	for _, bad := range []string{"inv_" + id[4:], id[4:], "ord_"} {
+		if _, err := gen.Parse(bad); err != ErrInvalidID {
+			t.Errorf("Parse(%q): expected ErrInvalidID, got %v", bad, err)
+		}
+	}
+
+	// Test case 3: The prefix survives ExportJSON and ImportJSON
+	b, _ := gen.ExportJSON()
+	imported, err := ImportJSON(b)
+	if err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+	if next := imported.Next(); !strings.HasPrefix(next, "ord_1") {
+		t.Errorf("Expected imported IDs to keep the prefix, got %q", next)
+	}
+
+	// Test case 4: Invalid prefixes are rejected
+	for _, bad := range []string{"ord id_", "caf\u00e9_", strings.Repeat("x", 17)} {
+		if _, err := New(&Config{ShardID: 5, Prefix: bad}); err == nil {
+			t.Errorf("Expected error for prefix %q, got nil", bad)
+		}
+	}
+}
+
+// TestParseWith tests decoding IDs minted under a different configuration
+func TestParseWith(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	epoch := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	oldCfg := &Config{ShardID: 700, CustomEpochMs: epoch, Layout: Layout{TimestampBits: 39, ShardBits: 10, SequenceBits: 15}}
+	newCfg := &Config{ShardID: 3, CustomEpochMs: epoch, Layout: Layout{TimestampBits: 42, ShardBits: 6, SequenceBits: 16}}
+	oldGen, _ := New(oldCfg)
+	oldGen.deps.nowFunc = func() int64 { return mockTime }
+	newGen, _ := New(newCfg)
+	oldGen.Next()
+	legacy := oldGen.Next()
+
+	// Test case 1: The current generator misreads the legacy ID
+	if p, err := newGen.Parse(legacy); err == nil && p.Shard == 700 {
+		t.Fatal("Expected the new layout to decode the legacy ID differently")
+	}
+
+	// Test case 2: An explicit configuration decodes it
+	p, err := ParseWith(legacy, oldCfg)
+	if err != nil {
+		t.Fatalf("ParseWith failed: %v", err)
+	}
+	if p.Shard != 700 || p.Seq != 1 || p.Time.UnixMilli() != mockTime {
+		t.Errorf("Unexpected parts: %+v", p)
+	}
+
+	// Test case 3: Alphabet, prefix and timestamp unit are honored
+	cfg := &Config{ShardID: 2, Alphabet: SortableAlphabet, VersionPrefix: 'v', TimestampUnit: 4 * time.Millisecond}
+	gen, _ := New(cfg)
+	gen.deps.nowFunc = func() int64 { return mockTime }
+	want, _ := gen.Parse(gen.Next())
+	if got, err := ParseWith(gen.Next(), cfg); err != nil || got.Time != want.Time || got.Shard != 2 {
+		t.Errorf("Expected %+v, got %+v, %v", want, got, err)
+	}
+
+	// Test case 4: nil selects the defaults
+	def, _ := New(&Config{ShardID: 9})
+	if p, err := ParseWith(def.Next(), nil); err != nil || p.Shard != 9 {
+		t.Errorf("Expected default decoding, got %+v, %v", p, err)
+	}
+
+	// Test case 5: Invalid configurations are reported
+	if _, err := ParseWith(legacy, &Config{Layout: Layout{TimestampBits: 60, ShardBits: 10, SequenceBits: 15}}); err == nil {
+		t.Error("Expected error for invalid layout, got nil")
+	}
+}
+
+// TestParseAuto tests detecting the encoding of an ID
+func TestParseAuto(t *testing.T) {
+	gen, err := New(&Config{ShardID: 9})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	id := gen.Next()
+	want, _ := Parse(id)
+	val, _ := decode(id, DefaultLayout)
+
+	// Test case 1: Every supported encoding yields the same parts
+	for _, s := range []string{
+		id,
+		id[:4] + "." + id[4:8] + "." + id[8:],
+		fmt.Sprintf("%016x", val),
+		fmt.Sprintf("%016X", val),
+		fmt.Sprintf("0x%016x", val),
+		fmt.Sprintf("0X%016X", val),
+		strconv.FormatUint(val, 10),
+	} {
+		got, err := ParseAuto(s)
+		if err != nil {
+			t.Errorf("ParseAuto(%q) failed: %v", s, err)
+		} else if got != want {
+			t.Errorf("ParseAuto(%q) = %+v, expected %+v", s, got, want)
+		}
+	}
+
+	// Test case 2: 16 decimal digits are ambiguous unless marked as hex
+	if _, err := ParseAuto("1234567890123456"); err != ErrAmbiguousID {
+		t.Errorf("Expected ErrAmbiguousID, got %v", err)
+	}
+	if _, err := ParseAuto("0X1234567890123456"); err != nil {
+		t.Errorf("Expected prefixed hex to parse, got %v", err)
+	}
+
+	// Test case 3: Other lengths of digits are plain decimal
+	p, err := ParseAuto("12345678901")
+	if err != nil || p.Seq != 12345678901&0x7FFF {
+		t.Errorf("Unexpected result for 11 digits: %+v, %v", p, err)
+	}
+
+	// Test case 4: Nothing matches
+	for _, bad := range []string{"", "bad", "0x12", "123456789012345678901", "-1", "0x123456789012345g"} {
+		if _, err := ParseAuto(bad); err != ErrInvalidID {
+			t.Errorf("ParseAuto(%q): expected ErrInvalidID, got %v", bad, err)
+		}
+	}
+}
+
+// TestDecodeAll tests decoding slices of IDs in bulk
+func TestDecodeAll(t *testing.T) {
+	gen, _ := New(&Config{ShardID: 4})
+	ids := gen.NextN(100)
+
+	// Test case 1: All IDs decode in order
+	vals, err := DecodeAll(ids)
+	if err != nil {
+		t.Fatalf("DecodeAll failed: %v", err)
+	}
+	for i, id := range ids {
+		want, _ := decode(id, DefaultLayout)
+		if vals[i] != want {
+			t.Errorf("Index %d: expected %d, got %d", i, want, vals[i])
+		}
+		if i > 0 && vals[i] <= vals[i-1] {
+			t.Errorf("Index %d: values not increasing", i)
+		}
+	}
+
+	// Test case 2: The first invalid ID is reported by index
+	ids[42] = "bad!"
+	ids[70] = "also bad"
+	_, err = DecodeAll(ids)
+	if !errors.Is(err, ErrInvalidID) || !strings.Contains(err.Error(), "index 42") {
+		t.Errorf("Expected ErrInvalidID at index 42, got %v", err)
+	}
+
+	// Test case 3: Empty input
+	if vals, err := DecodeAll(nil); err != nil || len(vals) != 0 {
+		t.Errorf("Expected empty result, got %v, %v", vals, err)
+	}
+}
+
+func BenchmarkDecodeAll(b *testing.B) {
+	gen, _ := New(&Config{ShardID: 4})
+	ids := gen.NextN(100000)
+	b.ResetTimer()
+	for i := 0; i < b.N; i++ {
+		if _, err := DecodeAll(ids); err != nil {
+			b.Fatal(err)
+		}
+	}
+}
+
+// TestSalt tests rejecting IDs from another environment by salt
+func TestSalt(t *testing.T) {
+	layout := Layout{TimestampBits: 39, ShardBits: 8, SequenceBits: 13, ReservedBits: 4}
+	staging, err := New(&Config{ShardID: 1, Layout: layout, Salt: 1})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	prod, _ := New(&Config{ShardID: 1, Layout: layout, Salt: 2})
+
+	// Test case 1: The salt round-trips
+	id := staging.Next()
+	p, err := staging.Parse(id)
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if p.Salt != 1 || p.Shard != 1 {
+		t.Errorf("Unexpected parts: %+v", p)
+	}
+
+	// Test case 2: A generator configured for another salt rejects it
+	if _, err := prod.Parse(id); !errors.Is(err, ErrSaltMismatch) || !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Expected ErrSaltMismatch, got %v", err)
+	}
+	if _, err := ParseWith(id, &Config{Layout: layout, Salt: 2}); !errors.Is(err, ErrSaltMismatch) {
+		t.Errorf("Expected ErrSaltMismatch from ParseWith, got %v", err)
+	}
+	if _, err := ParseWith(id, &Config{Layout: layout, Salt: 1}); err != nil {
+		t.Errorf("Expected ParseWith with matching salt to succeed, got %v", err)
+	}
+	b := staging.NextBinary()
+	if _, err := prod.ParseBinary(b[:]); !errors.Is(err, ErrSaltMismatch) {
+		t.Errorf("Expected ErrSaltMismatch from ParseBinary, got %v", err)
+	}
+
+	// Test case 3: The salt must fit the reserved bits
+	if _, err := New(&Config{ShardID: 1, Layout: layout, Salt: 16}); err == nil {
+		t.Error("Expected error for salt wider than the reserved bits, got nil")
+	}
+	if _, err := New(&Config{ShardID: 1, Salt: 1}); err == nil {
+		t.Error("Expected error for salt without reserved bits, got nil")
+	}
+
+	// Test case 4: Exported state keeps the salt
+	js, _ := staging.ExportJSON()
+	imported, err := ImportJSON(js)
+	if err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+	if _, err := imported.Parse(id); err != nil {
+		t.Errorf("Expected imported generator to accept its salt, got %v", err)
+	}
+}
+
+// TestAge tests computing the age of an ID
+func TestAge(t *testing.T) {
+	// Test case 1: A freshly generated ID is close to zero age
+	gen, _ := New(&Config{ShardID: 1})
+	age, err := Age(gen.Next(), 0)
+	if err != nil {
+		t.Fatalf("Age failed: %v", err)
+	}
+	if age < 0 || age > time.Second {
+		t.Errorf("Expected near-zero age for fresh ID, got %v", age)
+	}
+
+	// Test case 2: An ID generated an hour ago, with a custom epoch
+	customEpoch := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	gen, _ = New(&Config{ShardID: 1, CustomEpochMs: customEpoch})
+	gen.deps.nowFunc = func() int64 { return time.Now().Add(-time.Hour).UnixMilli() }
+	age, err = Age(gen.Next(), customEpoch)
+	if err != nil {
+		t.Fatalf("Age failed: %v", err)
+	}
+	if age < time.Hour || age > time.Hour+time.Second {
+		t.Errorf("Expected age of about 1h, got %v", age)
+	}
+
+	// Test case 3: Invalid ID
+	if _, err := Age("not-an-id", 0); err == nil {
+		t.Error("Expected error for invalid ID, got nil")
+	}
+}
+
+// TestTimeOf tests reading the generation time of an ID
+func TestTimeOf(t *testing.T) {
+	mockTime := time.Now().Add(-91 * 24 * time.Hour).UnixMilli()
+
+	// Test case 1: Default-format IDs
+	gen, _ := New(&Config{ShardID: 1})
+	gen.deps.nowFunc = func() int64 { return mockTime }
+	id := gen.Next()
+	if tm, err := TimeOf(id); err != nil || tm.UnixMilli() != mockTime {
+		t.Errorf("TimeOf(%q) = %v, %v, want %d", id, tm, err, mockTime)
+	}
+	if _, err := TimeOf("not-an-id"); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Expected ErrInvalidID, got %v", err)
+	}
+
+	// Test case 2: Generator methods use its epoch and format
+	customEpoch := time.Date(2022, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	gen, _ = New(&Config{ShardID: 1, CustomEpochMs: customEpoch, Encoding: EncodingCrockford})
+	gen.deps.nowFunc = func() int64 { return mockTime }
+	id = gen.Next()
+	if tm, err := gen.TimeOf(id); err != nil || tm.UnixMilli() != mockTime {
+		t.Errorf("TimeOf(%q) = %v, %v, want %d", id, tm, err, mockTime)
+	}
+	if age, err := gen.Age(id); err != nil || age < 90*24*time.Hour || age > 92*24*time.Hour {
+		t.Errorf("Expected an age of about 91 days, got %v, %v", age, err)
+	}
+	if _, err := gen.Age("not-an-id"); err == nil {
+		t.Error("Expected error for invalid ID, got nil")
+	}
+}
+
+// TestFixedWidth tests that small and large values encode to the same width
+func TestFixedWidth(t *testing.T) {
+	for _, l := range []Layout{DefaultLayout, NoShardLayout, {TimestampBits: 20, SequenceBits: 4}} {
+		n := l.chars()
+		maxVal := uint64(1)<<uint(l.Bits()) - 1
+		if l.Bits() == 64 {
+			maxVal = ^uint64(0)
+		}
+		for _, val := range []uint64{0, 1, maxVal} {
+			buf := make([]byte, n)
+			encodeTo(buf, val)
+			if val == 0 && string(buf) != strings.Repeat("A", n) {
+				t.Errorf("Expected zero to pad with 'A', got %q", buf)
+			}
+			got, err := decode(string(buf), l)
+			if err != nil {
+				t.Fatalf("decode(%q) failed: %v", buf, err)
+			}
+			if got != val {
+				t.Errorf("Round trip of %d in %+v gave %d", val, l, got)
+			}
+		}
+	}
+}
@@ -0,0 +1,164 @@
+package uniqid
+
+import (
+	"fmt"
+	"strings"
+)
+
+// NextDelimited generates a new ID like Next, with sep inserted every
+// groupSize characters for IDs that people read aloud or type, such as
+// support tickets and invoice numbers. The value is unchanged: Parse,
+// Generator.Parse and the other decoders strip the separators again.
+// A Prefix or VersionPrefix stays in front of the first group, and a
+// check character from Config.Checksum and a signature from
+// Config.SigningKey end the last.
+//
+// sep must be printable ASCII outside the generator's alphabet, so it
+// cannot be mistaken for an ID character. The default alphabet uses
+// '-' and '_', so pick e.g. '.' or ' ' with it, or '-' with an
+// alphabet that lacks it.
+//
+// Example:
+//
+//	id, err := gen.NextDelimited(4, '.') // "Ab3X.yz0L.mN_"
+func (g *Generator) NextDelimited(groupSize int, sep byte) (string, error) {
+	if err := g.checkGrouping(groupSize, sep); err != nil {
+		return "", err
+	}
+	val, _ := g.next(true)
+	var buf [24]byte
+	return g.group(g.appendID(buf[:0], val), groupSize, sep), nil
+}
+
+// FormatGrouped renders id, an ID in g's format in any form Normalize
+// accepts, with sep inserted every groupSize characters, as
+// NextDelimited would have generated it. It is meant for printing
+// references on receipts and emails, e.g. "AB3X-YZ0L-MN1Q-2" with
+// EncodingCrockford. groupSize and sep are restricted as for
+// NextDelimited.
+//
+// Example:
+//
+//	ref, err := gen.FormatGrouped(id, 4, '-')
+func (g *Generator) FormatGrouped(id string, groupSize int, sep byte) (string, error) {
+	if err := g.checkGrouping(groupSize, sep); err != nil {
+		return "", err
+	}
+	norm, err := g.Normalize(id)
+	if err != nil {
+		return "", err
+	}
+	return g.group([]byte(norm), groupSize, sep), nil
+}
+
+// Normalize returns the canonical form of s, an ID in g's format as a
+// person might type it: separators of any kind and in any places are
+// dropped, and with a case-insensitive encoding (EncodingCrockford)
+// letter case and look-alike characters are folded, as is the case of
+// a Prefix. Separators are ASCII spaces and punctuation outside the
+// alphabet. It returns ErrInvalidID, or ErrChecksumMismatch, if the
+// result is not a valid ID, so Normalize followed by Generator.Parse
+// accepts IDs however they were grouped.
+//
+// Example:
+//
+//	id, err := gen.Normalize("ab3x yz0l-mn1q 2") // "AB3XYZ0LMN1Q2"
+func (g *Generator) Normalize(s string) (string, error) {
+	foldCase := g.codec == crockfordCodec
+	if len(s) < len(g.prefix) || !(s[:len(g.prefix)] == g.prefix ||
+		foldCase && strings.EqualFold(s[:len(g.prefix)], g.prefix)) {
+		return "", ErrInvalidID
+	}
+	out := make([]byte, 0, g.Len())
+	out = append(out, g.prefix...)
+	for i := len(g.prefix); i < len(s); i++ {
+		c := s[i]
+		switch v := g.codec.table[c]; {
+		case v != 0xFF:
+			out = append(out, g.codec.alphabet[v])
+		case isSeparator(c):
+		default:
+			return "", ErrInvalidID
+		}
+	}
+	if _, err := g.Parse(string(out)); err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// checkGrouping validates the arguments of NextDelimited and
+// FormatGrouped.
+func (g *Generator) checkGrouping(groupSize int, sep byte) error {
+	if groupSize < 1 {
+		return fmt.Errorf("group size must be positive, got %d", groupSize)
+	}
+	if sep < ' ' || sep > '~' || g.codec.table[sep] != 0xFF {
+		return fmt.Errorf("separator %q must be printable ASCII outside the alphabet", sep)
+	}
+	return nil
+}
+
+// group inserts sep every groupSize characters into raw, an ID in g's
+// format, leaving its prefixes in front of the first group.
+func (g *Generator) group(raw []byte, groupSize int, sep byte) string {
+	prefix := len(raw) - g.Len() + len(g.prefix)
+	if g.version != 0 {
+		prefix++
+	}
+	out := make([]byte, 0, len(raw)+len(raw)/groupSize)
+	out = append(out, raw[:prefix]...)
+	for i, c := range raw[prefix:] {
+		if i > 0 && i%groupSize == 0 {
+			out = append(out, sep)
+		}
+		out = append(out, c)
+	}
+	return string(out)
+}
+
+// isSeparator reports whether c is an ASCII space or punctuation
+// character, which Normalize drops unless the alphabet uses it.
+func isSeparator(c byte) bool {
+	switch {
+	case c == ' ' || c == '\t':
+		return true
+	case c < '!' || c > '~':
+		return false
+	}
+	return !('0' <= c && c <= '9' || 'a' <= c && c <= 'z' || 'A' <= c && c <= 'Z')
+}
+
+// undelimit removes the separators NextDelimited inserted into id,
+// which should hold n alphabet characters. The groups must be evenly
+// sized, apart from a shorter last one, and split by one repeated byte
+// outside the alphabet. It returns false if id does not have that
+// shape.
+func (c *codec) undelimit(id string, n int) (string, bool) {
+	size := 0
+	for size < len(id) && c.table[id[size]] != 0xFF {
+		size++
+	}
+	if size == 0 || size >= n || size == len(id) {
+		return "", false
+	}
+	if len(id) != n+(n-1)/size {
+		return "", false
+	}
+	sep := id[size]
+	out := make([]byte, 0, n)
+	for i := 0; i < len(id); i++ {
+		if i%(size+1) == size {
+			if id[i] != sep {
+				return "", false
+			}
+			continue
+		}
+		out = append(out, id[i])
+	}
+	return string(out), true
+}
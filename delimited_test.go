@@ -0,0 +1,148 @@
+package uniqid
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestNextDelimited tests grouped IDs and parsing them back
+func TestNextDelimited(t *testing.T) {
+	gen, err := New(&Config{ShardID: 5})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	// Test case 1: Separators land every groupSize characters
+	for _, tc := range []struct {
+		size   int
+		sep    byte
+		groups []int
+	}{
+		{4, '.', []int{4, 4, 3}},
+		{3, ' ', []int{3, 3, 3, 2}},
+		{11, '.', []int{11}},
+	} {
+		id, err := gen.NextDelimited(tc.size, tc.sep)
+		if err != nil {
+			t.Fatalf("NextDelimited(%d) failed: %v", tc.size, err)
+		}
+		groups := strings.Split(id, string(tc.sep))
+		if len(groups) != len(tc.groups) {
+			t.Fatalf("NextDelimited(%d) = %q, expected %d groups", tc.size, id, len(tc.groups))
+		}
+		for i, g := range groups {
+			if len(g) != tc.groups[i] {
+				t.Errorf("NextDelimited(%d) = %q: group %d has %d characters, expected %d", tc.size, id, i, len(g), tc.groups[i])
+			}
+		}
+
+		// Test case 2: Parsing the delimited form gives the original value
+		plain := strings.Join(groups, "")
+		want, err := Parse(plain)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", plain, err)
+		}
+		got, err := Parse(id)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", id, err)
+		}
+		if got != want || got.Shard != 5 {
+			t.Errorf("Parse(%q) = %+v, expected %+v", id, got, want)
+		}
+		if _, err := gen.Parse(id); err != nil {
+			t.Errorf("Generator.Parse(%q) failed: %v", id, err)
+		}
+	}
+
+	// Test case 3: Malformed grouping is rejected
+	id, _ := gen.NextDelimited(4, '.')
+	for _, bad := range []string{
+		id[:4] + "." + id[4:],                              // doubled separator
+		strings.Replace(id, ".", " ", 1),                   // mixed separators
+		id[:3] + "." + strings.ReplaceAll(id[3:], ".", ""), // uneven groups
+		id + ".",
+	} {
+		if _, err := Parse(bad); err != ErrInvalidID {
+			t.Errorf("Parse(%q): expected ErrInvalidID, got %v", bad, err)
+		}
+	}
+
+	// Test case 4: Invalid arguments
+	if _, err := gen.NextDelimited(0, '.'); err == nil {
+		t.Error("Expected error for zero group size, got nil")
+	}
+	if _, err := gen.NextDelimited(4, '-'); err == nil {
+		t.Error("Expected error for separator in the alphabet, got nil")
+	}
+
+	// Test case 5: A version prefix stays in front of the first group
+	v, _ := New(&Config{ShardID: 5, VersionPrefix: 'v'})
+	id, err = v.NextDelimited(4, ' ')
+	if err != nil {
+		t.Fatalf("NextDelimited failed: %v", err)
+	}
+	if len(id) != 14 || id[0] != 'v' || id[5] != ' ' {
+		t.Errorf("Unexpected delimited ID with prefix: %q", id)
+	}
+	if _, err := v.Parse(id); err != nil {
+		t.Errorf("Parse(%q) failed: %v", id, err)
+	}
+}
+
+// TestFormatGrouped tests grouping existing IDs and normalizing typed ones
+func TestFormatGrouped(t *testing.T) {
+	gen, err := New(&Config{ShardID: 5, Encoding: EncodingCrockford, Prefix: "inv_", Checksum: true})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	id := gen.Next()
+
+	// Test case 1: FormatGrouped groups the ID after its prefix
+	ref, err := gen.FormatGrouped(id, 4, '-')
+	if err != nil {
+		t.Fatalf("FormatGrouped failed: %v", err)
+	}
+	if want := id[:8] + "-" + id[8:12] + "-" + id[12:16] + "-" + id[16:]; ref != want {
+		t.Errorf("FormatGrouped(%q) = %q, want %q", id, ref, want)
+	}
+
+	// Test case 2: Normalize accepts any grouping, case and look-alikes
+	body := id[4:]
+	for _, typed := range []string{
+		ref,
+		id,
+		"INV_" + strings.ToLower(body[:5]) + " " + body[5:9] + "/" + body[9:],
+		"inv_ " + strings.ReplaceAll(body, "1", "l") + " ",
+	} {
+		if got, err := gen.Normalize(typed); err != nil || got != id {
+			t.Errorf("Normalize(%q) = %q, %v, want %q", typed, got, err, id)
+		}
+	}
+	if again, _ := gen.FormatGrouped(strings.ToLower(ref), 4, '-'); again != ref {
+		t.Errorf("FormatGrouped of the lower-case form = %q, want %q", again, ref)
+	}
+
+	// Test case 3: Typos, foreign characters and wrong prefixes are rejected
+	typo := []byte(id)
+	typo[6] = map[bool]byte{true: 'B', false: 'A'}[typo[6] == 'A']
+	for _, bad := range []string{string(typo), id + "U", "ord_" + body, id[:10]} {
+		if _, err := gen.Normalize(bad); err == nil {
+			t.Errorf("Normalize(%q): expected error, got nil", bad)
+		}
+	}
+
+	// Test case 4: Case stays significant with a case-sensitive alphabet
+	def, _ := New(&Config{ShardID: 5})
+	plain := def.Next()
+	if got, err := def.Normalize(plain[:4] + " " + plain[4:]); err != nil || got != plain {
+		t.Errorf("Normalize with a space = %q, %v, want %q", got, err, plain)
+	}
+	if upper := strings.ToUpper(plain); upper != plain {
+		if got, _ := def.Normalize(upper); got == plain {
+			t.Errorf("Normalize folded the case of %q", upper)
+		}
+	}
+	if _, err := def.FormatGrouped(plain, 4, '-'); err == nil {
+		t.Error("Expected error for a separator in the alphabet, got nil")
+	}
+}
@@ -0,0 +1,159 @@
+package uniqid
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Environment variables read by ConfigFromEnv, each setting the Config
+// field of the same name. Unset or empty variables keep the default.
+const (
+	EnvShardID          = "UNIQID_SHARD_ID"           // integer, or "auto" for -1 (the default)
+	EnvEpochMs          = "UNIQID_EPOCH_MS"           // CustomEpochMs, Unix milliseconds
+	EnvName             = "UNIQID_NAME"               // Name
+	EnvAlphabet         = "UNIQID_ALPHABET"           // Alphabet, 64 characters
+	EnvEncoding         = "UNIQID_ENCODING"           // "base64" or "crockford"
+	EnvPrefix           = "UNIQID_PREFIX"             // Prefix, e.g. "ord_"
+	EnvVersionPrefix    = "UNIQID_VERSION_PREFIX"     // one character
+	EnvLayout           = "UNIQID_LAYOUT"             // JSON, as in ExportJSON
+	EnvNoShard          = "UNIQID_NO_SHARD"           // boolean
+	EnvTimestampUnit    = "UNIQID_TIMESTAMP_UNIT"     // duration, e.g. "10ms"
+	EnvSalt             = "UNIQID_SALT"               // integer
+	EnvShardSource      = "UNIQID_SHARD_SOURCE"       // e.g. "hostname"; see ConfigFromEnv
+	EnvShardMetadataTag = "UNIQID_SHARD_METADATA_TAG" // tag name
+	EnvCachedClock      = "UNIQID_CACHED_CLOCK"       // boolean
+)
+
+// ConfigFromEnv builds a Config from UNIQID_* environment variables,
+// for twelve-factor deployments that configure the generator per
+// environment without code changes. Booleans are parsed with
+// strconv.ParseBool and durations with time.ParseDuration. Shard
+// sources are named "auto", "first-mac", "all-macs", "hostname",
+// "random", "kubernetes", "aws", "gcp" and "azure". Without
+// UNIQID_SHARD_ID the shard is auto-derived, as for New(nil).
+//
+// The error names the offending variable. The result is not validated;
+// New does that.
+func ConfigFromEnv() (*Config, error) {
+	cfg := &Config{ShardID: -1}
+	var err error
+	str := func(name string, dst *string) {
+		if v := os.Getenv(name); v != "" {
+			*dst = v
+		}
+	}
+	parse := func(name string, set func(string) error) {
+		if v := os.Getenv(name); v != "" && err == nil {
+			if e := set(v); e != nil {
+				err = fmt.Errorf("%s=%q: %w", name, v, e)
+			}
+		}
+	}
+	parseBool := func(name string, dst *bool) {
+		parse(name, func(v string) (e error) {
+			*dst, e = strconv.ParseBool(v)
+			return e
+		})
+	}
+
+	parse(EnvShardID, func(v string) (e error) {
+		if strings.EqualFold(v, "auto") {
+			return nil
+		}
+		cfg.ShardID, e = strconv.Atoi(v)
+		return e
+	})
+	parse(EnvEpochMs, func(v string) (e error) {
+		cfg.CustomEpochMs, e = strconv.ParseInt(v, 10, 64)
+		return e
+	})
+	str(EnvName, &cfg.Name)
+	str(EnvAlphabet, &cfg.Alphabet)
+	parse(EnvEncoding, func(v string) error {
+		switch strings.ToLower(v) {
+		case "base64":
+			cfg.Encoding = EncodingBase64
+		case "crockford":
+			cfg.Encoding = EncodingCrockford
+		default:
+			return errors.New("unknown encoding")
+		}
+		return nil
+	})
+	parse(EnvPrefix, func(v string) error {
+		cfg.Prefix = v
+		return nil
+	})
+	parse(EnvVersionPrefix, func(v string) error {
+		if len(v) != 1 {
+			return errors.New("must be a single character")
+		}
+		cfg.VersionPrefix = v[0]
+		return nil
+	})
+	parse(EnvLayout, func(v string) error {
+		return json.Unmarshal([]byte(v), &cfg.Layout)
+	})
+	parseBool(EnvNoShard, &cfg.NoShard)
+	parse(EnvTimestampUnit, func(v string) (e error) {
+		cfg.TimestampUnit, e = time.ParseDuration(v)
+		return e
+	})
+	parse(EnvSalt, func(v string) error {
+		n, e := strconv.ParseUint(v, 10, 16)
+		cfg.Salt = uint16(n)
+		return e
+	})
+	parse(EnvShardSource, func(v string) error {
+		for src, name := range shardSourceNames {
+			if strings.EqualFold(v, name) {
+				cfg.ShardSource = ShardSource(src)
+				return nil
+			}
+		}
+		return errors.New("unknown shard source")
+	})
+	str(EnvShardMetadataTag, &cfg.ShardMetadataTag)
+	parseBool(EnvCachedClock, &cfg.CachedClock)
+	if err != nil {
+		return nil, err
+	}
+	return cfg, nil
+}
+
+// NewFromEnv creates a generator configured by ConfigFromEnv.
+//
+// Example:
+//
+//	// UNIQID_SHARD_ID=7 UNIQID_EPOCH_MS=1704067200000 ./server
+//	gen, err := uniqid.NewFromEnv()
+func NewFromEnv() (*Generator, error) {
+	cfg, err := ConfigFromEnv()
+	if err != nil {
+		return nil, err
+	}
+	return New(cfg)
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// shardSourceNames are the ShardSource names accepted in
+// UNIQID_SHARD_SOURCE, indexed by value.
+var shardSourceNames = [...]string{
+	ShardSourceAuto:       "auto",
+	ShardSourceFirstMAC:   "first-mac",
+	ShardSourceAllMACs:    "all-macs",
+	ShardSourceHostname:   "hostname",
+	ShardSourceRandom:     "random",
+	ShardSourceKubernetes: "kubernetes",
+	ShardSourceAWS:        "aws",
+	ShardSourceGCP:        "gcp",
+	ShardSourceAzure:      "azure",
+}
@@ -0,0 +1,66 @@
+package uniqid
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestConfigFromEnv tests configuring a generator from UNIQID_* variables
+func TestConfigFromEnv(t *testing.T) {
+	// Test case 1: Without variables the shard is auto-derived
+	cfg, err := ConfigFromEnv()
+	if err != nil || cfg.ShardID != -1 {
+		t.Fatalf("Expected an auto shard, got %+v, %v", cfg, err)
+	}
+
+	// Test case 2: Every variable sets its field
+	t.Setenv(EnvShardID, "7")
+	t.Setenv(EnvEpochMs, "1704067200000")
+	t.Setenv(EnvName, "orders")
+	t.Setenv(EnvEncoding, "Crockford")
+	t.Setenv(EnvVersionPrefix, "V")
+	t.Setenv(EnvLayout, `{"timestampBits":40,"shardBits":8,"sequenceBits":12,"reservedBits":4}`)
+	t.Setenv(EnvTimestampUnit, "10ms")
+	t.Setenv(EnvSalt, "3")
+	t.Setenv(EnvShardSource, "kubernetes")
+	t.Setenv(EnvCachedClock, "true")
+	cfg, err = ConfigFromEnv()
+	if err != nil {
+		t.Fatalf("ConfigFromEnv failed: %v", err)
+	}
+	want := Config{
+		ShardID: 7, CustomEpochMs: 1704067200000, Name: "orders", Encoding: EncodingCrockford,
+		VersionPrefix: 'V', Layout: Layout{TimestampBits: 40, ShardBits: 8, SequenceBits: 12, ReservedBits: 4},
+		TimestampUnit: 10 * time.Millisecond, Salt: 3, ShardSource: ShardSourceKubernetes, CachedClock: true,
+	}
+	if !reflect.DeepEqual(*cfg, want) {
+		t.Errorf("Got %+v, want %+v", *cfg, want)
+	}
+	gen, err := NewFromEnv()
+	if err != nil {
+		t.Fatalf("NewFromEnv failed: %v", err)
+	}
+	if p, err := gen.Parse(gen.Next()); err != nil || p.Shard != 7 || p.Salt != 3 {
+		t.Errorf("Unexpected parts %+v, %v", p, err)
+	}
+
+	// Test case 3: "auto" selects the derived shard
+	t.Setenv(EnvShardID, "auto")
+	if cfg, _ := ConfigFromEnv(); cfg.ShardID != -1 {
+		t.Errorf("Expected shard -1 for auto, got %d", cfg.ShardID)
+	}
+
+	// Test case 4: Malformed values name the variable
+	for name, bad := range map[string]string{
+		EnvShardID: "seven", EnvEncoding: "hex", EnvVersionPrefix: "vv", EnvLayout: "{",
+		EnvNoShard: "maybe", EnvTimestampUnit: "10", EnvSalt: "-1", EnvShardSource: "dns",
+	} {
+		t.Setenv(name, bad)
+		if _, err := ConfigFromEnv(); err == nil || !strings.Contains(err.Error(), name) {
+			t.Errorf("Expected an error naming %s, got %v", name, err)
+		}
+		t.Setenv(name, "")
+	}
+}
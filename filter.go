@@ -0,0 +1,94 @@
+package uniqid
+
+import (
+	"errors"
+	"strings"
+)
+
+// ErrTooManyBanned is returned by NextE, TryNext, NextCtx and
+// NextTagged when Config.BannedSubstrings rejects a whole timestamp
+// tick's worth of IDs in a row, e.g. because an entry matches the
+// ID's timestamp characters, and by ReserveRange when it rejects an
+// ID of the range. No ID is issued, and the slots tried are used up.
+var ErrTooManyBanned = errors.New("too many consecutive IDs banned")
+
+// LookAlikeRuns lists pairs of characters of the default alphabet that
+// are easily confused in print, for use in Config.BannedSubstrings.
+// Matching ignores case, so "o0" also bans "O0", and "il" covers "Il"
+// and "iL".
+var LookAlikeRuns = []string{"o0", "0o", "l1", "1l", "i1", "1i", "il", "li"}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// reroll reports whether the layout's reserved bits are free for
+// re-rolling banned IDs: c has BannedSubstrings, and neither Salt nor
+// BurstOverflow uses the reserved bits.
+func (c *Config) reroll() bool {
+	return len(c.BannedSubstrings) > 0 && c.layout().ReservedBits > 0 && c.Salt == 0 && !c.BurstOverflow
+}
+
+// allowed returns val, or if its ID is banned the first variant of it
+// with re-rolled reserved bits whose ID is not. It reports false if
+// none is found. Like isBanned, it needs no lock.
+func (g *Generator) allowed(val uint64) (uint64, bool) {
+	if g.banned == nil || !g.isBanned(val) {
+		return val, true
+	}
+	if !g.reroll {
+		return 0, false
+	}
+	for r := uint64(1); r <= g.layout.MaxSalt(); r++ {
+		if !g.isBanned(val | r) {
+			return val | r, true
+		}
+	}
+	return 0, false
+}
+
+// isBanned reports whether the ID for val contains one of the banned
+// substrings after its prefixes. It only reads settings fixed by New.
+func (g *Generator) isBanned(val uint64) bool {
+	var buf [64]byte
+	id := g.appendID(buf[:0], val)
+	skip := len(g.prefix)
+	if g.version != 0 {
+		skip++
+	}
+	body := strings.ToLower(string(id[skip:]))
+	for _, b := range g.banned {
+		if strings.Contains(body, b) {
+			return true
+		}
+	}
+	return false
+}
+
+// bannedLimit returns how many banned slots in a row are skipped
+// before the generator gives up: one tick's worth, which tries every
+// sequence value, so the ban must match characters that only depend
+// on the timestamp, shard or other fixed fields.
+func (g *Generator) bannedLimit() int {
+	return g.layout.MaxSequence() + 1
+}
+
+// unclaim gives back the issue counter of a slot skipped by
+// nextTaggedLocked, so the next ID takes its counter value, and moves it from
+// Stats.Generated to Stats.Skipped. The caller must hold g.mu.
+func (g *Generator) unclaim() {
+	g.counter--
+	g.issued--
+	g.stats.Generated--
+	g.stats.Skipped++
+}
+
+// lowerAll returns the strings in s in lower case, or nil if s is
+// empty.
+func lowerAll(s []string) []string {
+	var out []string
+	for _, v := range s {
+		out = append(out, strings.ToLower(v))
+	}
+	return out
+}
@@ -0,0 +1,186 @@
+package uniqid
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestBannedSubstrings tests skipping IDs that contain banned substrings
+func TestBannedSubstrings(t *testing.T) {
+	const mockTime = 1_800_000_000_000
+	newGen := func(cfg *Config) *Generator {
+		t.Helper()
+		gen, err := New(cfg)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		gen.deps.nowFunc = func() int64 { return mockTime }
+		return gen
+	}
+	plain := newGen(&Config{ShardID: 1})
+	ids := plain.NextN(2000)
+
+	// Test case 1: Only slots whose IDs contain a banned run are skipped
+	const banned = "Ab"
+	gen := newGen(&Config{ShardID: 1, Prefix: "x_", BannedSubstrings: append([]string{banned}, LookAlikeRuns...)})
+	var want []string
+	for _, id := range ids {
+		lower := strings.ToLower(id)
+		ok := !strings.Contains(lower, strings.ToLower(banned))
+		for _, run := range LookAlikeRuns {
+			ok = ok && !strings.Contains(lower, run)
+		}
+		if ok {
+			want = append(want, id)
+		}
+	}
+	got := gen.NextN(len(want))
+	for i, id := range got {
+		if id != "x_"+want[i] {
+			t.Fatalf("ID %d = %q, want the slot of %q", i, id, want[i])
+		}
+	}
+	if s := gen.Stats(); s.Skipped != uint64(len(ids)-len(want)) || s.Generated != uint64(len(want)) {
+		t.Errorf("Unexpected stats %+v, expected %d skipped", s, len(ids)-len(want))
+	}
+
+	// Test case 2: Skipped slots do not leave gaps in the counter field
+	counted := newGen(&Config{
+		ShardID:          1,
+		Layout:           Layout{TimestampBits: 39, ShardBits: 4, SequenceBits: 15, CounterBits: 6},
+		BannedSubstrings: LookAlikeRuns,
+	})
+	for i, id := range counted.NextN(500) {
+		if p, _ := counted.Parse(id); p.Counter != uint64(i)%64 {
+			t.Fatalf("ID %d has counter %d", i, p.Counter)
+		}
+	}
+
+	// Test case 3: Short entries and LockFree are rejected
+	for _, bad := range []*Config{
+		{ShardID: 1, BannedSubstrings: []string{"a"}},
+		{ShardID: 1, BannedSubstrings: LookAlikeRuns, LockFree: true},
+	} {
+		if _, err := New(bad); err == nil {
+			t.Errorf("Expected error for %+v, got nil", bad)
+		}
+	}
+}
+
+// TestBannedSubstringsLimits tests that no issuing path returns a
+// banned ID, re-rolling reserved bits where possible
+func TestBannedSubstringsLimits(t *testing.T) {
+	const mockTime = 1_800_000_000_000
+	newGen := func(cfg *Config) *Generator {
+		t.Helper()
+		gen, err := New(cfg)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		gen.deps.nowFunc = func() int64 { return mockTime }
+		return gen
+	}
+	contains := func(id string, banned []string) bool {
+		for _, b := range banned {
+			if strings.Contains(strings.ToLower(id), b) {
+				return true
+			}
+		}
+		return false
+	}
+
+	// Test case 1: Free reserved bits are re-rolled instead of
+	// skipping the slot, and Parse ignores them
+	rerolled := &Config{ShardID: 3, Layout: Layout{TimestampBits: 39, ShardBits: 10, SequenceBits: 13, ReservedBits: 2}}
+	first := newGen(rerolled).Next()
+	banned := *rerolled
+	banned.BannedSubstrings = []string{strings.ToLower(first[len(first)-3:])}
+	gen := newGen(&banned)
+	id := gen.Next()
+	if p, err := gen.Parse(id); err != nil || id == first || p.Seq != 0 || p.Shard != 3 {
+		t.Errorf("Expected %q re-rolled in slot 0, got %q: %+v, %v", first, id, p, err)
+	}
+	if p, err := ParseWith(id, &banned); err != nil || p.Seq != 0 {
+		t.Errorf("ParseWith(%q) = %+v, %v", id, p, err)
+	}
+	if s := gen.Stats(); s.Skipped != 0 || s.Generated != 1 {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+
+	// Test case 2: ReserveRange re-rolls its IDs, or gives the range up
+	block, err := newGen(&banned).ReserveRange(100)
+	if err != nil {
+		t.Fatalf("ReserveRange failed: %v", err)
+	}
+	for i, id := range block.All() {
+		if p, err := gen.Parse(id); err != nil || int(p.Seq) != i || contains(id, banned.BannedSubstrings) {
+			t.Fatalf("Block ID %d %q: %+v, %v", i, id, p, err)
+		}
+	}
+	fifth := newGen(&Config{ShardID: 3}).NextN(5)[4]
+	gen = newGen(&Config{ShardID: 3, BannedSubstrings: []string{fifth}, TrackHistogram: true})
+	if _, err := gen.ReserveRange(10); err != ErrTooManyBanned {
+		t.Errorf("Expected ErrTooManyBanned, got %v", err)
+	}
+	if s, h := gen.Stats(), gen.Histogram(); s.Skipped != 10 || s.Generated != 0 || len(h) != 0 {
+		t.Errorf("Unexpected stats %+v and histogram %v", s, h)
+	}
+	if p, _ := gen.Parse(gen.Next()); p.Seq != 10 || p.Counter != 0 {
+		t.Errorf("Expected the next ID in slot 10, got %+v", p)
+	}
+
+	// Test case 3: Tagged and keyed IDs are filtered
+	tagged := &Config{NoShard: true, Layout: Layout{TimestampBits: 39, SequenceBits: 12, TagBits: 13}}
+	plain := newGen(tagged)
+	var ids []string
+	for i := 0; i < 20; i++ {
+		id, _ := plain.NextTagged(0x1BCD)
+		ids = append(ids, id)
+	}
+	banned = *tagged
+	banned.BannedSubstrings = []string{strings.ToLower(ids[0][len(ids[0])-3:])}
+	gen = newGen(&banned)
+	for i := 0; i < 10; i++ {
+		if id, _ := gen.NextTagged(0x1BCD); contains(id, banned.BannedSubstrings) {
+			t.Fatalf("Tagged ID %q contains a banned substring", id)
+		}
+	}
+	gen = newGen(&Config{ShardID: 1, BannedSubstrings: LookAlikeRuns})
+	for i := 0; i < 2000; i++ {
+		if id := gen.NextForKey([]byte{byte(i)}); contains(id, LookAlikeRuns) {
+			t.Fatalf("Keyed ID %q contains a look-alike run", id)
+		}
+	}
+
+	// Test case 4: A ban matching a whole tick fails NextE and TryNext,
+	// and Next waits for the next tick
+	tick := strings.ToLower(newGen(&Config{ShardID: 1}).Next()[4:7])
+	limit := uint64(DefaultLayout.MaxSequence() + 1)
+	cfg := &Config{ShardID: 1, BannedSubstrings: []string{tick}, TrackHistogram: true}
+	if _, err := newGen(cfg).NextE(); err != ErrTooManyBanned {
+		t.Errorf("Expected ErrTooManyBanned, got %v", err)
+	}
+	if newGen(cfg).Peek() != "" {
+		t.Error("Expected Peek to report no ID for the banned tick")
+	}
+	gen = newGen(cfg)
+	if _, err := gen.TryNext(); err != ErrTooManyBanned {
+		t.Errorf("Expected ErrTooManyBanned from TryNext, got %v", err)
+	}
+	gen.deps.nowFunc = func() int64 {
+		if gen.stats.Skipped >= limit {
+			return mockTime + 1
+		}
+		return mockTime
+	}
+	id = gen.Next()
+	if p, _ := gen.Parse(id); contains(id, []string{tick}) || p.Time.UnixMilli() != mockTime+1 {
+		t.Errorf("Expected an allowed ID in the next tick, got %q at %v", id, p.Time)
+	}
+	if s := gen.Stats(); s.Skipped != limit || s.Generated != 1 {
+		t.Errorf("Unexpected stats %+v", s)
+	}
+	if h := gen.Histogram(); h[1] != 1 || len(h) != 1 {
+		t.Errorf("Expected only issued IDs in the histogram, got %v", h)
+	}
+}
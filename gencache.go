@@ -0,0 +1,154 @@
+package uniqid
+
+import (
+	"container/list"
+	"crypto/sha256"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+)
+
+const (
+	// genCacheCapacity is how many distinct configs Gen keeps a
+	// generator for.
+	genCacheCapacity = 64
+	// genCacheThrashLimit is how many evictions per genCacheWindow Gen
+	// tolerates before refusing new configs.
+	genCacheThrashLimit = genCacheCapacity
+	genCacheWindow      = time.Second
+)
+
+// ErrGenCacheThrash is returned by Gen when it is called with so many
+// distinct configs that its generator cache keeps evicting entries.
+// Create generators with New and reuse them instead.
+var ErrGenCacheThrash = errors.New("too many distinct configs passed to Gen; create generators with New")
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// genCache is a bounded LRU of generators keyed by config, so repeated
+// Gen calls with the same config share one generator (and its
+// sequence) instead of risking duplicate IDs.
+type genCache struct {
+	mu          sync.Mutex
+	order       *list.List
+	entries     map[string]*list.Element
+	windowStart time.Time
+	evictions   int
+}
+
+// genCacheEntry is the value stored in the LRU list. ready is closed
+// once gen and err are set.
+type genCacheEntry struct {
+	key   string
+	gen   *Generator
+	err   error
+	ready chan struct{}
+}
+
+// genConfigs is the cache used by Gen.
+var genConfigs = newGenCache()
+
+func newGenCache() *genCache {
+	return &genCache{
+		order:   list.New(),
+		entries: make(map[string]*list.Element, genCacheCapacity),
+	}
+}
+
+// get returns the cached generator for cfg, creating it with newFunc on
+// a miss. A miss that would evict more than genCacheThrashLimit entries
+// within genCacheWindow fails with ErrGenCacheThrash.
+//
+// The generator is created without holding c.mu, since New may wait
+// for a cloud metadata service; concurrent calls for the same config
+// wait for that one creation instead.
+func (c *genCache) get(cfg *Config) (*Generator, error) {
+	key := genCacheKey(cfg)
+	c.mu.Lock()
+	if el, ok := c.entries[key]; ok {
+		c.order.MoveToFront(el)
+		c.mu.Unlock()
+		e := el.Value.(*genCacheEntry)
+		<-e.ready
+		return e.gen, e.err
+	}
+	if c.order.Len() >= genCacheCapacity {
+		if now := time.Now(); now.Sub(c.windowStart) > genCacheWindow {
+			c.windowStart, c.evictions = now, 0
+		}
+		if c.evictions >= genCacheThrashLimit {
+			c.mu.Unlock()
+			return nil, ErrGenCacheThrash
+		}
+	}
+	e := &genCacheEntry{key: key, ready: make(chan struct{})}
+	el := c.order.PushFront(e)
+	c.entries[key] = el
+	c.mu.Unlock()
+
+	e.gen, e.err = newFunc(cfg)
+
+	c.mu.Lock()
+	close(e.ready)
+	var evicted []*Generator
+	if e.err != nil {
+		c.order.Remove(el)
+		delete(c.entries, key)
+	}
+	for old := c.order.Back(); old != nil && c.order.Len() > genCacheCapacity; {
+		prev := old.Prev()
+		if oe := old.Value.(*genCacheEntry); oe.done() {
+			c.evictions++
+			c.order.Remove(old)
+			delete(c.entries, oe.key)
+			if oe.gen != nil {
+				evicted = append(evicted, oe.gen)
+			}
+		}
+		old = prev
+	}
+	c.mu.Unlock()
+	for _, g := range evicted {
+		g.Close()
+	}
+	return e.gen, e.err
+}
+
+// done reports whether e's generator has been created.
+func (e *genCacheEntry) done() bool {
+	select {
+	case <-e.ready:
+		return true
+	default:
+		return false
+	}
+}
+
+// genCacheKey returns the cache key for cfg: its value fields, with
+// the key material hashed rather than held in the map. Function and
+// interface fields (RandReader, Clock, StateStore, ShardProvider and
+// the hooks) are left out, as only their addresses would differ: Gen
+// uses those of the first call with an otherwise equal config.
+func genCacheKey(cfg *Config) string {
+	c := *cfg
+	c.RandReader, c.Clock, c.StateStore, c.ShardProvider = nil, nil, nil, nil
+	c.OnShardChange, c.OnClockDrift = nil, nil
+	h := sha256.New()
+	for _, k := range [][]byte{c.TokenKey, c.SigningKey, c.ObfuscationKey} {
+		_ = binary.Write(h, binary.BigEndian, uint32(len(k)))
+		h.Write(k)
+	}
+	c.TokenKey, c.SigningKey, c.ObfuscationKey = nil, nil, nil
+	return fmt.Sprintf("%#v/%x", c, h.Sum(nil))
+}
+
+// len returns the number of cached generators.
+func (c *genCache) len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.order.Len()
+}
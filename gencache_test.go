@@ -0,0 +1,125 @@
+package uniqid
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestGenConfigCache tests that Gen reuses generators per config within a bounded cache
+func TestGenConfigCache(t *testing.T) {
+	original := genConfigs
+	genConfigs = newGenCache()
+	defer func() { genConfigs = original }()
+
+	// Test case 1: Repeated configs share a generator, so IDs never repeat
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id, err := Gen(&Config{ShardID: 5})
+		if err != nil {
+			t.Fatalf("Gen failed: %v", err)
+		}
+		if seen[id] {
+			t.Fatalf("Duplicate ID %q from a repeated config", id)
+		}
+		seen[id] = true
+	}
+	if n := genConfigs.len(); n != 1 {
+		t.Errorf("Expected 1 cached generator, got %d", n)
+	}
+
+	// Test case 2: Cycling through many configs keeps the cache bounded
+	for i := 0; i < genCacheCapacity+genCacheThrashLimit; i++ {
+		if _, err := Gen(&Config{ShardID: i}); err != nil {
+			t.Fatalf("Gen(ShardID %d) failed: %v", i, err)
+		}
+		if n := genConfigs.len(); n > genCacheCapacity {
+			t.Fatalf("Cache grew to %d entries, capacity %d", n, genCacheCapacity)
+		}
+	}
+
+	// Test case 3: Cached configs are still served while thrashing
+	last := &Config{ShardID: genCacheCapacity + genCacheThrashLimit - 1}
+	if _, err := Gen(last); err != nil {
+		t.Errorf("Expected cached config to be served, got %v", err)
+	}
+
+	// Test case 4: Further new configs within the window are refused
+	if _, err := Gen(&Config{ShardID: 1000}); !errors.Is(err, ErrGenCacheThrash) {
+		t.Errorf("Expected ErrGenCacheThrash, got %v", err)
+	}
+
+	// Test case 5: Invalid configs are not cached
+	genConfigs = newGenCache()
+	if _, err := Gen(&Config{ShardID: 9999}); err == nil {
+		t.Error("Expected error for invalid config, got nil")
+	}
+	if n := genConfigs.len(); n != 0 {
+		t.Errorf("Expected invalid config not to be cached, got %d entries", n)
+	}
+}
+
+// TestGenConfigCacheKey tests which config fields key the cache, and
+// that creating a generator does not block other configs
+func TestGenConfigCacheKey(t *testing.T) {
+	original, originalNew := genConfigs, newFunc
+	genConfigs = newGenCache()
+	defer func() { genConfigs, newFunc = original, originalNew }()
+
+	// Test case 1: Fresh hooks and clocks do not defeat the cache
+	for i := 0; i < 10; i++ {
+		cfg := &Config{ShardID: 5, OnClockDrift: func(time.Duration) {}, Clock: NewManualClock(time.Now())}
+		if _, err := Gen(cfg); err != nil {
+			t.Fatalf("Gen failed: %v", err)
+		}
+	}
+	if n := genConfigs.len(); n != 1 {
+		t.Errorf("Expected 1 cached generator, got %d", n)
+	}
+
+	// Test case 2: Key material is hashed, and distinct keys differ
+	secret := []byte("very-secret-signing-key")
+	key := genCacheKey(&Config{SigningKey: secret})
+	if strings.Contains(key, string(secret)) || strings.Contains(key, fmt.Sprint(secret)) {
+		t.Errorf("Cache key exposes the signing key: %s", key)
+	}
+	if key == genCacheKey(&Config{SigningKey: []byte("another-signing-key-value")}) || key == genCacheKey(&Config{ObfuscationKey: secret}) {
+		t.Error("Expected different keys to give different cache keys")
+	}
+
+	// Test case 3: A slow New does not hold up cached configs, and
+	// concurrent callers for the slow config share its generator
+	release := make(chan struct{})
+	newFunc = func(cfg *Config) (*Generator, error) {
+		if cfg.ShardID == 7 {
+			<-release
+		}
+		return originalNew(cfg)
+	}
+	results := make(chan *Generator, 2)
+	for i := 0; i < 2; i++ {
+		go func() {
+			g, _ := genConfigs.get(&Config{ShardID: 7})
+			results <- g
+		}()
+	}
+	done := make(chan error)
+	go func() {
+		_, err := Gen(&Config{ShardID: 5})
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if err != nil {
+			t.Errorf("Gen failed: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Gen for a cached config blocked on another config's New")
+	}
+	close(release)
+	if a, b := <-results, <-results; a == nil || a != b {
+		t.Errorf("Expected concurrent callers to share one generator, got %p and %p", a, b)
+	}
+}
@@ -0,0 +1,88 @@
+package uniqid
+
+import "sort"
+
+// Histogram returns the distribution of IDs generated per active
+// millisecond (per timestamp tick with a coarser TimestampUnit): each
+// key is a number of IDs, and its value is how many milliseconds
+// produced exactly that many. The millisecond in progress is included.
+// It returns nil unless Config.TrackHistogram is set.
+//
+// Only the generator's own sequence is counted, not IDs for other
+// shards made by NextForKey.
+//
+// Example:
+//
+//	for perMs, n := range gen.Histogram() {
+//	    fmt.Printf("%d IDs/ms: %d times\n", perMs, n)
+//	}
+func (g *Generator) Histogram() map[int]int {
+	if g.hist == nil {
+		return nil
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	out := make(map[int]int, len(g.hist.buckets)+1)
+	for k, v := range g.hist.buckets {
+		out[k] = v
+	}
+	if g.hist.cur > 0 {
+		out[g.hist.cur]++
+	}
+	return out
+}
+
+// P99PerMs returns the 99th percentile of IDs generated per active
+// millisecond, a quick check of how close a node runs to the layout's
+// per-millisecond ceiling (Layout.MaxSequence + 1). It returns 0 if
+// nothing was generated or Config.TrackHistogram is not set.
+func (g *Generator) P99PerMs() int {
+	h := g.Histogram()
+	total := 0
+	keys := make([]int, 0, len(h))
+	for k, v := range h {
+		keys = append(keys, k)
+		total += v
+	}
+	sort.Ints(keys)
+	// Smallest bucket covering at least 99% of active milliseconds.
+	need := (total*99 + 99) / 100
+	seen := 0
+	for _, k := range keys {
+		seen += h[k]
+		if seen >= need {
+			return k
+		}
+	}
+	return 0
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// histogram counts IDs per timestamp tick. It is guarded by the
+// generator's lock.
+type histogram struct {
+	buckets map[int]int
+	ms      int64
+	cur     int
+}
+
+// newHistogram returns a histogram, or nil if tracking is disabled.
+func newHistogram(enabled bool) *histogram {
+	if !enabled {
+		return nil
+	}
+	return &histogram{buckets: make(map[int]int)}
+}
+
+// record counts one ID generated in tick ms.
+func (h *histogram) record(ms int64) {
+	if ms != h.ms && h.cur > 0 {
+		h.buckets[h.cur]++
+		h.cur = 0
+	}
+	h.ms = ms
+	h.cur++
+}
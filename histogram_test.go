@@ -0,0 +1,63 @@
+package uniqid
+
+import (
+	"reflect"
+	"testing"
+	"time"
+)
+
+// TestHistogram tests tracking IDs generated per millisecond
+func TestHistogram(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+
+	// Test case 1: Disabled by default
+	gen, _ := New(&Config{ShardID: 1})
+	gen.Next()
+	if h := gen.Histogram(); h != nil {
+		t.Errorf("Expected nil histogram when disabled, got %v", h)
+	}
+	if p := gen.P99PerMs(); p != 0 {
+		t.Errorf("Expected P99PerMs 0 when disabled, got %d", p)
+	}
+
+	// Test case 2: A burst shows up in its own bucket
+	gen, _ = New(&Config{ShardID: 1, TrackHistogram: true})
+	gen.deps.nowFunc = func() int64 { return mockTime }
+	if p := gen.P99PerMs(); p != 0 {
+		t.Errorf("Expected P99PerMs 0 before generating, got %d", p)
+	}
+	for ms := 0; ms < 200; ms++ {
+		n := 2
+		if ms == 100 {
+			n = 500
+		}
+		for i := 0; i < n; i++ {
+			gen.Next()
+		}
+		mockTime++
+	}
+	want := map[int]int{2: 199, 500: 1}
+	if h := gen.Histogram(); !reflect.DeepEqual(h, want) {
+		t.Errorf("Expected histogram %v, got %v", want, h)
+	}
+	if p := gen.P99PerMs(); p != 2 {
+		t.Errorf("Expected P99PerMs 2, got %d", p)
+	}
+
+	// Test case 3: Sustained bursts raise the 99th percentile
+	for ms := 0; ms < 5; ms++ {
+		for i := 0; i < 1000; i++ {
+			gen.Next()
+		}
+		mockTime++
+	}
+	if p := gen.P99PerMs(); p != 1000 {
+		t.Errorf("Expected P99PerMs 1000, got %d", p)
+	}
+
+	// Test case 4: The returned map is a copy
+	gen.Histogram()[2] = 0
+	if gen.Histogram()[2] != 199 {
+		t.Error("Histogram should return a copy")
+	}
+}
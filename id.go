@@ -0,0 +1,177 @@
+package uniqid
+
+import (
+	"cmp"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"strconv"
+	"time"
+)
+
+// ID is a default-format ID held as its packed 64-bit value: the
+// DefaultLayout, the default epoch and the default alphabet, as read
+// by Parse. Unlike a bare string it keeps the ID's structure at hand
+// and cannot be mixed up with identifiers from other systems. IDs
+// order like their strings and values. The zero ID would be
+// timestamped at the epoch itself, so it can serve as "no ID".
+//
+// Example:
+//
+//	id, err := gen.NextID()
+//	fmt.Println(id, id.Time(), id.Shard())
+type ID uint64
+
+// ErrNotDefaultFormat is returned by NextID when the generator's IDs
+// do not use the default format that ID's methods assume.
+var ErrNotDefaultFormat = errors.New("generator does not use the default ID format")
+
+// ParseID decodes a default-format ID string, as Parse does, into an
+// ID.
+func ParseID(s string) (ID, error) {
+	val, err := decode(s, DefaultLayout)
+	if err != nil {
+		return 0, err
+	}
+	return ID(val), nil
+}
+
+// maxFutureSkew is how far ahead of the local clock IsValid accepts a
+// timestamp, allowing for clock skew between nodes.
+const maxFutureSkew = time.Minute
+
+// IsValid reports whether s is a plausible default-format ID: 11
+// characters of the default alphabet whose value fits DefaultLayout,
+// with a timestamp no more than a minute ahead of the local clock. It
+// is a cheap check for rejecting junk input, e.g. in an HTTP handler
+// before a database lookup; a valid ID may still not exist.
+//
+// Example:
+//
+//	if !uniqid.IsValid(r.PathValue("id")) {
+//	    http.NotFound(w, r)
+//	    return
+//	}
+func IsValid(s string) bool {
+	id, err := ParseID(s)
+	return err == nil && id.Time().Before(time.Now().Add(maxFutureSkew))
+}
+
+// MustParse is like ParseID but panics if s is not a well-formed ID.
+// It simplifies initializing variables and tests with known IDs.
+func MustParse(s string) ID {
+	id, err := ParseID(s)
+	if err != nil {
+		panic(`uniqid: MustParse(` + strconv.Quote(s) + `): ` + err.Error())
+	}
+	return id
+}
+
+// NextID generates a new ID as an ID. It is meant for generators
+// producing default-format IDs and returns ErrNotDefaultFormat,
+// without generating, if g's layout, epoch, timestamp unit, encoding,
+// prefixes, checksum, signature, obfuscation, salt or burst overflow
+// differ from the defaults, since ID's methods would misread them;
+// use Next and Generator.Parse for those.
+func (g *Generator) NextID() (ID, error) {
+	if g.layout != DefaultLayout || g.baseEpoch != defaultEpochMs || g.unit != 1 ||
+		g.codec != defaultCodec || g.prefix != "" || g.checksum || len(g.signKey) > 0 ||
+		len(g.obfKey) > 0 || g.version != 0 || g.salt != 0 || g.overflow {
+		return 0, ErrNotDefaultFormat
+	}
+	val, _ := g.next(true)
+	return ID(val), nil
+}
+
+// String returns the 11-character encoding of id, as Next returns it.
+func (id ID) String() string {
+	var out [11]byte
+	defaultCodec.encode(out[:], uint64(id))
+	return string(out[:])
+}
+
+// Time returns the generation time of id, millisecond precision.
+func (id ID) Time() time.Time {
+	return id.parts().Time
+}
+
+// Shard returns the shard ID of the generator that produced id.
+func (id ID) Shard() uint16 {
+	return id.parts().Shard
+}
+
+// Seq returns the sequence number of id within its millisecond.
+func (id ID) Seq() uint16 {
+	return id.parts().Seq
+}
+
+// Compare returns -1, 0 or +1 as id was generated before, is equal to,
+// or was generated after other.
+func (id ID) Compare(other ID) int {
+	return cmp.Compare(id, other)
+}
+
+// IsZero reports whether id is the zero ID.
+func (id ID) IsZero() bool {
+	return id == 0
+}
+
+// MarshalText implements encoding.TextMarshaler with the string form.
+func (id ID) MarshalText() ([]byte, error) {
+	return []byte(id.String()), nil
+}
+
+// UnmarshalText implements encoding.TextUnmarshaler, accepting only a
+// well-formed string form and returning ErrInvalidID otherwise.
+func (id *ID) UnmarshalText(b []byte) error {
+	return id.scanString(string(b))
+}
+
+// MarshalJSON implements json.Marshaler, writing the string form as a
+// JSON string.
+func (id ID) MarshalJSON() ([]byte, error) {
+	return json.Marshal(id.String())
+}
+
+// UnmarshalJSON implements json.Unmarshaler. It accepts a JSON string
+// holding a well-formed ID and returns ErrInvalidID for any other
+// string; null leaves id unchanged, as for the standard types.
+//
+// Example:
+//
+//	var req struct{ OrderID uniqid.ID `json:"orderId"` }
+//	if err := json.Unmarshal(body, &req); errors.Is(err, uniqid.ErrInvalidID) {
+//	    http.Error(w, "bad orderId", http.StatusBadRequest)
+//	}
+func (id *ID) UnmarshalJSON(b []byte) error {
+	if string(b) == "null" {
+		return nil
+	}
+	var s string
+	if err := json.Unmarshal(b, &s); err != nil {
+		return err
+	}
+	return id.UnmarshalText([]byte(s))
+}
+
+// MarshalBinary implements encoding.BinaryMarshaler with the packed
+// value in 8 big-endian bytes, as NextBinary writes by default.
+func (id ID) MarshalBinary() ([]byte, error) {
+	return binary.BigEndian.AppendUint64(nil, uint64(id)), nil
+}
+
+// UnmarshalBinary implements encoding.BinaryUnmarshaler, returning
+// ErrInvalidID unless b is exactly 8 bytes.
+func (id *ID) UnmarshalBinary(b []byte) error {
+	if len(b) != 8 {
+		return ErrInvalidID
+	}
+	*id = ID(binary.BigEndian.Uint64(b))
+	return nil
+}
+
+// parts splits id into its components.
+// Not exported.
+func (id ID) parts() Parts {
+	return DefaultLayout.parts(uint64(id), defaultEpochMs, 1)
+}
@@ -0,0 +1,157 @@
+package uniqid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestID tests the typed ID and its accessors
+func TestID(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	gen, err := New(&Config{ShardID: 42})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	// Test case 1: Accessors match Parse of the string form
+	first, _ := gen.NextID()
+	id, err := gen.NextID()
+	if err != nil {
+		t.Fatalf("NextID failed: %v", err)
+	}
+	p, err := Parse(id.String())
+	if err != nil {
+		t.Fatalf("Parse(%q) failed: %v", id, err)
+	}
+	if !id.Time().Equal(p.Time) || id.Time().UnixMilli() != mockTime {
+		t.Errorf("Expected time %d, got %v", mockTime, id.Time())
+	}
+	if id.Shard() != 42 || id.Seq() != 1 {
+		t.Errorf("Expected shard 42 seq 1, got %d %d", id.Shard(), id.Seq())
+	}
+
+	// Test case 2: ParseID round-trips and rejects bad input
+	back, err := ParseID(id.String())
+	if err != nil || back != id {
+		t.Errorf("ParseID(%q) = %v, %v; want %v", id, back, err, id)
+	}
+	if _, err := ParseID("not-an-id"); err != ErrInvalidID {
+		t.Errorf("Expected ErrInvalidID, got %v", err)
+	}
+
+	// Test case 3: Compare follows generation order and IsZero the zero value
+	if first.Compare(id) != -1 || id.Compare(first) != 1 || id.Compare(id) != 0 {
+		t.Error("Compare does not follow generation order")
+	}
+	var zero ID
+	if !zero.IsZero() || id.IsZero() {
+		t.Error("IsZero misreports")
+	}
+
+	// Test case 4: Non-default formats are refused without using a slot
+	for _, cfg := range []*Config{
+		{ShardID: 1, CustomEpochMs: 1700000000000},
+		{ShardID: 1, Prefix: "ord_"},
+		{ShardID: 1, Encoding: EncodingCrockford},
+	} {
+		custom, _ := New(cfg)
+		if _, err := custom.NextID(); err != ErrNotDefaultFormat {
+			t.Errorf("Expected ErrNotDefaultFormat for %+v, got %v", cfg, err)
+		}
+		if s := custom.Stats(); s.Generated != 0 {
+			t.Errorf("Expected no ID generated, got %d", s.Generated)
+		}
+	}
+}
+
+// TestIDMarshaling tests the text, JSON and binary forms of ID
+func TestIDMarshaling(t *testing.T) {
+	gen, _ := New(&Config{ShardID: 3})
+	id, _ := gen.NextID()
+
+	// Test case 1: JSON round-trips as a string, also inside structs
+	type event struct {
+		ID ID `json:"id"`
+	}
+	b, err := json.Marshal(event{ID: id})
+	if err != nil || string(b) != `{"id":"`+id.String()+`"}` {
+		t.Fatalf("Marshal = %s, %v", b, err)
+	}
+	var ev event
+	if err := json.Unmarshal(b, &ev); err != nil || ev.ID != id {
+		t.Errorf("Unmarshal = %v, %v; want %v", ev.ID, err, id)
+	}
+	if err := json.Unmarshal([]byte(`{"id":null}`), &ev); err != nil || ev.ID != id {
+		t.Errorf("Expected null to leave the ID unchanged, got %v, %v", ev.ID, err)
+	}
+
+	// Test case 2: Malformed values are rejected on unmarshal
+	for _, in := range []string{`{"id":"too-short"}`, `{"id":"!!!!!!!!!!!"}`, `{"id":42}`} {
+		if err := json.Unmarshal([]byte(in), &ev); err == nil {
+			t.Errorf("Expected error for %s, got nil", in)
+		}
+	}
+	if err := json.Unmarshal([]byte(`{"id":"bad"}`), &ev); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Expected ErrInvalidID, got %v", err)
+	}
+
+	// Test case 3: Text round-trips
+	text, _ := id.MarshalText()
+	var back ID
+	if err := back.UnmarshalText(text); err != nil || back != id {
+		t.Errorf("UnmarshalText = %v, %v; want %v", back, err, id)
+	}
+
+	// Test case 4: Binary is the big-endian value and checks its length
+	bin, _ := id.MarshalBinary()
+	if want := binary.BigEndian.AppendUint64(nil, uint64(id)); !bytes.Equal(bin, want) {
+		t.Errorf("MarshalBinary = %x, want %x", bin, want)
+	}
+	back = 0
+	if err := back.UnmarshalBinary(bin); err != nil || back != id {
+		t.Errorf("UnmarshalBinary = %v, %v; want %v", back, err, id)
+	}
+	if err := back.UnmarshalBinary(bin[:7]); err != ErrInvalidID {
+		t.Errorf("Expected ErrInvalidID for 7 bytes, got %v", err)
+	}
+}
+
+// TestIsValid tests cheap validation of untrusted ID strings
+func TestIsValid(t *testing.T) {
+	gen, _ := New(&Config{ShardID: 5})
+	id := gen.Next()
+
+	// Test case 1: Generated IDs are valid
+	if !IsValid(id) {
+		t.Errorf("Expected %q to be valid", id)
+	}
+
+	// Test case 2: Wrong length, foreign characters and overflowing values are not
+	for _, s := range []string{"", id[:10], id + "A", "ABCDEFGHIJ!", "zzzzzzzzzzz"} {
+		if IsValid(s) {
+			t.Errorf("Expected %q to be invalid", s)
+		}
+	}
+
+	// Test case 3: Timestamps beyond the skew allowance are not
+	future := gen.format(DefaultLayout.pack(time.Now().Add(time.Hour).UnixMilli()-defaultEpochMs, 5, 0, 0))
+	if IsValid(future) {
+		t.Errorf("Expected future ID %q to be invalid", future)
+	}
+
+	// Test case 4: MustParse returns the ID or panics
+	if MustParse(id).String() != id {
+		t.Errorf("MustParse(%q) did not round-trip", id)
+	}
+	defer func() {
+		if recover() == nil {
+			t.Error("Expected MustParse to panic on junk")
+		}
+	}()
+	MustParse("junk")
+}
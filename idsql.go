@@ -0,0 +1,91 @@
+package uniqid
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"strconv"
+)
+
+// BigIntID is an ID stored in a BIGINT column: its Value writes the
+// packed value as an int64, while ID's writes the 11-character string
+// form for CHAR(11) columns with a binary collation. Choosing the
+// type per column, rather than a global setting, keeps packages that
+// share a process from overriding each other's choice. Both Scan
+// either form.
+//
+// The 64-bit DefaultLayout fills the sign bit from September 2028,
+// after which Value fails with ErrInt64Overflow rather than write a
+// negative value that would no longer sort in generation order, as
+// NextInt64 does.
+//
+// Example:
+//
+//	_, err := db.Exec("INSERT INTO orders (id) VALUES (?)", uniqid.BigIntID(id))
+//	err = row.Scan((*uniqid.BigIntID)(&id))
+type BigIntID ID
+
+// Value implements driver.Valuer, writing the string form of id. The
+// zero ID is written as NULL.
+func (id ID) Value() (driver.Value, error) {
+	if id.IsZero() {
+		return nil, nil
+	}
+	return id.String(), nil
+}
+
+// Scan implements sql.Scanner. It accepts the string form as a string
+// or []byte, the packed value as an int64 or, as some drivers return
+// BIGINT columns, as decimal text, and NULL as the zero ID. Text of 11
+// characters is always read as the string form. Malformed strings
+// return ErrInvalidID.
+func (id *ID) Scan(src any) error {
+	switch v := src.(type) {
+	case nil:
+		*id = 0
+	case int64:
+		*id = ID(v)
+	case string:
+		return id.scanString(v)
+	case []byte:
+		return id.scanString(string(v))
+	default:
+		return fmt.Errorf("uniqid: cannot scan %T into ID", src)
+	}
+	return nil
+}
+
+// Value implements driver.Valuer, writing the packed value of id as an
+// int64. The zero ID is written as NULL. It returns ErrInt64Overflow
+// for IDs from September 2028 on.
+func (id BigIntID) Value() (driver.Value, error) {
+	if id == 0 {
+		return nil, nil
+	}
+	return toInt64(uint64(id))
+}
+
+// Scan implements sql.Scanner like ID.Scan.
+func (id *BigIntID) Scan(src any) error {
+	return (*ID)(id).Scan(src)
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// scanString sets id from its string form, or from the decimal text
+// of its packed value.
+func (id *ID) scanString(s string) error {
+	if len(s) != 11 {
+		if v, err := strconv.ParseInt(s, 10, 64); err == nil {
+			*id = ID(v)
+			return nil
+		}
+	}
+	v, err := ParseID(s)
+	if err != nil {
+		return err
+	}
+	*id = v
+	return nil
+}
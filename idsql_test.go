@@ -0,0 +1,69 @@
+package uniqid
+
+import (
+	"database/sql/driver"
+	"strconv"
+	"testing"
+)
+
+// TestIDSQL tests storing and loading IDs through database/sql
+func TestIDSQL(t *testing.T) {
+	gen, _ := New(&Config{ShardID: 7})
+	id, _ := gen.NextID()
+
+	// Test case 1: ID writes the string form
+	v, err := id.Value()
+	if err != nil || v != id.String() {
+		t.Errorf("Value() = %v, %v; want %q", v, err, id.String())
+	}
+
+	// Test case 2: BigIntID writes the packed value
+	v, err = BigIntID(id).Value()
+	lateVal, lateErr := BigIntID(1<<63 | 12345).Value()
+	if err != nil || v != int64(id) {
+		t.Errorf("Value() = %v, %v; want %d", v, err, int64(id))
+	}
+	if lateErr != ErrInt64Overflow {
+		t.Errorf("Expected ErrInt64Overflow for a value with the top bit set, got %v, %v", lateVal, lateErr)
+	}
+
+	// Test case 3: Zero IDs are NULL both ways
+	if v, _ := ID(0).Value(); v != nil {
+		t.Errorf("Expected NULL for the zero ID, got %v", v)
+	}
+	if v, _ := BigIntID(0).Value(); v != nil {
+		t.Errorf("Expected NULL for the zero BigIntID, got %v", v)
+	}
+	got := id
+	if err := got.Scan(nil); err != nil || !got.IsZero() {
+		t.Errorf("Scan(nil) = %v, %v; want zero ID", got, err)
+	}
+
+	// Test case 4: Every stored form scans back into both types,
+	// including negative and decimal-text BIGINTs
+	late := ID(1<<63 | 12345)
+	for _, src := range []driver.Value{
+		id.String(), []byte(id.String()), int64(id), int64(late),
+		[]byte(strconv.FormatInt(int64(id), 10)), []byte(strconv.FormatInt(int64(late), 10)),
+	} {
+		var back ID
+		var big BigIntID
+		if err := back.Scan(src); err != nil {
+			t.Errorf("Scan(%v) failed: %v", src, err)
+		} else if back != id && back != late {
+			t.Errorf("Scan(%v) = %v", src, back)
+		}
+		if err := big.Scan(src); err != nil || ID(big) != back {
+			t.Errorf("BigIntID Scan(%v) = %v, %v; want %v", src, big, err, back)
+		}
+	}
+
+	// Test case 5: Malformed and unsupported values are rejected
+	var bad ID
+	if err := bad.Scan("short"); err != ErrInvalidID {
+		t.Errorf("Expected ErrInvalidID, got %v", err)
+	}
+	if err := bad.Scan(3.5); err == nil {
+		t.Error("Expected error scanning a float64, got nil")
+	}
+}
@@ -0,0 +1,124 @@
+package uniqid
+
+import "hash/fnv"
+
+// NextForKey generates a new ID whose shard field is derived from key
+// rather than the generator's own shard, so all IDs for one entity
+// (e.g. a user ID) land on the same logical shard. The shard is the
+// FNV-1a hash of key reduced to the layout's shard field, the same hash
+// auto-sharding uses.
+//
+// Each derived shard has its own sequence (and counter, see
+// Layout.CounterBits), so IDs from one generator never collide; a key
+// that maps to the generator's own shard shares the state used by
+// Next. The generator tracks at most one sequence
+// per possible shard value.
+//
+// Uniqueness across generators is not guaranteed: two processes
+// calling NextForKey with keys that map to the same shard in the same
+// millisecond can produce the same ID. Route each key to a single
+// generator, or give each process its own layout bits to tell them
+// apart.
+//
+// Example:
+//
+//	id := gen.NextForKey([]byte(userID))
+func (g *Generator) NextForKey(key []byte) string {
+	shard := g.shardForKey(key)
+	g.mu.Lock()
+	var val uint64
+	if shard == g.shard {
+		val = g.nextWaitLocked(g.tick())
+	} else {
+		val = g.nextKeyedLocked(shard)
+	}
+	g.mu.Unlock()
+	return g.format(val)
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// keyedSeq is the sequence state of one shard derived by NextForKey.
+type keyedSeq struct {
+	lastMs  int64
+	seq     uint32
+	counter uint64
+}
+
+// shardForKey returns the shard NextForKey derives from key.
+func (g *Generator) shardForKey(key []byte) uint16 {
+	h := fnv.New32a()
+	_, _ = h.Write(key)
+	return uint16(h.Sum32() & uint32(g.layout.MaxShard()))
+}
+
+// nextKeyedLocked generates the next packed value for a derived shard
+// other than g.shard. Like nextLocked, it must be called with g.mu held
+// and releases it while waiting for the next millisecond. IDs rejected
+// by Config.BannedSubstrings are re-rolled or skipped as by
+// nextTaggedLocked; when too many are, it waits for the next tick.
+func (g *Generator) nextKeyedLocked(shard uint16) uint64 {
+	if g.keyed == nil {
+		g.keyed = make(map[uint16]*keyedSeq)
+	}
+	st := g.keyed[shard]
+	if st == nil {
+		// Like g's own sequence, a new shard resumes after the tick of
+		// the mark loaded from Config.StateStore.
+		st = &keyedSeq{lastMs: g.markTick}
+		if g.markTick > 0 {
+			st.seq = uint32(g.layout.MaxSequence())
+		}
+		g.keyed[shard] = st
+	}
+	for skips := 1; ; skips++ {
+		if val, ok := g.allowed(g.claimKeyedLocked(st, shard)); ok {
+			return val
+		}
+		st.counter--
+		g.stats.Generated--
+		g.stats.Skipped++
+		if skips == g.bannedLimit() {
+			lastMs, nowFunc := st.lastMs, g.deps.nowFunc
+			g.mu.Unlock()
+			g.wait(g.baseEpoch, lastMs*g.unit+g.unit-1, nowFunc, g.spinSleep)
+			g.mu.Lock()
+			skips = 0
+		}
+	}
+}
+
+// claimKeyedLocked claims the next sequence slot of st, the state of
+// shard, and returns its packed value.
+func (g *Generator) claimKeyedLocked(st *keyedSeq, shard uint16) uint64 {
+	for {
+		nowMs := g.tick()
+		if beforeEpoch(nowMs, st.lastMs) {
+			g.waitEpochLocked()
+			continue
+		}
+		if nowMs < st.lastMs {
+			g.stats.ClockBackwards++
+		}
+		if nowMs > st.lastMs {
+			g.saveState(nowMs)
+			st.lastMs = nowMs
+			st.seq = g.firstSeq()
+			break
+		}
+		if int(st.seq) < g.layout.MaxSequence() {
+			st.seq++
+			break
+		}
+		g.stats.Rollovers++
+		lastMs, nowFunc := st.lastMs, g.deps.nowFunc
+		g.mu.Unlock()
+		g.wait(g.baseEpoch, lastMs*g.unit+g.unit-1, nowFunc, g.spinSleep)
+		g.mu.Lock()
+	}
+	g.stats.Generated++
+	st.counter++
+	return g.layout.pack(st.lastMs, shard, st.seq, st.counter-1) | uint64(g.salt)
+}
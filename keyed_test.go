@@ -0,0 +1,90 @@
+package uniqid
+
+import (
+	"fmt"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestNextForKey tests deriving the shard from a per-call key
+func TestNextForKey(t *testing.T) {
+	var mockTime atomic.Int64
+	mockTime.Store(time.Now().UnixMilli())
+	gen, _ := New(&Config{ShardID: 1})
+	gen.deps.nowFunc = mockTime.Load
+
+	// Test case 1: The same key always yields the same shard
+	shards := make(map[string]uint16)
+	seen := make(map[string]bool)
+	for round := 0; round < 3; round++ {
+		for k := 0; k < 50; k++ {
+			key := fmt.Sprintf("user-%d", k)
+			id := gen.NextForKey([]byte(key))
+			if seen[id] {
+				t.Fatalf("Duplicate ID %q for key %q", id, key)
+			}
+			seen[id] = true
+			parts, err := gen.Parse(id)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			if want, ok := shards[key]; ok && parts.Shard != want {
+				t.Errorf("Key %q moved from shard %d to %d", key, want, parts.Shard)
+			}
+			shards[key] = parts.Shard
+		}
+	}
+
+	// Test case 2: Keys sharing a shard get distinct sequence numbers
+	id1 := gen.NextForKey([]byte("user-7"))
+	id2 := gen.NextForKey([]byte("user-7"))
+	p1, _ := gen.Parse(id1)
+	p2, _ := gen.Parse(id2)
+	if p1.Shard != p2.Shard || p2.Seq != p1.Seq+1 {
+		t.Errorf("Expected consecutive sequence on shard %d, got %+v then %+v", p1.Shard, p1, p2)
+	}
+
+	// Test case 3: A key on the generator's own shard shares Next's sequence
+	var own []byte
+	for k := 0; own == nil; k++ {
+		key := []byte(fmt.Sprintf("own-%d", k))
+		if p, _ := gen.Parse(gen.NextForKey(key)); p.Shard == 1 {
+			own = key
+		}
+	}
+	a, _ := gen.Parse(gen.Next())
+	b, _ := gen.Parse(gen.NextForKey(own))
+	if b.Shard != 1 || b.Seq != a.Seq+1 {
+		t.Errorf("Expected NextForKey to continue Next's sequence, got %+v then %+v", a, b)
+	}
+
+	// Test case 4: Exhausting a derived shard waits for the next millisecond
+	gen, _ = New(&Config{ShardID: 1, Layout: Layout{TimestampBits: 40, ShardBits: 8, SequenceBits: 2}})
+	gen.deps.nowFunc = mockTime.Load
+	var other []byte
+	for k := 0; other == nil; k++ {
+		key := []byte(fmt.Sprintf("other-%d", k))
+		if p, _ := gen.Parse(gen.NextForKey(key)); p.Shard != 1 {
+			other = key
+		}
+	}
+	for i := 0; i < 3; i++ {
+		gen.NextForKey(other)
+	}
+	done := make(chan string)
+	go func() { done <- gen.NextForKey(other) }()
+	time.Sleep(5 * time.Millisecond)
+	mockTime.Add(1)
+	select {
+	case id := <-done:
+		if p, _ := gen.Parse(id); p.Seq != 0 || p.Time.UnixMilli() != mockTime.Load() {
+			t.Errorf("Expected first ID of the next millisecond, got %+v", p)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("NextForKey did not resume after the clock advanced")
+	}
+	if s := gen.Stats(); s.Rollovers == 0 {
+		t.Error("Expected a rollover to be counted")
+	}
+}
@@ -0,0 +1,156 @@
+// Package keys generates API keys in the style of GitHub's tokens: a
+// readable prefix naming the key type, a random part, and a checksum,
+// e.g. "uk_live_1hbY7oKq2vZ0cN4xWm8rTg3sPeLd6A0B2m4X". The checksum
+// lets services reject mistyped or made-up keys without hashing them
+// or touching the database, and the prefix lets secret scanners and
+// people recognize leaked keys.
+//
+// Keys use the 62 letters and digits only, so they survive URLs,
+// shells and double-click selection intact. They carry no timestamp;
+// for sortable IDs use the uniqid package itself.
+//
+// Example:
+//
+//	live := keys.Format{Prefix: "uk_live_"}
+//	key, err := live.Generate()
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	if !live.Verify(input) {
+//	    return errUnauthorized // no lookup needed
+//	}
+package keys
+
+import (
+	"crypto/rand"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"hash/crc32"
+	"io"
+)
+
+// base62 is the alphabet of the random part and the checksum.
+const base62 = "0123456789ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz"
+
+// DefaultLength is the number of random characters when Format.Length
+// is zero: 30 characters give about 178 bits of entropy.
+const DefaultLength = 30
+
+// ChecksumLength is the number of characters of the checksum ending
+// every key: the CRC-32 of the prefix and random part in base 62.
+const ChecksumLength = 6
+
+// Format describes a kind of API key.
+//
+// Fields:
+//   - Prefix: Identifies the key type, e.g. "uk_live_"; letters,
+//     digits and underscores, at most 32 (default = none).
+//   - Length: Number of random characters, at least 16
+//     (default = DefaultLength).
+//   - Rand: Entropy source (default = crypto/rand).
+type Format struct {
+	Prefix string
+	Length int
+	Rand   io.Reader
+}
+
+// Generate returns a new key: Prefix, Length random characters and the
+// checksum. It returns an error if the format is invalid or the
+// entropy source fails.
+func (f Format) Generate() (string, error) {
+	n, err := f.validate()
+	if err != nil {
+		return "", err
+	}
+	r := f.Rand
+	if r == nil {
+		r = rand.Reader
+	}
+	key := make([]byte, 0, len(f.Prefix)+n+ChecksumLength)
+	key = append(key, f.Prefix...)
+	// Draw bytes below 248, the largest multiple of 62 that fits, so
+	// every character is equally likely.
+	var buf [64]byte
+	for len(key) < len(f.Prefix)+n {
+		if _, err := io.ReadFull(r, buf[:]); err != nil {
+			return "", fmt.Errorf("keys: %w", err)
+		}
+		for _, b := range buf {
+			if b < 248 && len(key) < len(f.Prefix)+n {
+				key = append(key, base62[b%62])
+			}
+		}
+	}
+	return string(appendChecksum(key, key)), nil
+}
+
+// Verify reports whether key has f's prefix, length and alphabet and
+// a matching checksum. The checksum comparison takes constant time.
+// A true result only means the key is well-formed; it must still be
+// looked up to be trusted.
+func (f Format) Verify(key string) bool {
+	n, err := f.validate()
+	if err != nil || len(key) != len(f.Prefix)+n+ChecksumLength || key[:len(f.Prefix)] != f.Prefix {
+		return false
+	}
+	body := key[:len(key)-ChecksumLength]
+	valid := 1
+	for i := len(f.Prefix); i < len(key); i++ {
+		if !isBase62(key[i]) {
+			valid = 0
+		}
+	}
+	var want [ChecksumLength]byte
+	appendChecksum(want[:0], []byte(body))
+	return subtle.ConstantTimeCompare(want[:], []byte(key[len(body):]))&valid == 1
+}
+
+// Len returns the length of every key of format f.
+func (f Format) Len() int {
+	n := f.Length
+	if n == 0 {
+		n = DefaultLength
+	}
+	return len(f.Prefix) + n + ChecksumLength
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// validate checks f and returns its random length.
+func (f Format) validate() (int, error) {
+	n := f.Length
+	if n == 0 {
+		n = DefaultLength
+	}
+	if n < 16 {
+		return 0, errors.New("keys: length must be at least 16")
+	}
+	if len(f.Prefix) > 32 {
+		return 0, errors.New("keys: prefix must be at most 32 characters")
+	}
+	for i := 0; i < len(f.Prefix); i++ {
+		if c := f.Prefix[i]; c != '_' && !isBase62(c) {
+			return 0, fmt.Errorf("keys: prefix character %q must be a letter, digit or underscore", c)
+		}
+	}
+	return n, nil
+}
+
+// appendChecksum appends the base-62 CRC-32 of body to dst.
+func appendChecksum(dst, body []byte) []byte {
+	sum := crc32.ChecksumIEEE(body)
+	var out [ChecksumLength]byte
+	for i := ChecksumLength - 1; i >= 0; i-- {
+		out[i] = base62[sum%62]
+		sum /= 62
+	}
+	return append(dst, out[:]...)
+}
+
+// isBase62 reports whether c is a letter or digit.
+func isBase62(c byte) bool {
+	return '0' <= c && c <= '9' || 'A' <= c && c <= 'Z' || 'a' <= c && c <= 'z'
+}
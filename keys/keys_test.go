@@ -0,0 +1,87 @@
+package keys
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestGenerate tests generating keys
+func TestGenerate(t *testing.T) {
+	live := Format{Prefix: "uk_live_"}
+
+	// Test case 1: Keys have the prefix, length and alphabet, and differ
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		key, err := live.Generate()
+		if err != nil {
+			t.Fatalf("Generate failed: %v", err)
+		}
+		if !strings.HasPrefix(key, "uk_live_") || len(key) != live.Len() || live.Len() != 8+DefaultLength+ChecksumLength {
+			t.Fatalf("Unexpected key %q", key)
+		}
+		if strings.Trim(key[8:], base62) != "" {
+			t.Fatalf("Key %q has characters outside base 62", key)
+		}
+		if seen[key] {
+			t.Fatalf("Duplicate key %q", key)
+		}
+		seen[key] = true
+	}
+
+	// Test case 2: Bytes of 248 and above are skipped rather than biasing
+	src := append(bytes.Repeat([]byte{255}, 64), bytes.Repeat([]byte{63}, 64)...)
+	key, err := Format{Length: 16, Rand: bytes.NewReader(src)}.Generate()
+	if err != nil || key[:16] != strings.Repeat("1", 16) {
+		t.Errorf("Generate = %q, %v, want sixteen '1's first", key, err)
+	}
+
+	// Test case 3: Invalid formats and failing sources
+	for _, bad := range []Format{
+		{Length: 15},
+		{Prefix: "uk-live"},
+		{Prefix: strings.Repeat("x", 33)},
+		{Rand: bytes.NewReader(nil)},
+	} {
+		if _, err := bad.Generate(); err == nil {
+			t.Errorf("Generate(%+v): expected error, got nil", bad)
+		}
+	}
+}
+
+// TestVerify tests rejecting malformed keys by their checksum
+func TestVerify(t *testing.T) {
+	live := Format{Prefix: "uk_live_"}
+	key, _ := live.Generate()
+
+	// Test case 1: Generated keys verify
+	if !live.Verify(key) {
+		t.Fatalf("Verify(%q) = false", key)
+	}
+
+	// Test case 2: Any single changed character is caught
+	for i := 8; i < len(key); i++ {
+		b := []byte(key)
+		b[i] = map[bool]byte{true: 'b', false: 'a'}[b[i] == 'a']
+		if live.Verify(string(b)) {
+			t.Errorf("Verify(%q) = true after changing position %d", b, i)
+		}
+	}
+
+	// Test case 3: Other prefixes, lengths and characters are rejected
+	test := Format{Prefix: "uk_test_"}
+	for _, bad := range []string{
+		"uk_test_" + key[8:],
+		key[:len(key)-1],
+		key + "0",
+		key[:20] + "-" + key[21:],
+		"",
+	} {
+		if live.Verify(bad) {
+			t.Errorf("Verify(%q) = true", bad)
+		}
+	}
+	if test.Verify(key) {
+		t.Error("Expected a live key to fail verification as a test key")
+	}
+}
@@ -0,0 +1,181 @@
+package uniqid
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"time"
+)
+
+// Layout describes how the bits of an ID's packed value are divided.
+// From most to least significant the fields are: timestamp, shard,
+// sequence, tag, counter, reserved. Reserved bits are zero unless they
+// hold a Config.Salt. The
+// tag field holds an application-defined value chosen per call with
+// NextTagged, e.g. a tenant ID for routing without a lookup; Next
+// leaves it zero. The counter field holds the low bits of a counter
+// that grows by exactly one with every ID the generator issues, across
+// milliseconds, so consumers can detect dropped IDs by gaps.
+// SequenceBeforeShard swaps the shard and sequence fields, as some
+// existing formats such as Sonyflake's order them.
+//
+// The encoded ID uses one character per 6 bits, so the total width
+// determines the ID length: the 64-bit DefaultLayout gives 11
+// characters.
+//
+// Example:
+//
+//	// Fewer nodes, more IDs per millisecond.
+//	gen, err := uniqid.New(&uniqid.Config{
+//	    ShardID: 3,
+//	    Layout:  uniqid.Layout{TimestampBits: 40, ShardBits: 8, SequenceBits: 16},
+//	})
+type Layout struct {
+	TimestampBits int `json:"timestampBits"`
+	ShardBits     int `json:"shardBits"`
+	SequenceBits  int `json:"sequenceBits"`
+	ReservedBits  int `json:"reservedBits"`
+	TagBits       int `json:"tagBits,omitempty"`
+	CounterBits   int `json:"counterBits,omitempty"`
+
+	SequenceBeforeShard bool `json:"sequenceBeforeShard,omitempty"`
+}
+
+// DefaultLayout is the layout used when Config.Layout is left zero:
+// 39-bit millisecond timestamp, 10-bit shard and 15-bit sequence.
+var DefaultLayout = Layout{TimestampBits: 39, ShardBits: 10, SequenceBits: 15}
+
+// NoShardLayout is the layout used with Config.NoShard: no shard
+// field, 44-bit timestamp and 16-bit sequence, encoded in 10 characters.
+var NoShardLayout = Layout{TimestampBits: 44, ShardBits: 0, SequenceBits: 16}
+
+// Validate reports whether l is a supported layout: every field is
+// non-negative, the timestamp and sequence fields are present, shard
+// and sequence fit in 16 bits, and the total width is at most 64 bits.
+func (l Layout) Validate() error {
+	switch {
+	case l.TimestampBits < 1 || l.ShardBits < 0 || l.SequenceBits < 1 || l.ReservedBits < 0 || l.TagBits < 0 || l.CounterBits < 0:
+		return errors.New("layout fields must be non-negative, with at least 1 timestamp and sequence bit")
+	case l.ShardBits > 16:
+		return errors.New("layout shard field must be at most 16 bits")
+	case l.SequenceBits > 16:
+		return errors.New("layout sequence field must be at most 16 bits")
+	case l.Bits() > 64:
+		return fmt.Errorf("layout is %d bits wide, at most 64 supported", l.Bits())
+	}
+	return nil
+}
+
+// Bits returns the total width of the layout in bits.
+func (l Layout) Bits() int {
+	return l.TimestampBits + l.ShardBits + l.SequenceBits + l.TagBits + l.CounterBits + l.ReservedBits
+}
+
+// MaxShard returns the largest shard ID the layout can hold.
+func (l Layout) MaxShard() int {
+	return 1<<l.ShardBits - 1
+}
+
+// MaxSequence returns the largest sequence number the layout can hold,
+// i.e. one less than the number of IDs per millisecond per shard.
+func (l Layout) MaxSequence() int {
+	return 1<<l.SequenceBits - 1
+}
+
+// MaxTag returns the largest tag the layout can hold, or 0 if it has
+// no tag field.
+func (l Layout) MaxTag() uint64 {
+	return 1<<uint(l.TagBits) - 1
+}
+
+// MaxCounter returns the largest value of the counter field, after
+// which it wraps to 0, or 0 if the layout has no counter field.
+func (l Layout) MaxCounter() uint64 {
+	return 1<<uint(l.CounterBits) - 1
+}
+
+// MaxSalt returns the largest Config.Salt the layout's reserved bits
+// can hold.
+func (l Layout) MaxSalt() uint64 {
+	return 1<<uint(min(l.ReservedBits, 16)) - 1
+}
+
+// Horizon returns the last instant the layout can represent for IDs
+// generated against epochMs with timestamp ticks of unit (the
+// Config.TimestampUnit; 0 means a millisecond). Timestamps after it do
+// not fit. A horizon beyond the range of int64 milliseconds is
+// reported as the last such instant.
+func (l Layout) Horizon(epochMs int64, unit time.Duration) time.Time {
+	unitMs := max(unit.Milliseconds(), 1)
+	if l.TimestampBits >= 63 || int64(1)<<l.TimestampBits > (math.MaxInt64-epochMs)/unitMs {
+		return time.UnixMilli(math.MaxInt64)
+	}
+	return time.UnixMilli(epochMs + int64(1)<<l.TimestampBits*unitMs - 1)
+}
+
+// SQLType recommends a column type for storing IDs of this layout.
+// Layouts of at most 63 bits fit a signed 64-bit integer and get
+// "BIGINT". Wider layouts get "CHAR(n)" for the encoded form, since
+// their values can exceed the signed range once the timestamp's top
+// bit is set.
+//
+// The result is advisory. A CHAR column must use a case-sensitive,
+// binary collation, because IDs differing only in letter case are
+// distinct.
+func (l Layout) SQLType() string {
+	if l.Bits() <= 63 {
+		return "BIGINT"
+	}
+	return fmt.Sprintf("CHAR(%d)", l.chars())
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// chars returns the number of characters needed to encode the layout.
+func (l Layout) chars() int {
+	return (l.Bits() + 5) / 6
+}
+
+// pack combines the fields into a single value, with a zero tag.
+// Only the low CounterBits of counter are kept.
+func (l Layout) pack(ms int64, shard uint16, seq uint32, counter uint64) uint64 {
+	counterShift := uint(l.ReservedBits)
+	shardShift, seqShift, timeShift := l.shifts()
+	return uint64(ms)<<timeShift | uint64(shard)<<shardShift | uint64(seq)<<seqShift |
+		counter&l.MaxCounter()<<counterShift
+}
+
+// withTag sets the tag field of a packed value whose tag is zero.
+func (l Layout) withTag(val, tag uint64) uint64 {
+	return val | tag<<uint(l.ReservedBits+l.CounterBits)
+}
+
+// parts splits a packed value into its components; unit is the
+// number of milliseconds per timestamp tick.
+func (l Layout) parts(val uint64, baseEpoch, unit int64) Parts {
+	counterShift := uint(l.ReservedBits)
+	tagShift := counterShift + uint(l.CounterBits)
+	shardShift, seqShift, timeShift := l.shifts()
+	return Parts{
+		Time:    time.UnixMilli(int64(val>>timeShift)*unit + baseEpoch),
+		Shard:   uint16(val>>shardShift) & uint16(l.MaxShard()),
+		Seq:     uint16(val>>seqShift) & uint16(l.MaxSequence()),
+		Tag:     val >> tagShift & l.MaxTag(),
+		Counter: val >> counterShift & l.MaxCounter(),
+		Salt:    uint16(val & l.MaxSalt()),
+	}
+}
+
+// shifts returns the bit offsets of the shard, sequence and timestamp
+// fields, which sit above the tag, counter and reserved bits in the
+// order given by SequenceBeforeShard.
+func (l Layout) shifts() (shard, seq, time uint) {
+	low := uint(l.ReservedBits + l.CounterBits + l.TagBits)
+	shard, seq = low+uint(l.SequenceBits), low
+	if l.SequenceBeforeShard {
+		shard, seq = low, low+uint(l.ShardBits)
+	}
+	return shard, seq, low + uint(l.SequenceBits+l.ShardBits)
+}
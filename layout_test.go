@@ -0,0 +1,250 @@
+package uniqid
+
+import (
+	"math"
+	"testing"
+	"time"
+)
+
+// TestLayoutValidate tests validation of valid and invalid layouts
+func TestLayoutValidate(t *testing.T) {
+	valid := []Layout{
+		DefaultLayout,
+		{TimestampBits: 41, ShardBits: 10, SequenceBits: 12},
+		{TimestampBits: 39, ShardBits: 0, SequenceBits: 16, ReservedBits: 9},
+	}
+	for _, l := range valid {
+		if err := l.Validate(); err != nil {
+			t.Errorf("Validate(%+v) failed: %v", l, err)
+		}
+	}
+
+	invalid := []Layout{
+		{},
+		{TimestampBits: 39, ShardBits: 10, SequenceBits: 0},
+		{TimestampBits: 39, ShardBits: -1, SequenceBits: 15},
+		{TimestampBits: 39, ShardBits: 10, SequenceBits: 15, ReservedBits: -1},
+		{TimestampBits: 30, ShardBits: 17, SequenceBits: 15},
+		{TimestampBits: 30, ShardBits: 10, SequenceBits: 17},
+		{TimestampBits: 40, ShardBits: 10, SequenceBits: 15},
+	}
+	for _, l := range invalid {
+		if err := l.Validate(); err == nil {
+			t.Errorf("Validate(%+v): expected error, got nil", l)
+		}
+	}
+}
+
+// TestLayoutMaxima tests the derived maxima of a layout
+func TestLayoutMaxima(t *testing.T) {
+	if got := DefaultLayout.Bits(); got != 64 {
+		t.Errorf("Expected 64 bits, got %d", got)
+	}
+	if got := DefaultLayout.MaxShard(); got != 1023 {
+		t.Errorf("Expected max shard 1023, got %d", got)
+	}
+	if got := DefaultLayout.MaxSequence(); got != 1<<15-1 {
+		t.Errorf("Expected max sequence %d, got %d", 1<<15-1, got)
+	}
+	if got := DefaultLayout.chars(); got != 11 {
+		t.Errorf("Expected 11 characters, got %d", got)
+	}
+
+	want := time.UnixMilli(defaultEpochMs + 1<<39 - 1)
+	if got := DefaultLayout.Horizon(defaultEpochMs, 0); !got.Equal(want) {
+		t.Errorf("Expected horizon %v, got %v", want, got)
+	}
+	if DefaultLayout.Horizon(defaultEpochMs, time.Millisecond).Year() != 2037 {
+		t.Errorf("Expected default horizon in 2037, got %v", DefaultLayout.Horizon(defaultEpochMs, time.Millisecond))
+	}
+	// Sonyflake-style 10ms ticks last ten times as long.
+	sony := Layout{TimestampBits: 39, ShardBits: 16, SequenceBits: 8}
+	if got, want := sony.Horizon(0, 10*time.Millisecond), time.UnixMilli(10<<39-1); !got.Equal(want) {
+		t.Errorf("Expected 10ms horizon %v, got %v", want, got)
+	}
+	// Horizons past the int64 range saturate instead of overflowing.
+	for _, wide := range []Layout{{TimestampBits: 64}, {TimestampBits: 60, SequenceBits: 4}} {
+		if got := wide.Horizon(defaultEpochMs, time.Second); !got.Equal(time.UnixMilli(math.MaxInt64)) {
+			t.Errorf("Expected a saturated horizon for %+v, got %v", wide, got)
+		}
+	}
+
+	l := Layout{TimestampBits: 41, ShardBits: 5, SequenceBits: 12}
+	if l.MaxShard() != 31 || l.MaxSequence() != 4095 || l.chars() != 10 {
+		t.Errorf("Unexpected maxima for %+v", l)
+	}
+}
+
+// TestLayoutSQLType tests the recommended column type
+func TestLayoutSQLType(t *testing.T) {
+	if got := DefaultLayout.SQLType(); got != "CHAR(11)" {
+		t.Errorf("Expected CHAR(11) for the 64-bit default layout, got %s", got)
+	}
+	l := Layout{TimestampBits: 41, ShardBits: 10, SequenceBits: 12}
+	if got := l.SQLType(); got != "BIGINT" {
+		t.Errorf("Expected BIGINT for a 63-bit layout, got %s", got)
+	}
+}
+
+// TestCustomLayout tests generating and parsing IDs with a custom layout
+func TestCustomLayout(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	l := Layout{TimestampBits: 41, ShardBits: 5, SequenceBits: 12, ReservedBits: 2}
+	gen, err := New(&Config{ShardID: 31, Layout: l})
+	if err != nil {
+		t.Fatalf("New with custom layout failed: %v", err)
+	}
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	_ = gen.Next()
+	id := gen.Next()
+	if len(id) != 10 {
+		t.Errorf("Expected 10-character ID for a 60-bit layout, got %q", id)
+	}
+	p, err := gen.Parse(id)
+	if err != nil {
+		t.Fatalf("Parse(%q) failed: %v", id, err)
+	}
+	if p.Time.UnixMilli() != mockTime || p.Shard != 31 || p.Seq != 1 {
+		t.Errorf("Unexpected parts %+v", p)
+	}
+
+	// Sequence capacity follows the layout
+	for i := 2; i < 1<<12; i++ {
+		if _, err := gen.TryNext(); err != nil {
+			t.Fatalf("TryNext failed at seq %d: %v", i, err)
+		}
+	}
+	if _, err := gen.TryNext(); err != ErrSequenceExhausted {
+		t.Errorf("Expected ErrSequenceExhausted after %d IDs, got %v", 1<<12, err)
+	}
+
+	// Shard must fit the layout
+	if _, err := New(&Config{ShardID: 32, Layout: l}); err == nil {
+		t.Error("Expected error for shard outside layout, got nil")
+	}
+
+	// Invalid layouts are rejected
+	if _, err := New(&Config{ShardID: 1, Layout: Layout{TimestampBits: 60, ShardBits: 10, SequenceBits: 15}}); err == nil {
+		t.Error("Expected error for invalid layout, got nil")
+	}
+
+	// Auto-derived shards are reduced to fit narrow shard fields
+	gen, err = New(&Config{ShardID: -1, Layout: Layout{TimestampBits: 41, ShardBits: 2, SequenceBits: 15}})
+	if err != nil {
+		t.Fatalf("New with auto shard and narrow layout failed: %v", err)
+	}
+	if gen.shard > 3 {
+		t.Errorf("Expected auto shard within 2 bits, got %d", gen.shard)
+	}
+}
+
+// TestNoShard tests generating shorter IDs without a shard field
+func TestNoShard(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	gen, err := New(&Config{NoShard: true})
+	if err != nil {
+		t.Fatalf("New with NoShard failed: %v", err)
+	}
+	gen.deps.nowFunc = func() int64 { return mockTime }
+	if gen.layout != NoShardLayout {
+		t.Errorf("Expected NoShardLayout, got %+v", gen.layout)
+	}
+
+	id := gen.Next()
+	if len(id) != 10 {
+		t.Errorf("Expected 10-character ID, got %q", id)
+	}
+	p, err := gen.Parse(id)
+	if err != nil {
+		t.Fatalf("Parse(%q) failed: %v", id, err)
+	}
+	if p.Time.UnixMilli() != mockTime || p.Shard != 0 || p.Seq != 0 {
+		t.Errorf("Unexpected parts %+v", p)
+	}
+
+	// Wider sequence: 1<<16 IDs fit in one millisecond
+	for i := 1; i < 1<<16; i++ {
+		if _, err := gen.TryNext(); err != nil {
+			t.Fatalf("TryNext failed at seq %d: %v", i, err)
+		}
+	}
+
+	// Explicit layouts must not have a shard field
+	if _, err := New(&Config{NoShard: true, Layout: DefaultLayout}); err == nil {
+		t.Error("Expected error for NoShard with a sharded layout, got nil")
+	}
+}
+
+// TestCounterBits tests the embedded issue counter used for gap detection
+func TestCounterBits(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	layout := Layout{TimestampBits: 39, ShardBits: 4, SequenceBits: 13, CounterBits: 8}
+	gen, err := New(&Config{ShardID: 3, Layout: layout})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	// Test case 1: The counter grows by one per ID, across milliseconds, and wraps
+	var ids []string
+	for i := 0; i < 600; i++ {
+		if i%7 == 0 {
+			mockTime++
+		}
+		ids = append(ids, gen.Next())
+	}
+	for i, id := range ids {
+		p, err := gen.Parse(id)
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if want := uint64(i) & layout.MaxCounter(); p.Counter != want {
+			t.Fatalf("ID %d: expected counter %d, got %d", i, want, p.Counter)
+		}
+		if p.Shard != 3 {
+			t.Errorf("ID %d: expected shard 3, got %d", i, p.Shard)
+		}
+	}
+
+	// Test case 2: A dropped ID shows up as a gap
+	received := append(append([]string{}, ids[10:20]...), ids[21:30]...)
+	gaps := 0
+	for i := 1; i < len(received); i++ {
+		a, _ := gen.Parse(received[i-1])
+		b, _ := gen.Parse(received[i])
+		if (b.Counter-a.Counter)&layout.MaxCounter() != 1 {
+			gaps++
+		}
+	}
+	if gaps != 1 {
+		t.Errorf("Expected exactly 1 gap, found %d", gaps)
+	}
+
+	// Test case 3: A rolled-back ID's counter is reused, leaving no gap
+	last, _ := gen.Parse(ids[len(ids)-1])
+	_, _, rollback := gen.Speculate()
+	rollback()
+	mockTime++
+	p, _ := gen.Parse(gen.Next())
+	if p.Counter != (last.Counter+1)&layout.MaxCounter() {
+		t.Errorf("Expected counter %d after rollback, got %d", last.Counter+1, p.Counter)
+	}
+
+	// Test case 4: Exported state continues the counter
+	b, _ := gen.ExportJSON()
+	imported, err := ImportJSON(b)
+	if err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+	imported.deps.nowFunc = gen.deps.nowFunc
+	q, _ := imported.Parse(imported.Next())
+	if q.Counter != (p.Counter+1)&layout.MaxCounter() {
+		t.Errorf("Expected imported counter %d, got %d", p.Counter+1, q.Counter)
+	}
+
+	// Test case 5: Negative counter width is invalid
+	if err := (Layout{TimestampBits: 39, SequenceBits: 5, CounterBits: -1}).Validate(); err == nil {
+		t.Error("Expected error for negative CounterBits, got nil")
+	}
+}
@@ -0,0 +1,104 @@
+package uniqid
+
+import "sync/atomic"
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// atomicStats holds the counters updated by nextAtomic, which runs
+// without g.mu. Stats adds them to the mutex-guarded ones.
+type atomicStats struct {
+	generated      atomic.Uint64
+	rollovers      atomic.Uint64
+	clockBackwards atomic.Uint64
+}
+
+// addTo returns s with the atomic counters added.
+func (a *atomicStats) addTo(s Stats) Stats {
+	s.Generated += a.generated.Load()
+	s.Rollovers += a.rollovers.Load()
+	s.ClockBackwards += a.clockBackwards.Load()
+	return s
+}
+
+// packWord and unpackWord convert between a (timestamp tick, sequence)
+// pair and the single word nextAtomic swaps: the tick in the upper 48
+// bits, sign included, and the sequence in the lower 16.
+func packWord(ms int64, seq uint32) uint64 {
+	return uint64(ms)<<16 | uint64(seq)
+}
+
+func unpackWord(w uint64) (int64, uint32) {
+	return int64(w) >> 16, uint32(w & 0xFFFF)
+}
+
+// position returns the timestamp tick and sequence of the last issued
+// ID. Unless the generator is LockFree, the caller must hold g.mu.
+func (g *Generator) position() (int64, uint32) {
+	if g.lockFree {
+		return unpackWord(g.word.Load())
+	}
+	return g.lastMs, g.seq
+}
+
+// nextAtomic is nextLocked for Config.LockFree generators: it claims
+// the slot after the last one with a compare-and-swap on g.word, and
+// retries if another goroutine got there first. If locked is true the
+// caller holds g.mu, which is released while waiting for the next
+// millisecond and held again on return.
+func (g *Generator) nextAtomic(block, locked bool, nowMs int64) (uint64, error) {
+	maxSeq := uint32(g.layout.MaxSequence())
+	for {
+		old := g.word.Load()
+		lastMs, seq := unpackWord(old)
+		var next uint64
+		switch {
+		case beforeEpoch(nowMs, lastMs):
+			if !block {
+				return 0, ErrBeforeEpoch
+			}
+			nowFunc := g.deps.nowFunc
+			if locked {
+				g.mu.Unlock()
+			}
+			sleepUntilNextMs(g.baseEpoch, -1, nowFunc, 0)
+			if locked {
+				g.mu.Lock()
+			}
+			nowMs = g.tick()
+			continue
+		case nowMs > lastMs:
+			g.driftSeen.Store(false)
+			next = packWord(nowMs, g.firstSeq())
+		case seq < maxSeq:
+			// Same tick, or the clock moved backwards: stay on lastMs.
+			next = old + 1
+		case !block:
+			return 0, ErrSequenceExhausted
+		default:
+			g.astats.rollovers.Add(1)
+			nowFunc := g.deps.nowFunc
+			if locked {
+				g.mu.Unlock()
+			}
+			// Wait for the last millisecond of the exhausted tick to pass.
+			g.wait(g.baseEpoch, lastMs*g.unit+g.unit-1, nowFunc, g.spinSleep)
+			if locked {
+				g.mu.Lock()
+			}
+			nowMs = g.tick()
+			continue
+		}
+		if !g.word.CompareAndSwap(old, next) {
+			continue
+		}
+		if nowMs < lastMs {
+			g.astats.clockBackwards.Add(1)
+			g.clockDrift(nowMs, lastMs)
+		}
+		g.astats.generated.Add(1)
+		ms, s := unpackWord(next)
+		return g.layout.pack(ms, g.shard, s, 0) | uint64(g.salt), nil
+	}
+}
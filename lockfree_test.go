@@ -0,0 +1,103 @@
+package uniqid
+
+import (
+	"sync"
+	"testing"
+	"time"
+)
+
+// TestLockFree tests generating IDs with the atomic compare-and-swap path
+func TestLockFree(t *testing.T) {
+	// Test case 1: IDs stay unique and ordered per goroutine under contention
+	gen, err := New(&Config{ShardID: 1, LockFree: true})
+	if err != nil {
+		t.Fatalf("New with LockFree failed: %v", err)
+	}
+	const workers, perWorker = 64, 2000
+	results := make([][]uint64, workers)
+	var wg sync.WaitGroup
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func(w int) {
+			defer wg.Done()
+			vals := make([]uint64, perWorker)
+			for i := range vals {
+				vals[i], _ = decode(gen.Next(), DefaultLayout)
+			}
+			results[w] = vals
+		}(w)
+	}
+	wg.Wait()
+	seen := make(map[uint64]struct{}, workers*perWorker)
+	for _, vals := range results {
+		for i, val := range vals {
+			if _, dup := seen[val]; dup {
+				t.Fatalf("Duplicate value %d", val)
+			}
+			seen[val] = struct{}{}
+			if i > 0 && val <= vals[i-1] {
+				t.Fatalf("Values not monotonic within a goroutine: %d after %d", val, vals[i-1])
+			}
+		}
+	}
+	if s := gen.Stats(); s.Generated != workers*perWorker {
+		t.Errorf("Expected %d generated, got %d", workers*perWorker, s.Generated)
+	}
+
+	// Test case 2: Exhaustion, clock regressions and state match the locked path
+	mockTime := time.Now().UnixMilli()
+	gen, err = New(&Config{ShardID: 1, LockFree: true, Layout: Layout{TimestampBits: 39, ShardBits: 10, SequenceBits: 1}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	gen.deps.nowFunc = func() int64 { return mockTime }
+	for i := 0; i < 2; i++ {
+		if _, err := gen.TryNext(); err != nil {
+			t.Fatalf("TryNext %d failed: %v", i, err)
+		}
+	}
+	if _, err := gen.TryNext(); err != ErrSequenceExhausted {
+		t.Errorf("Expected ErrSequenceExhausted, got %v", err)
+	}
+	mockTime++
+	gen.Next()
+	mockTime -= 2
+	id := gen.Next()
+	if p, _ := gen.Parse(id); p.Time.UnixMilli() != mockTime+2 || p.Seq != 1 {
+		t.Errorf("Expected the last timestamp to be reused, got %+v", p)
+	}
+	if ms, seq := gen.State(); ms != mockTime+2 || seq != 1 {
+		t.Errorf("Unexpected state %d/%d", ms, seq)
+	}
+	if s := gen.Stats(); s.Generated != 4 || s.ClockBackwards != 1 {
+		t.Errorf("Unexpected stats: %+v", s)
+	}
+
+	// Test case 3: Block reservation is refused
+	if _, err := gen.ReserveBlock(4); err == nil {
+		t.Error("Expected ReserveBlock to fail with LockFree, got nil")
+	}
+
+	// Test case 4: Options that need the lock are rejected
+	for _, bad := range []*Config{
+		{LockFree: true, Layout: Layout{TimestampBits: 49, ShardBits: 0, SequenceBits: 15}},
+		{LockFree: true, Layout: Layout{TimestampBits: 39, ShardBits: 10, SequenceBits: 7, CounterBits: 8}},
+		{LockFree: true, TrackHistogram: true},
+		{LockFree: true, BurstOverflow: true, Layout: Layout{TimestampBits: 39, ShardBits: 10, SequenceBits: 11, ReservedBits: 4}},
+		{LockFree: true, RefreshShardInterval: time.Minute},
+	} {
+		if _, err := New(bad); err == nil {
+			t.Errorf("Expected error for %+v, got nil", bad)
+		}
+	}
+}
+
+func BenchmarkNextParallelLockFree(b *testing.B) {
+	gen, _ := New(&Config{ShardID: 1, LockFree: true})
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = gen.Next()
+		}
+	})
+}
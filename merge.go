@@ -0,0 +1,94 @@
+package uniqid
+
+import (
+	"container/heap"
+	"fmt"
+)
+
+// MergeSorted k-way merges streams of IDs, each already sorted by
+// generation time (e.g. per-shard files), into a single slice in
+// global chronological order. IDs are ordered by their decoded
+// timestamp, then shard, then sequence, so the result is correct even
+// though the default alphabet does not sort in ASCII order.
+//
+// IDs must use the default format. An ID that cannot be decoded
+// aborts the merge with an error naming its position.
+//
+// Example:
+//
+//	all, err := uniqid.MergeSorted(shard0IDs, shard1IDs, shard2IDs)
+func MergeSorted(streams ...[]string) ([]string, error) {
+	h := make(mergeHeap, 0, len(streams))
+	total := 0
+	for i, s := range streams {
+		total += len(s)
+		if len(s) == 0 {
+			continue
+		}
+		c, err := newMergeCursor(s, i)
+		if err != nil {
+			return nil, err
+		}
+		h = append(h, c)
+	}
+	heap.Init(&h)
+
+	out := make([]string, 0, total)
+	for h.Len() > 0 {
+		c := h[0]
+		out = append(out, c.ids[c.pos])
+		c.pos++
+		if c.pos == len(c.ids) {
+			heap.Pop(&h)
+			continue
+		}
+		val, err := decode(c.ids[c.pos], DefaultLayout)
+		if err != nil {
+			return nil, fmt.Errorf("stream %d, index %d: %w", c.stream, c.pos, err)
+		}
+		c.val = val
+		heap.Fix(&h, 0)
+	}
+	return out, nil
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// mergeCursor tracks the read position within one input stream.
+type mergeCursor struct {
+	ids    []string
+	pos    int
+	val    uint64
+	stream int
+}
+
+// newMergeCursor positions a cursor at the start of a non-empty stream.
+func newMergeCursor(ids []string, stream int) (*mergeCursor, error) {
+	val, err := decode(ids[0], DefaultLayout)
+	if err != nil {
+		return nil, fmt.Errorf("stream %d, index 0: %w", stream, err)
+	}
+	return &mergeCursor{ids: ids, val: val, stream: stream}, nil
+}
+
+// mergeHeap is a min-heap of cursors ordered by their current value,
+// with the stream index breaking ties so the merge is stable.
+type mergeHeap []*mergeCursor
+
+func (h mergeHeap) Len() int { return len(h) }
+func (h mergeHeap) Less(i, j int) bool {
+	if h[i].val != h[j].val {
+		return h[i].val < h[j].val
+	}
+	return h[i].stream < h[j].stream
+}
+func (h mergeHeap) Swap(i, j int) { h[i], h[j] = h[j], h[i] }
+func (h *mergeHeap) Push(x any)   { *h = append(*h, x.(*mergeCursor)) }
+func (h *mergeHeap) Pop() any {
+	old := *h
+	c := old[len(old)-1]
+	*h = old[:len(old)-1]
+	return c
+}
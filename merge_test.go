@@ -0,0 +1,64 @@
+package uniqid
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestMergeSorted tests merging per-shard streams into time order
+func TestMergeSorted(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	clock := func() int64 { return mockTime }
+
+	gens := make([]*Generator, 3)
+	for i := range gens {
+		gens[i], _ = New(&Config{ShardID: i})
+		gens[i].deps.nowFunc = clock
+	}
+
+	// Interleave generation across shards while time advances
+	streams := make([][]string, 3)
+	total := 0
+	for step := 0; step < 30; step++ {
+		shard := (step * 7) % 3
+		streams[shard] = append(streams[shard], gens[shard].Next())
+		total++
+		if step%4 == 0 {
+			mockTime++
+		}
+	}
+
+	merged, err := MergeSorted(streams[0], nil, streams[1], streams[2])
+	if err != nil {
+		t.Fatalf("MergeSorted failed: %v", err)
+	}
+	if len(merged) != total {
+		t.Fatalf("Expected %d IDs, got %d", total, len(merged))
+	}
+	var prev Parts
+	for i, id := range merged {
+		p, err := Parse(id)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", id, err)
+		}
+		if i > 0 && p.Time.Before(prev.Time) {
+			t.Errorf("ID %d (%q) is earlier than its predecessor", i, id)
+		}
+		prev = p
+	}
+
+	// Empty input
+	if merged, err := MergeSorted(); err != nil || len(merged) != 0 {
+		t.Errorf("Expected empty result, got %v, %v", merged, err)
+	}
+
+	// Invalid IDs report their position
+	_, err = MergeSorted(streams[0], []string{streams[1][0], "bad"})
+	if !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Expected ErrInvalidID, got %v", err)
+	}
+	if _, err := MergeSorted([]string{"bad"}); !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Expected ErrInvalidID, got %v", err)
+	}
+}
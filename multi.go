@@ -0,0 +1,172 @@
+package uniqid
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+)
+
+// MultiGenerator produces IDs across several shards owned by the same
+// process, e.g. a contiguous block assigned by a coordinator. Each
+// shard has its own counter state, so per-millisecond throughput grows
+// with the number of shards while IDs stay globally unique.
+// It is safe for concurrent use by multiple goroutines.
+//
+// IDs are time-sortable per shard only; consecutive calls to Next
+// rotate across shards.
+type MultiGenerator struct {
+	gens     []*Generator
+	shards   []int
+	next     atomic.Uint64
+	reserved bool
+	close    sync.Once
+}
+
+// NewMultiShard creates a MultiGenerator owning the given shards.
+// All other settings come from cfg (nil means defaults); cfg.ShardID
+// is ignored. Shards must be distinct and fit the configured layout.
+//
+// The shards are reserved in this process's shard registry (see
+// ReserveShard) until Close is called, so two MultiGenerators never
+// share a shard. cfg.OnShardConflict decides what happens when a
+// requested shard is already reserved: fail (the default), substitute
+// the next free shard, or use it anyway. Shards reports the shards
+// actually used.
+//
+// A StateStore serves a single generator, so cfg.StateStore is
+// rejected; cfg.StateFile is used with a ".<shard>" suffix, giving
+// each shard its own file.
+//
+// Example:
+//
+//	mg, err := uniqid.NewMultiShard(nil, []int{8, 9, 10, 11})
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	id := mg.Next()
+func NewMultiShard(cfg *Config, shards []int) (*MultiGenerator, error) {
+	if len(shards) == 0 {
+		return nil, errors.New("at least one shard is required")
+	}
+	base := Config{}
+	if cfg != nil {
+		base = *cfg
+	}
+	seen := make(map[int]struct{}, len(shards))
+	for _, s := range shards {
+		if _, dup := seen[s]; dup {
+			return nil, fmt.Errorf("shard %d listed more than once", s)
+		}
+		seen[s] = struct{}{}
+		if s < 0 {
+			return nil, fmt.Errorf("shard %d must not be negative", s)
+		}
+	}
+
+	if base.StateStore != nil {
+		return nil, errors.New("stateStore cannot be shared by several shards; use stateFile")
+	}
+
+	policy := base.OnShardConflict
+	claimed, err := claimShards(shards, policy, base.layout().MaxShard(), seen)
+	if err != nil {
+		return nil, err
+	}
+	// The generators are created without the registry lock held, as
+	// New may do I/O; Close releases the shards if one fails.
+	mg := &MultiGenerator{shards: claimed, reserved: policy != ShardConflictAllow}
+	for _, s := range claimed {
+		c := base
+		c.ShardID = s
+		if base.StateFile != "" {
+			c.StateFile = fmt.Sprintf("%s.%d", base.StateFile, s)
+		}
+		g, err := newFunc(&c)
+		if err != nil {
+			mg.Close()
+			return nil, err
+		}
+		mg.gens = append(mg.gens, g)
+	}
+	return mg, nil
+}
+
+// Next returns a new unique ID, rotating round-robin across the owned
+// shards.
+func (m *MultiGenerator) Next() string {
+	i := (m.next.Add(1) - 1) % uint64(len(m.gens))
+	return m.gens[i].Next()
+}
+
+// Shards returns the shards owned by m, in the order given to
+// NewMultiShard, with any substitutions made under
+// ShardConflictSkipOccupied.
+func (m *MultiGenerator) Shards() []int {
+	return append([]int(nil), m.shards...)
+}
+
+// Close closes m's generators (see Generator.Close) and releases its
+// shards from the shard registry so they can be reserved again. m must
+// not be used afterwards. Calling Close more than once is a no-op.
+func (m *MultiGenerator) Close() {
+	m.close.Do(func() {
+		for _, g := range m.gens {
+			g.Close()
+		}
+		if !m.reserved {
+			return
+		}
+		for _, s := range m.shards {
+			ReleaseShard(s)
+		}
+	})
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// claimShards returns the shards NewMultiShard uses for the requested
+// ones under policy, substituting reserved shards with
+// ShardConflictSkipOccupied, and reserves them unless policy is
+// ShardConflictAllow. taken holds the requested shards and gains the
+// substitutes.
+func claimShards(shards []int, policy ShardConflictPolicy, maxShard int, taken map[int]struct{}) ([]int, error) {
+	shardRegistry.mu.Lock()
+	defer shardRegistry.mu.Unlock()
+	claimed := make([]int, 0, len(shards))
+	for _, s := range shards {
+		if policy != ShardConflictAllow && shardRegistry.held[s] {
+			if policy != ShardConflictSkipOccupied {
+				return nil, fmt.Errorf("shard %d: %w", s, ErrShardReserved)
+			}
+			free, ok := freeShard(s, maxShard, taken)
+			if !ok {
+				return nil, fmt.Errorf("no free shard to replace reserved shard %d", s)
+			}
+			taken[free] = struct{}{}
+			s = free
+		}
+		claimed = append(claimed, s)
+	}
+	if policy != ShardConflictAllow {
+		for _, s := range claimed {
+			shardRegistry.held[s] = true
+		}
+	}
+	return claimed, nil
+}
+
+// freeShard returns the first shard after s, wrapping around within
+// [0, maxShard], that is neither reserved nor in taken. The caller must
+// hold shardRegistry.mu.
+func freeShard(s, maxShard int, taken map[int]struct{}) (int, bool) {
+	for i := 1; i <= maxShard; i++ {
+		c := (s + i) % (maxShard + 1)
+		if _, ok := taken[c]; !ok && !shardRegistry.held[c] {
+			return c, true
+		}
+	}
+	return 0, false
+}
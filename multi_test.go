@@ -0,0 +1,181 @@
+package uniqid
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+)
+
+// TestNewMultiShard tests creating multi-shard generators
+func TestNewMultiShard(t *testing.T) {
+	mg, err := NewMultiShard(&Config{ShardID: 99}, []int{4, 5, 6})
+	if err != nil {
+		t.Fatalf("NewMultiShard failed: %v", err)
+	}
+	defer mg.Close()
+	shards := mg.Shards()
+	if len(shards) != 3 || shards[0] != 4 || shards[2] != 6 {
+		t.Errorf("Unexpected shards %v", shards)
+	}
+	shards[0] = 100
+	if mg.Shards()[0] != 4 {
+		t.Error("Shards should return a copy")
+	}
+
+	// Next rotates across the owned shards
+	for i := 0; i < 6; i++ {
+		p, err := Parse(mg.Next())
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if want := uint16(4 + i%3); p.Shard != want {
+			t.Errorf("Expected shard %d, got %d", want, p.Shard)
+		}
+	}
+
+	// Invalid shard sets
+	for _, bad := range [][]int{nil, {1, 2, 1}, {1, 1024}, {-1}} {
+		if _, err := NewMultiShard(nil, bad); err == nil {
+			t.Errorf("NewMultiShard(%v): expected error, got nil", bad)
+		}
+	}
+	// ...release the shards reserved before the failure
+	if err := ReserveShard(1); err != nil {
+		t.Errorf("Expected shard 1 to be released, got %v", err)
+	}
+	ReleaseShard(1)
+
+	// A StateStore is rejected, and StateFile gets one file per shard
+	if _, err := NewMultiShard(&Config{StateStore: NewFileStateStore("unused")}, []int{1}); err == nil {
+		t.Error("Expected error for a shared StateStore, got nil")
+	}
+	path := filepath.Join(t.TempDir(), "uniqid.state")
+	mg, err = NewMultiShard(&Config{StateFile: path}, []int{1, 2})
+	if err != nil {
+		t.Fatalf("NewMultiShard failed: %v", err)
+	}
+	defer mg.Close()
+	mg.Next()
+	mg.Next()
+	for _, name := range []string{path + ".1", path + ".2"} {
+		if _, err := os.Stat(name); err != nil {
+			t.Errorf("Expected state file %s: %v", name, err)
+		}
+	}
+}
+
+// TestMultiShardConcurrency tests uniqueness across shards under load
+func TestMultiShardConcurrency(t *testing.T) {
+	mg, err := NewMultiShard(nil, []int{0, 1, 2, 3})
+	if err != nil {
+		t.Fatalf("NewMultiShard failed: %v", err)
+	}
+	defer mg.Close()
+
+	const workers, perWorker = 8, 10000
+	var (
+		mu   sync.Mutex
+		seen = make(map[string]struct{}, workers*perWorker)
+		wg   sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids := make([]string, perWorker)
+			for i := range ids {
+				ids[i] = mg.Next()
+			}
+			mu.Lock()
+			for _, id := range ids {
+				seen[id] = struct{}{}
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+
+	if len(seen) != workers*perWorker {
+		t.Errorf("Generated duplicate IDs, expected %d unique, got %d", workers*perWorker, len(seen))
+	}
+}
+
+// TestShardConflict tests the policies for shards already reserved in the process
+func TestShardConflict(t *testing.T) {
+	if err := ReserveShard(21); err != nil {
+		t.Fatalf("ReserveShard failed: %v", err)
+	}
+	defer ReleaseShard(21)
+
+	// Test case 1: Reserving twice fails
+	if err := ReserveShard(21); !errors.Is(err, ErrShardReserved) {
+		t.Errorf("Expected ErrShardReserved, got %v", err)
+	}
+	if err := ReserveShard(-1); err == nil {
+		t.Error("Expected error for negative shard, got nil")
+	}
+
+	// Test case 2: The default policy fails on a reserved shard
+	if _, err := NewMultiShard(nil, []int{20, 21}); !errors.Is(err, ErrShardReserved) {
+		t.Errorf("Expected ErrShardReserved, got %v", err)
+	}
+	// ...and reserves nothing on failure
+	if err := ReserveShard(20); err != nil {
+		t.Errorf("Expected shard 20 to stay free, got %v", err)
+	}
+	ReleaseShard(20)
+
+	// Test case 3: SkipOccupied substitutes the next free shard
+	mg, err := NewMultiShard(&Config{OnShardConflict: ShardConflictSkipOccupied}, []int{20, 21, 22})
+	if err != nil {
+		t.Fatalf("NewMultiShard failed: %v", err)
+	}
+	if got := mg.Shards(); got[0] != 20 || got[1] != 23 || got[2] != 22 {
+		t.Errorf("Expected shards [20 23 22], got %v", got)
+	}
+	if err := ReserveShard(23); !errors.Is(err, ErrShardReserved) {
+		t.Errorf("Expected substituted shard to be reserved, got %v", err)
+	}
+
+	// Test case 4: Shards held by a MultiGenerator are freed by Close
+	if _, err := NewMultiShard(nil, []int{22}); !errors.Is(err, ErrShardReserved) {
+		t.Errorf("Expected ErrShardReserved for shard held by another pool, got %v", err)
+	}
+	mg.Close()
+	mg.Close()
+	other, err := NewMultiShard(nil, []int{22})
+	if err != nil {
+		t.Fatalf("Expected shard 22 to be free after Close, got %v", err)
+	}
+	other.Close()
+
+	// Test case 5: SkipOccupied fails when the layout has no free shard
+	small := Layout{TimestampBits: 39, ShardBits: 1, SequenceBits: 15}
+	if err := ReserveShard(0); err != nil {
+		t.Fatalf("ReserveShard failed: %v", err)
+	}
+	defer ReleaseShard(0)
+	if _, err := NewMultiShard(&Config{Layout: small, OnShardConflict: ShardConflictSkipOccupied}, []int{0, 1}); err == nil {
+		t.Error("Expected error when no free shard remains, got nil")
+	}
+
+	// Test case 6: Allow uses the reserved shard without reserving it
+	mg, err = NewMultiShard(&Config{OnShardConflict: ShardConflictAllow}, []int{21})
+	if err != nil {
+		t.Fatalf("NewMultiShard failed: %v", err)
+	}
+	if p, _ := Parse(mg.Next()); p.Shard != 21 {
+		t.Errorf("Expected shard 21, got %d", p.Shard)
+	}
+	mg.Close()
+	if err := ReserveShard(21); !errors.Is(err, ErrShardReserved) {
+		t.Error("Expected Close under Allow not to release the original reservation")
+	}
+
+	// Test case 7: Unknown policies are rejected
+	if _, err := NewMultiShard(&Config{OnShardConflict: 7}, []int{30}); err == nil {
+		t.Error("Expected error for unknown policy, got nil")
+	}
+}
@@ -0,0 +1,53 @@
+package uniqid
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/binary"
+)
+
+// permuteRounds is the number of Feistel rounds of permute. Small
+// layouts have small halves, so it uses more than the 4 rounds a
+// 64-bit network would need.
+const permuteRounds = 8
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// permute applies (or with inverse, undoes) a keyed permutation of the
+// values below 2^bits, for Config.TokenKey and Config.ObfuscationKey:
+// a balanced Feistel network over the smallest even width of at least
+// bits, with HMAC-SHA256 as the round function, repeated (cycle
+// walking) until the result fits in bits again.
+func permute(key []byte, val uint64, bits int, inverse bool) uint64 {
+	half := uint(bits+1) / 2
+	mask := uint64(1)<<half - 1
+	for {
+		l, r := val>>half, val&mask
+		for i := 0; i < permuteRounds; i++ {
+			if inverse {
+				round := permuteRounds - 1 - i
+				l, r = r^permuteRound(key, bits, round, l)&mask, l
+			} else {
+				l, r = r, l^permuteRound(key, bits, i, r)&mask
+			}
+		}
+		val = l<<half | r
+		if bits == 64 || val < 1<<uint(bits) {
+			return val
+		}
+	}
+}
+
+// permuteRound is the round function: the first 64 bits of
+// HMAC-SHA256(key, bits || round || half). Including the width keeps
+// the permutations of different widths independent under one key.
+func permuteRound(key []byte, bits, round int, half uint64) uint64 {
+	var msg [10]byte
+	msg[0], msg[1] = byte(bits), byte(round)
+	binary.BigEndian.PutUint64(msg[2:], half)
+	mac := hmac.New(sha256.New, key)
+	_, _ = mac.Write(msg[:])
+	return binary.BigEndian.Uint64(mac.Sum(nil))
+}
@@ -0,0 +1,94 @@
+package uniqid
+
+import (
+	"bytes"
+	"math/rand/v2"
+	"testing"
+	"time"
+)
+
+// TestPermute tests the keyed permutation behind ObfuscationKey
+func TestPermute(t *testing.T) {
+	key := bytes.Repeat([]byte("p"), 16)
+
+	// Test case 1: Small domains are permuted onto themselves
+	for _, bits := range []int{1, 5, 8} {
+		seen := make(map[uint64]bool)
+		for v := uint64(0); v < 1<<bits; v++ {
+			p := permute(key, v, bits, false)
+			if p >= 1<<bits || seen[p] {
+				t.Fatalf("bits %d: %d maps to %d, out of range or taken", bits, v, p)
+			}
+			seen[p] = true
+			if back := permute(key, p, bits, true); back != v {
+				t.Fatalf("bits %d: inverse of %d gives %d, want %d", bits, p, back, v)
+			}
+		}
+	}
+
+	// Test case 2: Wide values round-trip and stay within their width
+	for _, bits := range []int{39, 60, 63, 64} {
+		for i := 0; i < 100; i++ {
+			v := rand.Uint64() >> (64 - bits)
+			p := permute(key, v, bits, false)
+			if bits < 64 && p >= 1<<bits {
+				t.Fatalf("bits %d: %d maps out of range to %d", bits, v, p)
+			}
+			if back := permute(key, p, bits, true); back != v {
+				t.Fatalf("bits %d: round trip of %d gives %d", bits, v, back)
+			}
+		}
+	}
+}
+
+// TestObfuscationKey tests generating and parsing encrypted IDs
+func TestObfuscationKey(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	key := bytes.Repeat([]byte("o"), 16)
+	cfg := &Config{ShardID: 7, ObfuscationKey: key}
+	gen, err := New(cfg)
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	// Test case 1: Parse with the key recovers the components
+	ids := gen.NextN(50)
+	for i, id := range ids {
+		p, err := ParseWith(id, cfg)
+		if err != nil || p.Shard != 7 || int(p.Seq) != i || p.Time.UnixMilli() != mockTime {
+			t.Fatalf("ParseWith(%q) = %+v, %v", id, p, err)
+		}
+	}
+
+	// Test case 2: Without the key the components are hidden
+	exposed, sorted := 0, true
+	for i, id := range ids {
+		if p, err := Parse(id); err == nil && p.Shard == 7 {
+			exposed++
+		}
+		if i > 0 && id < ids[i-1] {
+			sorted = false
+		}
+	}
+	if exposed > 5 || sorted {
+		t.Errorf("Expected scrambled IDs, %d of 50 show the shard, sorted %v", exposed, sorted)
+	}
+
+	// Test case 3: Other layouts, encodings and a short key
+	for _, c := range []*Config{
+		{ShardID: 1, Layout: Layout{TimestampBits: 41, ShardBits: 6, SequenceBits: 12}, ObfuscationKey: key},
+		{ShardID: 1, Encoding: EncodingCrockford, Checksum: true, ObfuscationKey: key},
+	} {
+		g, err := New(c)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		if id := g.Next(); !g.Verify(id) {
+			t.Errorf("Verify(%q) = false", id)
+		}
+	}
+	if _, err := New(&Config{ShardID: 1, ObfuscationKey: []byte("short")}); err == nil {
+		t.Error("Expected error for a short obfuscation key, got nil")
+	}
+}
@@ -0,0 +1,108 @@
+package uniqid
+
+import "time"
+
+// Option configures a generator created by NewWithOptions.
+type Option func(*options)
+
+// NewWithOptions creates a generator from functional options, an
+// alternative to New for callers who prefer spelling out only what
+// they change. Unlike Config's zero values, each option means exactly
+// what it says: without WithShard the shard ID is auto-derived, as for
+// New(nil), and WithEpoch(time.UnixMilli(0)) selects the Unix epoch
+// rather than the default. Later options override earlier ones.
+//
+// Example:
+//
+//	gen, err := uniqid.NewWithOptions(
+//	    uniqid.WithShard(5),
+//	    uniqid.WithEpoch(time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)),
+//	    uniqid.WithAlphabet(uniqid.SortableAlphabet),
+//	)
+func NewWithOptions(opts ...Option) (*Generator, error) {
+	o := options{cfg: Config{ShardID: -1}}
+	for _, opt := range opts {
+		opt(&o)
+	}
+	return New(&o.cfg)
+}
+
+// WithConfig starts from a copy of cfg; options after it adjust it.
+func WithConfig(cfg Config) Option {
+	return func(o *options) { o.cfg = cfg }
+}
+
+// WithShard sets the shard ID (Config.ShardID); -1 auto-derives it.
+func WithShard(id int) Option {
+	return func(o *options) { o.cfg.ShardID = id }
+}
+
+// WithShardProvider takes the shard ID from p (Config.ShardProvider).
+func WithShardProvider(p ShardProvider) Option {
+	return func(o *options) { o.cfg.ShardProvider, o.cfg.ShardID = p, 0 }
+}
+
+// WithEpoch sets the custom epoch (Config.CustomEpochMs) to t,
+// truncated to the millisecond; the Unix epoch sets Config.UnixEpoch.
+func WithEpoch(t time.Time) Option {
+	return func(o *options) {
+		ms := t.UnixMilli()
+		o.cfg.CustomEpochMs, o.cfg.UnixEpoch = ms, ms == 0
+	}
+}
+
+// WithClock reads the time from c (Config.Clock).
+func WithClock(c Clock) Option {
+	return func(o *options) { o.cfg.Clock = c }
+}
+
+// WithAlphabet encodes IDs with alphabet a (Config.Alphabet).
+func WithAlphabet(a string) Option {
+	return func(o *options) { o.cfg.Alphabet = a }
+}
+
+// WithEncoding renders IDs with encoding e (Config.Encoding).
+func WithEncoding(e Encoding) Option {
+	return func(o *options) { o.cfg.Encoding = e }
+}
+
+// WithLayout sets the bit layout (Config.Layout).
+func WithLayout(l Layout) Option {
+	return func(o *options) { o.cfg.Layout = l }
+}
+
+// WithNoShard drops the shard field (Config.NoShard).
+func WithNoShard() Option {
+	return func(o *options) { o.cfg.NoShard, o.cfg.ShardID = true, 0 }
+}
+
+// WithName labels the generator (Config.Name).
+func WithName(name string) Option {
+	return func(o *options) { o.cfg.Name = name }
+}
+
+// WithPrefix prepends a type prefix such as "ord_" to every ID
+// (Config.Prefix).
+func WithPrefix(prefix string) Option {
+	return func(o *options) { o.cfg.Prefix = prefix }
+}
+
+// WithVersionPrefix prepends c to every ID (Config.VersionPrefix).
+func WithVersionPrefix(c byte) Option {
+	return func(o *options) { o.cfg.VersionPrefix = c }
+}
+
+// WithTimestampUnit sets the time one timestamp tick represents
+// (Config.TimestampUnit).
+func WithTimestampUnit(d time.Duration) Option {
+	return func(o *options) { o.cfg.TimestampUnit = d }
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// options collects the settings of NewWithOptions.
+type options struct {
+	cfg Config
+}
@@ -0,0 +1,86 @@
+package uniqid
+
+import (
+	"os"
+	"path/filepath"
+	"strconv"
+	"testing"
+	"time"
+)
+
+// fixedClock is a Clock frozen at a Unix millisecond.
+type fixedClock int64
+
+func (c fixedClock) NowMs() int64 { return int64(c) }
+
+// TestNewWithOptions tests building generators from functional options
+func TestNewWithOptions(t *testing.T) {
+	epoch := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	now := epoch.Add(time.Hour).UnixMilli()
+
+	// Test case 1: Options set their fields
+	gen, err := NewWithOptions(
+		WithShard(5),
+		WithEpoch(epoch),
+		WithClock(fixedClock(now)),
+		WithAlphabet(SortableAlphabet),
+		WithName("opts"),
+		WithVersionPrefix('v'),
+	)
+	if err != nil {
+		t.Fatalf("NewWithOptions failed: %v", err)
+	}
+	cfg := gen.Config()
+	if cfg.ShardID != 5 || cfg.CustomEpochMs != epoch.UnixMilli() || cfg.Alphabet != SortableAlphabet || cfg.Name != "opts" {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+	id := gen.Next()
+	if p, err := gen.Parse(id); err != nil || p.Time.UnixMilli() != now || p.Shard != 5 || id[0] != 'v' {
+		t.Errorf("Unexpected ID %q: %+v, %v", id, p, err)
+	}
+
+	// Test case 2: Without WithShard the shard is auto-derived
+	gen, err = NewWithOptions()
+	if err != nil {
+		t.Fatalf("NewWithOptions failed: %v", err)
+	}
+	if want, _ := autoShardWithDeps(newDeps(&Config{})); gen.Config().ShardID != int(want) {
+		t.Errorf("Expected auto shard %d, got %d", want, gen.Config().ShardID)
+	}
+
+	// Test case 3: The Unix epoch is distinct from the default
+	gen, _ = NewWithOptions(WithShard(1), WithEpoch(time.UnixMilli(0)), WithClock(fixedClock(now)),
+		WithLayout(Layout{TimestampBits: 44, ShardBits: 4, SequenceBits: 16}))
+	if p, _ := gen.Parse(gen.Next()); p.Time.UnixMilli() != now || gen.baseEpoch != 0 {
+		t.Errorf("Expected the Unix epoch, got base %d and time %v", gen.baseEpoch, p.Time)
+	}
+	// Config round-trips it through New
+	cfg = gen.Config()
+	if again, err := New(&cfg); err != nil || again.baseEpoch != 0 || !cfg.UnixEpoch {
+		t.Errorf("Expected Config to keep the Unix epoch, got %+v, %v", cfg, err)
+	}
+	// The state mark is read with the Unix epoch
+	path := filepath.Join(t.TempDir(), "uniqid.state")
+	os.WriteFile(path, []byte(strconv.FormatInt(now+5000, 10)), 0o644)
+	gen, _ = NewWithOptions(WithShard(1), WithEpoch(time.UnixMilli(0)), WithClock(fixedClock(now)),
+		WithLayout(Layout{TimestampBits: 44, ShardBits: 4, SequenceBits: 16}),
+		func(o *options) { o.cfg.StateFile = path })
+	if _, err := gen.TryNext(); err != ErrSequenceExhausted {
+		t.Errorf("Expected no ID before the stored mark, got %v", err)
+	}
+	if _, err := New(&Config{UnixEpoch: true, CustomEpochMs: 1}); err == nil {
+		t.Error("Expected error for UnixEpoch with CustomEpochMs, got nil")
+	}
+
+	// Test case 4: Later options override earlier ones, and invalid ones fail
+	gen, _ = NewWithOptions(WithConfig(Config{ShardID: 3, Name: "base"}), WithName("override"))
+	if cfg := gen.Config(); cfg.ShardID != 3 || cfg.Name != "override" {
+		t.Errorf("Unexpected config %+v", cfg)
+	}
+	if _, err := NewWithOptions(WithShard(5000)); err == nil {
+		t.Error("Expected error for shard 5000, got nil")
+	}
+	if _, err := NewWithOptions(WithNoShard(), WithLayout(NoShardLayout)); err != nil {
+		t.Errorf("Expected WithNoShard to work, got %v", err)
+	}
+}
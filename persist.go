@@ -0,0 +1,118 @@
+package uniqid
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+)
+
+// stateAheadMs is how far ahead of the issued timestamps the persisted
+// high-water mark is set, in milliseconds. It bounds how often the
+// StateStore is written.
+const stateAheadMs = 1000
+
+// StateStore persists a generator's timestamp high-water mark across
+// restarts; see Config.StateStore. Load returns the stored mark in
+// Unix milliseconds, or 0 if none was saved yet. Save replaces it and
+// must not return before it is durable.
+type StateStore interface {
+	Load() (int64, error)
+	Save(ms int64) error
+}
+
+// FileStateStore is a StateStore keeping the mark as decimal text in
+// one file. Save writes a temporary file next to it and renames it
+// over the old one, so a crash never leaves a partial mark.
+type FileStateStore struct {
+	path string
+}
+
+// NewFileStateStore returns a FileStateStore for path. The file is
+// created by the first Save; its directory must exist.
+func NewFileStateStore(path string) *FileStateStore {
+	return &FileStateStore{path: path}
+}
+
+// Load implements StateStore. A missing file reads as 0.
+func (s *FileStateStore) Load() (int64, error) {
+	b, err := os.ReadFile(s.path)
+	if os.IsNotExist(err) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, err
+	}
+	ms, err := strconv.ParseInt(strings.TrimSpace(string(b)), 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("state file %s: %w", s.path, err)
+	}
+	return ms, nil
+}
+
+// Save implements StateStore.
+func (s *FileStateStore) Save(ms int64) error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".tmp*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.WriteString(strconv.FormatInt(ms, 10) + "\n"); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Sync(); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// loadState sets up cfg's state store and resumes after its mark: the
+// mark's tick is marked exhausted, so the next ID has a later
+// timestamp.
+func (g *Generator) loadState(cfg *Config) error {
+	g.store = cfg.StateStore
+	if cfg.StateFile != "" {
+		g.store = NewFileStateStore(cfg.StateFile)
+	}
+	if g.store == nil {
+		return nil
+	}
+	mark, err := g.store.Load()
+	if err != nil {
+		return fmt.Errorf("loading state: %w", err)
+	}
+	g.stateMark = mark
+	if tick := (mark - g.baseEpoch) / g.unit; tick > g.lastMs {
+		g.lastMs = tick
+		g.markTick = tick
+		g.seq = uint32(g.layout.MaxSequence())
+		if g.overflow {
+			g.ovf = uint16(g.layout.MaxSalt())
+		}
+	}
+	return nil
+}
+
+// saveState moves the persisted mark ahead of tick nowMs unless it
+// already covers it or no store is configured. On failure the mark
+// stays put, so the next tick tries again. It must be called with g.mu
+// held.
+func (g *Generator) saveState(nowMs int64) {
+	if g.store == nil || g.baseEpoch+nowMs*g.unit+g.unit-1 <= g.stateMark {
+		return
+	}
+	mark := g.baseEpoch + nowMs*g.unit + g.unit - 1 + stateAheadMs
+	if err := g.store.Save(mark); err == nil {
+		g.stateMark = mark
+	}
+}
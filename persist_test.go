@@ -0,0 +1,111 @@
+package uniqid
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// failingStore is a StateStore whose writes fail.
+type failingStore struct{ saves int }
+
+func (s *failingStore) Load() (int64, error) { return 0, nil }
+func (s *failingStore) Save(int64) error {
+	s.saves++
+	return errors.New("disk full")
+}
+
+// TestStateStore tests persisting the timestamp high-water mark
+func TestStateStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "uniqid.state")
+	mockTime := time.Now().UnixMilli()
+	newGen := func(cfg *Config) *Generator {
+		t.Helper()
+		cfg.ShardID = 1
+		gen, err := New(cfg)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		gen.deps.nowFunc = func() int64 { return mockTime }
+		return gen
+	}
+
+	// Test case 1: The first ID persists a mark ahead of its timestamp
+	gen := newGen(&Config{StateFile: path})
+	gen.Next()
+	mark, err := NewFileStateStore(path).Load()
+	if err != nil || mark != mockTime+stateAheadMs {
+		t.Fatalf("Expected mark %d, got %d, %v", mockTime+stateAheadMs, mark, err)
+	}
+	// IDs within the mark do not write again
+	mockTime += 500
+	os.Remove(path)
+	gen.Next()
+	if _, err := os.Stat(path); !os.IsNotExist(err) {
+		t.Error("Expected no write before the mark is reached")
+	}
+	mockTime += 600
+	gen.Next()
+	if mark, _ := NewFileStateStore(path).Load(); mark != mockTime+stateAheadMs {
+		t.Errorf("Expected the mark to move past %d, got %d", mockTime, mark)
+	}
+
+	// Test case 2: After a restart with the clock set back, IDs resume after the mark
+	mark, _ = NewFileStateStore(path).Load()
+	mockTime -= 5000
+	gen = newGen(&Config{StateFile: path})
+	if _, err := gen.TryNext(); err != ErrSequenceExhausted {
+		t.Errorf("Expected no ID before the mark, got %v", err)
+	}
+	if _, err := newGen(&Config{StateFile: path, ClockDriftPolicy: ClockDriftError}).NextE(); err != ErrClockBackwards {
+		t.Errorf("Expected ErrClockBackwards, got %v", err)
+	}
+	mockTime = mark + 1
+	if p, err := gen.Parse(gen.Next()); err != nil || p.Time.UnixMilli() != mark+1 {
+		t.Errorf("Expected the first ID after the mark %d, got %v, %v", mark, p.Time.UnixMilli(), err)
+	}
+
+	// Test case 3: Failed writes are retried on the next tick
+	store := &failingStore{}
+	gen = newGen(&Config{StateStore: store})
+	gen.Next()
+	gen.Next()
+	mockTime++
+	gen.Next()
+	if store.saves != 2 {
+		t.Errorf("Expected 2 save attempts, got %d", store.saves)
+	}
+
+	// Test case 4: NextForKey moves the mark, and its shards resume after it
+	os.Remove(path)
+	gen = newGen(&Config{StateFile: path})
+	key := []byte("user-0")
+	for k := 1; gen.shardForKey(key) == gen.shard; k++ {
+		key = fmt.Appendf(nil, "user-%d", k)
+	}
+	gen.NextForKey(key)
+	if mark, _ = NewFileStateStore(path).Load(); mark != mockTime+stateAheadMs {
+		t.Errorf("Expected NextForKey to persist mark %d, got %d", mockTime+stateAheadMs, mark)
+	}
+	gen = newGen(&Config{StateFile: path})
+	now := mark
+	gen.deps.nowFunc = func() int64 { now++; return now - 1 }
+	if p, err := gen.Parse(gen.NextForKey(key)); err != nil || p.Time.UnixMilli() <= mark {
+		t.Errorf("Expected a keyed ID after the mark %d, got %v, %v", mark, p.Time.UnixMilli(), err)
+	}
+
+	// Test case 5: Invalid combinations and unreadable state fail New
+	os.WriteFile(path, []byte("garbage"), 0o644)
+	for _, bad := range []*Config{
+		{StateFile: path},
+		{StateFile: path, StateStore: store},
+		{StateStore: store, LockFree: true},
+	} {
+		if _, err := New(bad); err == nil {
+			t.Errorf("Expected error for %+v, got nil", bad)
+		}
+	}
+}
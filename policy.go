@@ -0,0 +1,205 @@
+package uniqid
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"runtime"
+	"time"
+)
+
+// ErrClockBackwards is returned by NextE under ClockDriftError when the
+// clock reads earlier than the timestamp of the last ID by more than
+// Config.MaxClockDriftMs.
+var ErrClockBackwards = errors.New("clock moved backwards")
+
+// OverflowPolicy selects what happens when the sequence for the
+// current millisecond runs out; see Config.OverflowPolicy.
+type OverflowPolicy int
+
+const (
+	// OverflowSpin polls the clock until the next millisecond. This is
+	// the default.
+	OverflowSpin OverflowPolicy = iota
+	// OverflowSleep sleeps until the next millisecond.
+	OverflowSleep
+	// OverflowError makes NextE return ErrSequenceExhausted.
+	OverflowError
+)
+
+// WaitStrategy selects how a generator waits for the next millisecond
+// when the sequence runs out; see Config.WaitStrategy.
+type WaitStrategy int
+
+const (
+	// WaitSpinSleep polls the clock, yielding the processor and
+	// sleeping Config.SpinSleep between polls. This is the default.
+	WaitSpinSleep WaitStrategy = iota
+	// WaitBusySpin polls the clock without yielding or sleeping.
+	WaitBusySpin
+	// WaitYield polls the clock, only yielding between polls.
+	WaitYield
+	// WaitBackoff polls the clock, sleeping between polls for
+	// Config.SpinSleep at first and twice as long after each poll.
+	WaitBackoff
+	// WaitTimer sleeps until the next millisecond is due.
+	WaitTimer
+)
+
+// ClockDriftPolicy selects what NextE does when the clock moves
+// backwards; see Config.ClockDriftPolicy.
+type ClockDriftPolicy int
+
+const (
+	// ClockDriftTolerate keeps issuing IDs at the last timestamp until
+	// the clock catches up. This is the default.
+	ClockDriftTolerate ClockDriftPolicy = iota
+	// ClockDriftError makes NextE return ErrClockBackwards.
+	ClockDriftError
+)
+
+// NextE is like Next but reports the conditions Next handles silently,
+// as selected by Config.OverflowPolicy and Config.ClockDriftPolicy:
+// ErrSequenceExhausted under OverflowError, and ErrClockBackwards
+// under ClockDriftError. It also returns ErrTooManyBanned when
+// Config.BannedSubstrings rejects too many IDs in a row, and
+// ErrBeforeEpoch under OverflowError before the epoch. With the
+// default policies and no banned substrings it never fails.
+// Clock regressions are counted in Stats.ClockBackwards either way;
+// a clock before the epoch is not a regression.
+//
+// Example:
+//
+//	id, err := gen.NextE()
+//	if errors.Is(err, uniqid.ErrClockBackwards) {
+//	    alert("clock regression on " + host)
+//	}
+func (g *Generator) NextE() (string, error) {
+	g.mu.Lock()
+	nowMs := g.tick()
+	if lastMs, _ := g.position(); g.drift == ClockDriftError && !beforeEpoch(nowMs, lastMs) && g.clockDrift(nowMs, lastMs) {
+		g.stats.ClockBackwards++
+		g.mu.Unlock()
+		return "", ErrClockBackwards
+	}
+	val, err := g.nextLocked(g.policy != OverflowError, nowMs)
+	g.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+	return g.format(val), nil
+}
+
+// NextCtx is like Next but gives up when ctx is done while waiting for
+// the next millisecond, returning ctx.Err(). Waits happen when the
+// sequence runs out, before the epoch, and for as long as the clock
+// lags behind the last ID once the last timestamp's sequence is used
+// up, which can take a while after a large clock step backwards. It
+// also returns ctx.Err()
+// without generating if ctx is already done.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(ctx, 5*time.Millisecond)
+//	defer cancel()
+//	id, err := gen.NextCtx(ctx)
+func (g *Generator) NextCtx(ctx context.Context) (string, error) {
+	for {
+		if err := ctx.Err(); err != nil {
+			return "", err
+		}
+		g.mu.Lock()
+		nowMs := g.tick()
+		val, err := g.nextLocked(false, nowMs)
+		if err != ErrSequenceExhausted && err != ErrBeforeEpoch {
+			g.mu.Unlock()
+			if err != nil {
+				return "", err
+			}
+			return g.format(val), nil
+		}
+		// Wait for the epoch, or for the last millisecond of the
+		// exhausted tick to pass.
+		target := int64(-1)
+		if err == ErrSequenceExhausted {
+			g.stats.Rollovers++
+			lastMs, _ := g.position()
+			target = max(nowMs, lastMs)*g.unit + g.unit - 1
+		}
+		nowFunc := g.deps.nowFunc
+		g.mu.Unlock()
+		for nowFunc()-g.baseEpoch <= target {
+			select {
+			case <-ctx.Done():
+				return "", ctx.Err()
+			default:
+			}
+			runtime.Gosched()
+			if g.spinSleep > 0 {
+				time.Sleep(g.spinSleep)
+			}
+		}
+	}
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// clockDrift reports whether the clock reading nowMs lags lastMs, both
+// in timestamp ticks, by more than Config.MaxClockDriftMs, and calls
+// Config.OnClockDrift the first time it does until the clock catches
+// up again.
+func (g *Generator) clockDrift(nowMs, lastMs int64) bool {
+	drift := (lastMs - nowMs) * g.unit
+	if drift <= g.maxDrift {
+		return false
+	}
+	if g.onDrift != nil && !g.driftSeen.Swap(true) {
+		g.onDrift(time.Duration(drift) * time.Millisecond)
+	}
+	return true
+}
+
+// waitFunc blocks until the clock, read with nowFunc and relative to
+// baseEpoch, is past lastMs. sleep is the resolved Config.SpinSleep.
+type waitFunc func(baseEpoch, lastMs int64, nowFunc func() int64, sleep time.Duration)
+
+// waitFor returns the waitFunc for strategy under policy.
+func waitFor(strategy WaitStrategy, policy OverflowPolicy) waitFunc {
+	switch {
+	case strategy == WaitTimer || policy == OverflowSleep:
+		return sleepUntilNextMs
+	case strategy == WaitBusySpin:
+		return busyWaitUntilNextMs
+	case strategy == WaitYield:
+		return func(baseEpoch, lastMs int64, nowFunc func() int64, _ time.Duration) {
+			spinUntilNextMs(baseEpoch, lastMs, nowFunc, 0)
+		}
+	case strategy == WaitBackoff:
+		return backoffUntilNextMs
+	default:
+		return spinUntilNextMs
+	}
+}
+
+// busyWaitUntilNextMs polls the clock in a tight loop.
+func busyWaitUntilNextMs(baseEpoch, lastMs int64, nowFunc func() int64, _ time.Duration) {
+	for nowFunc()-baseEpoch <= lastMs {
+	}
+}
+
+// backoffUntilNextMs sleeps between polls, starting at sleep (or
+// defaultSpinSleep if that is zero) and doubling each time, but never
+// past the time the next millisecond is due.
+func backoffUntilNextMs(baseEpoch, lastMs int64, nowFunc func() int64, sleep time.Duration) {
+	sleep = cmp.Or(sleep, defaultSpinSleep)
+	for {
+		now := nowFunc() - baseEpoch
+		if now > lastMs {
+			return
+		}
+		time.Sleep(min(sleep, time.Duration(lastMs+1-now)*time.Millisecond))
+		sleep *= 2
+	}
+}
@@ -0,0 +1,232 @@
+package uniqid
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+)
+
+// TestNextE tests surfacing overflow and clock drift as errors
+func TestNextE(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	layout := Layout{TimestampBits: 39, ShardBits: 10, SequenceBits: 2}
+	newGen := func(cfg *Config) *Generator {
+		t.Helper()
+		cfg.ShardID, cfg.Layout = 1, layout
+		gen, err := New(cfg)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		gen.deps.nowFunc = func() int64 { return mockTime }
+		return gen
+	}
+
+	// Test case 1: Default policies behave like Next
+	gen := newGen(&Config{})
+	for i := 0; i < 2; i++ {
+		if _, err := gen.NextE(); err != nil {
+			t.Fatalf("NextE failed: %v", err)
+		}
+	}
+	mockTime -= 5
+	if _, err := gen.NextE(); err != nil {
+		t.Errorf("Expected drift to be tolerated, got %v", err)
+	}
+	mockTime += 5
+
+	// Test case 2: OverflowError reports exhaustion
+	mockTime++
+	gen = newGen(&Config{OverflowPolicy: OverflowError})
+	for i := 0; i < 4; i++ {
+		if _, err := gen.NextE(); err != nil {
+			t.Fatalf("NextE %d failed: %v", i, err)
+		}
+	}
+	if _, err := gen.NextE(); err != ErrSequenceExhausted {
+		t.Errorf("Expected ErrSequenceExhausted, got %v", err)
+	}
+	mockTime++
+	if _, err := gen.NextE(); err != nil {
+		t.Errorf("Expected NextE to recover in the next millisecond, got %v", err)
+	}
+
+	// Test case 3: ClockDriftError reports regressions without issuing
+	gen = newGen(&Config{ClockDriftPolicy: ClockDriftError})
+	last, _ := gen.NextE()
+	mockTime -= 3
+	if _, err := gen.NextE(); err != ErrClockBackwards {
+		t.Errorf("Expected ErrClockBackwards, got %v", err)
+	}
+	if s := gen.Stats(); s.ClockBackwards != 1 || s.Generated != 1 {
+		t.Errorf("Unexpected stats: %+v", s)
+	}
+	mockTime += 3
+	if id, err := gen.NextE(); err != nil || id == last {
+		t.Errorf("Expected a new ID once the clock recovers, got %q, %v", id, err)
+	}
+
+	// Test case 4: OverflowSleep waits for the next millisecond
+	gen = newGen(&Config{OverflowPolicy: OverflowSleep})
+	gen.deps.nowFunc = func() int64 { return time.Now().UnixMilli() }
+	for i := 0; i < 9; i++ {
+		if _, err := gen.NextE(); err != nil {
+			t.Fatalf("NextE failed: %v", err)
+		}
+	}
+	if s := gen.Stats(); s.Rollovers == 0 {
+		t.Error("Expected OverflowSleep to wait for the next millisecond")
+	}
+
+	// Test case 5: Unknown policies are rejected
+	for _, bad := range []*Config{{OverflowPolicy: 7}, {ClockDriftPolicy: -1}} {
+		if _, err := New(bad); err == nil {
+			t.Errorf("Expected error for %+v, got nil", bad)
+		}
+	}
+}
+
+// TestWaitStrategy tests each way of waiting for the next millisecond
+func TestWaitStrategy(t *testing.T) {
+	// Test case 1: Every strategy returns once the clock passes lastMs
+	for _, strategy := range []WaitStrategy{WaitSpinSleep, WaitBusySpin, WaitYield, WaitBackoff, WaitTimer} {
+		var polls int64
+		nowFunc := func() int64 { polls++; return 100 + polls/3 }
+		waitFor(strategy, OverflowSpin)(0, 101, nowFunc, time.Microsecond)
+		if now := 100 + polls/3; now != 102 {
+			t.Errorf("Strategy %d: expected to return at 102, clock reads %d", strategy, now)
+		}
+	}
+
+	// Test case 2: Generators with each strategy keep IDs unique across rollovers
+	layout := Layout{TimestampBits: 39, ShardBits: 10, SequenceBits: 2}
+	for _, strategy := range []WaitStrategy{WaitBusySpin, WaitYield, WaitBackoff, WaitTimer} {
+		gen, err := New(&Config{ShardID: 1, Layout: layout, WaitStrategy: strategy})
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		seen := make(map[string]bool)
+		for i := 0; i < 12; i++ {
+			id := gen.Next()
+			if seen[id] {
+				t.Fatalf("Strategy %d: duplicate ID %q", strategy, id)
+			}
+			seen[id] = true
+		}
+		if s := gen.Stats(); s.Rollovers == 0 {
+			t.Errorf("Strategy %d: expected rollovers, got %+v", strategy, s)
+		}
+	}
+
+	// Test case 3: Unknown strategies and conflicts with OverflowSleep are rejected
+	for _, bad := range []*Config{
+		{WaitStrategy: -1},
+		{WaitStrategy: 9},
+		{WaitStrategy: WaitBusySpin, OverflowPolicy: OverflowSleep},
+	} {
+		if _, err := New(bad); err == nil {
+			t.Errorf("Expected error for %+v, got nil", bad)
+		}
+	}
+}
+
+// TestClockDriftThreshold tests the drift threshold and callback
+func TestClockDriftThreshold(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	var drifts []time.Duration
+	gen, err := New(&Config{
+		ShardID:          1,
+		ClockDriftPolicy: ClockDriftError,
+		MaxClockDriftMs:  10,
+		OnClockDrift:     func(d time.Duration) { drifts = append(drifts, d) },
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	gen.deps.nowFunc = func() int64 { return mockTime }
+	gen.Next()
+
+	// Test case 1: Regressions within the threshold are tolerated silently
+	mockTime -= 10
+	if _, err := gen.NextE(); err != nil {
+		t.Errorf("Expected a small regression to be tolerated, got %v", err)
+	}
+	if len(drifts) != 0 {
+		t.Errorf("Expected no callback, got %v", drifts)
+	}
+
+	// Test case 2: Larger regressions call the hook once and apply the policy
+	mockTime -= 40
+	if _, err := gen.NextE(); err != ErrClockBackwards {
+		t.Errorf("Expected ErrClockBackwards, got %v", err)
+	}
+	gen.Next()
+	gen.Next()
+	if len(drifts) != 1 || drifts[0] != 50*time.Millisecond {
+		t.Errorf("Expected one 50ms drift report, got %v", drifts)
+	}
+
+	// Test case 3: A later regression is reported again once the clock caught up
+	mockTime += 51
+	gen.Next()
+	mockTime -= 20
+	gen.Next()
+	if len(drifts) != 2 || drifts[1] != 20*time.Millisecond {
+		t.Errorf("Expected a second 20ms drift report, got %v", drifts)
+	}
+
+	// Test case 4: A negative threshold is rejected
+	if _, err := New(&Config{MaxClockDriftMs: -1}); err == nil {
+		t.Error("Expected error for a negative MaxClockDriftMs, got nil")
+	}
+}
+
+// TestNextCtx tests cancelling the wait for the next millisecond
+func TestNextCtx(t *testing.T) {
+	var mockTime atomic.Int64
+	mockTime.Store(time.Now().UnixMilli())
+	gen, err := New(&Config{ShardID: 1, Layout: Layout{TimestampBits: 39, ShardBits: 10, SequenceBits: 1}})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	gen.deps.nowFunc = mockTime.Load
+
+	// Test case 1: IDs are generated while the sequence lasts
+	ctx := context.Background()
+	for i := 0; i < 2; i++ {
+		if _, err := gen.NextCtx(ctx); err != nil {
+			t.Fatalf("NextCtx failed: %v", err)
+		}
+	}
+
+	// Test case 2: A frozen clock makes the deadline expire
+	short, cancel := context.WithTimeout(ctx, 10*time.Millisecond)
+	defer cancel()
+	if _, err := gen.NextCtx(short); err != context.DeadlineExceeded {
+		t.Errorf("Expected context.DeadlineExceeded, got %v", err)
+	}
+
+	// Test case 3: The wait ends when the clock moves on
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		mockTime.Add(1)
+	}()
+	id, err := gen.NextCtx(ctx)
+	if err != nil {
+		t.Fatalf("NextCtx failed: %v", err)
+	}
+	if p, _ := gen.Parse(id); p.Time.UnixMilli() != mockTime.Load() || p.Seq != 0 {
+		t.Errorf("Unexpected parts after wait: %+v", p)
+	}
+
+	// Test case 4: A cancelled context generates nothing
+	done, cancelDone := context.WithCancel(ctx)
+	cancelDone()
+	before := gen.Stats().Generated
+	if _, err := gen.NextCtx(done); err != context.Canceled {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	if gen.Stats().Generated != before {
+		t.Error("Expected no ID to be generated with a cancelled context")
+	}
+}
@@ -0,0 +1,58 @@
+package uniqid
+
+import "math/rand/v2"
+
+// GeneratorPool spreads ID generation over several shards owned by the
+// same process, like MultiGenerator, but picks the shard for each call
+// so that concurrent goroutines rarely wait for one another: a call
+// takes the first generator it can lock without blocking, starting
+// from a random one, and only waits if all of them are busy. With at
+// least as many shards as runtime.GOMAXPROCS, throughput keeps growing
+// with cores instead of plateauing on a single generator's lock.
+// It is safe for concurrent use by multiple goroutines.
+//
+// IDs are unique and time-sortable per shard; IDs from different
+// shards within the same millisecond are in no particular order.
+type GeneratorPool struct {
+	*MultiGenerator
+}
+
+// NewGeneratorPool creates a GeneratorPool owning the given shards.
+// cfg and shards are handled as by NewMultiShard, including the shard
+// reservation released by Close.
+//
+// Example:
+//
+//	shards := make([]int, runtime.GOMAXPROCS(0))
+//	for i := range shards {
+//	    shards[i] = 64 + i
+//	}
+//	pool, err := uniqid.NewGeneratorPool(nil, shards)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer pool.Close()
+//	id := pool.Next()
+func NewGeneratorPool(cfg *Config, shards []int) (*GeneratorPool, error) {
+	mg, err := NewMultiShard(cfg, shards)
+	if err != nil {
+		return nil, err
+	}
+	return &GeneratorPool{mg}, nil
+}
+
+// Next returns a new unique ID from a generator no other goroutine is
+// using, waiting only if every generator is busy.
+func (p *GeneratorPool) Next() string {
+	n := len(p.gens)
+	start := rand.IntN(n)
+	for i := range n {
+		g := p.gens[(start+i)%n]
+		if g.mu.TryLock() {
+			val := g.nextWaitLocked(g.tick())
+			g.mu.Unlock()
+			return g.format(val)
+		}
+	}
+	return p.gens[start].Next()
+}
@@ -0,0 +1,72 @@
+package uniqid
+
+import (
+	"runtime"
+	"sync"
+	"testing"
+)
+
+// TestGeneratorPool tests generating IDs from a pool of shards
+func TestGeneratorPool(t *testing.T) {
+	pool, err := NewGeneratorPool(nil, []int{20, 21, 22, 23})
+	if err != nil {
+		t.Fatalf("NewGeneratorPool failed: %v", err)
+	}
+	defer pool.Close()
+
+	// Test case 1: Concurrent callers get unique IDs from the owned shards
+	const workers, perWorker = 8, 10000
+	var (
+		mu   sync.Mutex
+		seen = make(map[string]struct{}, workers*perWorker)
+		wg   sync.WaitGroup
+	)
+	for w := 0; w < workers; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			ids := make([]string, perWorker)
+			for i := range ids {
+				ids[i] = pool.Next()
+			}
+			mu.Lock()
+			for _, id := range ids {
+				seen[id] = struct{}{}
+			}
+			mu.Unlock()
+		}()
+	}
+	wg.Wait()
+	if len(seen) != workers*perWorker {
+		t.Errorf("Generated duplicate IDs, expected %d unique, got %d", workers*perWorker, len(seen))
+	}
+	for id := range seen {
+		p, err := Parse(id)
+		if err != nil || p.Shard < 20 || p.Shard > 23 {
+			t.Fatalf("Unexpected ID %q: %+v, %v", id, p, err)
+		}
+	}
+
+	// Test case 2: Shards are reserved like NewMultiShard's
+	if _, err := NewGeneratorPool(nil, []int{21}); err == nil {
+		t.Error("Expected error for a reserved shard, got nil")
+	}
+	if _, err := NewGeneratorPool(nil, nil); err == nil {
+		t.Error("Expected error for no shards, got nil")
+	}
+}
+
+func BenchmarkNextParallelPool(b *testing.B) {
+	shards := make([]int, runtime.GOMAXPROCS(0))
+	for i := range shards {
+		shards[i] = 100 + i
+	}
+	pool, _ := NewGeneratorPool(nil, shards)
+	defer pool.Close()
+	b.ResetTimer()
+	b.RunParallel(func(pb *testing.PB) {
+		for pb.Next() {
+			_ = pool.Next()
+		}
+	})
+}
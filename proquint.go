@@ -0,0 +1,113 @@
+package uniqid
+
+import "strings"
+
+// NextProquint generates a new ID rendered as proquints: pronounceable
+// five-letter words of alternating consonants and vowels, one per 16
+// bits of the packed value, joined by hyphens, e.g.
+// "lusab-babad-kuzib-tomur" for a 64-bit layout. They are easier to
+// read out over a call or recognize at a glance than the default
+// encoding. The value is the one Next would encode, obfuscated first
+// with Config.ObfuscationKey, and Config.Prefix is prepended;
+// VersionPrefix, Checksum and SigningKey apply only to Next's format.
+//
+// Proquints do not sort in generation order. ParseProquint decodes
+// them.
+//
+// Example:
+//
+//	id := gen.NextProquint() // "lusab-babad-kuzib-tomur"
+func (g *Generator) NextProquint() string {
+	val, _ := g.next(true)
+	if len(g.obfKey) > 0 {
+		val = permute(g.obfKey, val, g.layout.Bits(), false)
+	}
+	words := g.proquints()
+	dst := make([]byte, 0, len(g.prefix)+6*words-1)
+	dst = append(dst, g.prefix...)
+	for i := words - 1; i >= 0; i-- {
+		dst = appendProquint(dst, uint16(val>>(16*i)))
+		if i > 0 {
+			dst = append(dst, '-')
+		}
+	}
+	return string(dst)
+}
+
+// ParseProquint decomposes an ID produced by NextProquint, by g or any
+// generator sharing its format settings, into its components. Letter
+// case is ignored. The salt is checked as by Parse.
+func (g *Generator) ParseProquint(s string) (Parts, error) {
+	s, ok := strings.CutPrefix(s, g.prefix)
+	words := g.proquints()
+	if !ok || len(s) != 6*words-1 {
+		return Parts{}, ErrInvalidID
+	}
+	var val uint64
+	for i := 0; i < words; i++ {
+		if i > 0 && s[6*i-1] != '-' {
+			return Parts{}, ErrInvalidID
+		}
+		w, ok := parseProquint(s[6*i : 6*i+5])
+		if !ok {
+			return Parts{}, ErrInvalidID
+		}
+		val = val<<16 | uint64(w)
+	}
+	if bits := g.layout.Bits(); bits < 64 && val>>bits != 0 {
+		return Parts{}, ErrInvalidID
+	}
+	if len(g.obfKey) > 0 {
+		val = permute(g.obfKey, val, g.layout.Bits(), true)
+	}
+	return g.partsOf(val)
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// Proquint letters: a consonant encodes 4 bits and a vowel 2.
+const (
+	proquintConsonants = "bdfghjklmnprstvz"
+	proquintVowels     = "aiou"
+)
+
+// proquints returns the number of words in g's proquint IDs.
+func (g *Generator) proquints() int {
+	return (g.layout.Bits() + 15) / 16
+}
+
+// appendProquint appends the word for w, consonant-vowel-consonant-
+// vowel-consonant from the most significant bits.
+func appendProquint(dst []byte, w uint16) []byte {
+	return append(dst,
+		proquintConsonants[w>>12&0xF],
+		proquintVowels[w>>10&0x3],
+		proquintConsonants[w>>6&0xF],
+		proquintVowels[w>>4&0x3],
+		proquintConsonants[w&0xF],
+	)
+}
+
+// parseProquint converts a five-letter word back to its 16 bits,
+// ignoring case.
+func parseProquint(word string) (uint16, bool) {
+	var w uint16
+	for i := 0; i < len(word); i++ {
+		set, bits := proquintConsonants, 4
+		if i%2 == 1 {
+			set, bits = proquintVowels, 2
+		}
+		c := word[i]
+		if 'A' <= c && c <= 'Z' {
+			c += 'a' - 'A'
+		}
+		v := strings.IndexByte(set, c)
+		if v < 0 {
+			return 0, false
+		}
+		w = w<<bits | uint16(v)
+	}
+	return w, true
+}
@@ -0,0 +1,54 @@
+package uniqid
+
+import (
+	"strings"
+	"testing"
+)
+
+// TestProquint tests rendering and parsing IDs as proquints
+func TestProquint(t *testing.T) {
+	// Test case 1: Words match the proquint specification's examples
+	for w, want := range map[uint16]string{0x7F00: "lusab", 0x0001: "babad", 0x3F54: "gutih", 0xDCC1: "tugad"} {
+		if got := string(appendProquint(nil, w)); got != want {
+			t.Errorf("appendProquint(%#04x) = %q, want %q", w, got, want)
+		}
+		if got, ok := parseProquint(strings.ToUpper(want)); !ok || got != w {
+			t.Errorf("parseProquint(%q) = %#04x, %v", want, got, ok)
+		}
+	}
+
+	// Test case 2: IDs round-trip, with a prefix and obfuscation
+	for _, cfg := range []*Config{
+		{ShardID: 7},
+		{ShardID: 7, Prefix: "ord_", ObfuscationKey: []byte("0123456789abcdef")},
+	} {
+		gen, err := New(cfg)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		prev := Parts{}
+		for i := 0; i < 100; i++ {
+			id := gen.NextProquint()
+			if !strings.HasPrefix(id, cfg.Prefix) || len(id) != len(cfg.Prefix)+23 {
+				t.Fatalf("Unexpected proquint ID %q", id)
+			}
+			p, err := gen.ParseProquint(id)
+			if err != nil || p.Shard != 7 || p.Time.Before(prev.Time) || (p.Time.Equal(prev.Time) && p.Seq <= prev.Seq) {
+				t.Fatalf("ParseProquint(%q) = %+v, %v after %+v", id, p, err, prev)
+			}
+			if up, err := gen.ParseProquint(cfg.Prefix + strings.ToUpper(id[len(cfg.Prefix):])); err != nil || up != p {
+				t.Errorf("Expected upper case to parse as %+v, got %+v, %v", p, up, err)
+			}
+			prev = p
+		}
+	}
+
+	// Test case 3: Malformed IDs and values wider than the layout are
+	// rejected
+	gen, _ := New(&Config{ShardID: 7, Layout: Layout{TimestampBits: 41, ShardBits: 6, SequenceBits: 12}})
+	for _, bad := range []string{"", "lusab-babad", "lusab-babad-lusab-babax", "lusab_babad_lusab_babad", "zusab-babad-lusab-babad"} {
+		if _, err := gen.ParseProquint(bad); err != ErrInvalidID {
+			t.Errorf("ParseProquint(%q) = %v, want ErrInvalidID", bad, err)
+		}
+	}
+}
@@ -0,0 +1,62 @@
+package uniqid
+
+import (
+	"crypto/rand"
+	"fmt"
+)
+
+// maxRandomLen is the longest ID NextRandom generates.
+const maxRandomLen = 256
+
+// NextRandom returns a random, non-sortable ID of length characters
+// from the default alphabet, read from crypto/rand, in the style of
+// NanoID. Unlike Next it carries no timestamp, shard or sequence, so
+// it leaks nothing about when or where it was made; use it for session
+// tokens, API keys and other secrets. Each character holds 6 random
+// bits, so 22 characters give 132 bits. Uniqueness is probabilistic:
+// choose a length whose collision odds suit the number of IDs.
+//
+// It returns an error if length is not in [1, 256] or crypto/rand
+// fails.
+//
+// Example:
+//
+//	key, err := uniqid.NextRandom(32)
+func NextRandom(length int) (string, error) {
+	return randomID(defaultCodec, rand.Read, length)
+}
+
+// NextRandom is like the package-level NextRandom but uses g's
+// alphabet or encoding, and g's random source (Config.RandReader or
+// crypto/rand), and puts g's Config.Prefix in front, e.g. "sk_".
+// length excludes the prefix. With EncodingCrockford each character
+// holds 5 bits. No ID is consumed from g's sequence.
+func (g *Generator) NextRandom(length int) (string, error) {
+	id, err := randomID(g.codec, g.deps.randFunc, length)
+	if err != nil {
+		return "", err
+	}
+	return g.prefix + id, nil
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// randomID returns length characters of c's alphabet chosen uniformly
+// with bytes from read. The alphabet size is a power of two, so
+// masking each byte introduces no bias.
+func randomID(c *codec, read func([]byte) (int, error), length int) (string, error) {
+	if length < 1 || length > maxRandomLen {
+		return "", fmt.Errorf("random ID length must be in [1, %d], got %d", maxRandomLen, length)
+	}
+	buf := make([]byte, length)
+	if _, err := read(buf); err != nil {
+		return "", err
+	}
+	mask := byte(len(c.alphabet) - 1)
+	for i, b := range buf {
+		buf[i] = c.alphabet[b&mask]
+	}
+	return string(buf), nil
+}
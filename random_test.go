@@ -0,0 +1,66 @@
+package uniqid
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+)
+
+// TestNextRandom tests generating random, non-sortable IDs
+func TestNextRandom(t *testing.T) {
+	// Test case 1: IDs have the requested length and alphabet, and differ
+	seen := make(map[string]bool)
+	for i := 0; i < 1000; i++ {
+		id, err := NextRandom(21)
+		if err != nil {
+			t.Fatalf("NextRandom failed: %v", err)
+		}
+		if len(id) != 21 || strings.Trim(id, alphabet) != "" {
+			t.Fatalf("Unexpected ID %q", id)
+		}
+		if seen[id] {
+			t.Fatalf("Duplicate random ID %q", id)
+		}
+		seen[id] = true
+	}
+
+	// Test case 2: Every alphabet character turns up
+	id, _ := NextRandom(maxRandomLen)
+	all := id
+	for i := 0; i < 10; i++ {
+		id, _ = NextRandom(maxRandomLen)
+		all += id
+	}
+	for _, c := range alphabet {
+		if !strings.ContainsRune(all, c) {
+			t.Errorf("Character %q never generated", c)
+		}
+	}
+
+	// Test case 3: Generators use their encoding, prefix and random source
+	gen, err := New(&Config{
+		ShardID:    1,
+		Encoding:   EncodingCrockford,
+		Prefix:     "sk_",
+		RandReader: bytes.NewReader(bytes.Repeat([]byte{0x21}, 64)),
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if id, err := gen.NextRandom(4); err != nil || id != "sk_1111" {
+		t.Errorf("NextRandom = %q, %v, want \"sk_1111\"", id, err)
+	}
+	if s := gen.Stats(); s.Generated != 0 {
+		t.Errorf("Expected no sequence slot to be used, got %d", s.Generated)
+	}
+
+	// Test case 4: Invalid lengths and failing sources
+	for _, n := range []int{0, -1, maxRandomLen + 1} {
+		if _, err := NextRandom(n); err == nil {
+			t.Errorf("NextRandom(%d): expected error, got nil", n)
+		}
+	}
+	if _, err := gen.NextRandom(64); err == nil {
+		t.Error("Expected error from an exhausted random source, got nil")
+	}
+}
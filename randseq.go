@@ -0,0 +1,17 @@
+package uniqid
+
+import "math/rand/v2"
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// firstSeq returns the sequence number of a tick's first ID: 0, or
+// with Config.RandomizeSequence a random offset in the lower half of
+// the range, leaving at least half the range for the tick.
+func (g *Generator) firstSeq() uint32 {
+	if !g.randSeq {
+		return 0
+	}
+	return rand.Uint32N(uint32(g.layout.MaxSequence()+1) / 2)
+}
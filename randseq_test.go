@@ -0,0 +1,67 @@
+package uniqid
+
+import (
+	"testing"
+	"time"
+)
+
+// TestRandomizeSequence tests starting each millisecond at a random sequence
+func TestRandomizeSequence(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	gen, err := New(&Config{ShardID: 1, RandomizeSequence: true})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	// Test case 1: First sequences vary, stay in the lower half and keep increasing
+	starts := make(map[uint16]bool)
+	for ms := 0; ms < 20; ms++ {
+		mockTime++
+		var prev uint16
+		for i, id := range gen.NextN(5) {
+			p, _ := Parse(id)
+			if i == 0 {
+				if p.Seq >= 1<<14 {
+					t.Fatalf("First sequence %d outside the lower half", p.Seq)
+				}
+				starts[p.Seq] = true
+			} else if p.Seq != prev+1 {
+				t.Fatalf("Sequence %d does not follow %d", p.Seq, prev)
+			}
+			prev = p.Seq
+		}
+	}
+	if len(starts) < 10 {
+		t.Errorf("Expected varied first sequences, got %v", starts)
+	}
+
+	// Test case 2: ReserveRange is limited to half a tick
+	if _, err := gen.ReserveRange(1 << 14); err != nil {
+		t.Errorf("ReserveRange of half a tick failed: %v", err)
+	}
+	if _, err := gen.ReserveRange(1<<14 + 1); err == nil {
+		t.Error("Expected ReserveRange beyond half a tick to fail, got nil")
+	}
+
+	// Test case 3: NextForKey and LockFree generators randomize too
+	lf, _ := New(&Config{ShardID: 1, RandomizeSequence: true, LockFree: true})
+	starts = make(map[uint16]bool)
+	for i := 0; i < 20; i++ {
+		lf.deps.nowFunc = func() int64 { return mockTime + int64(i) + 1 }
+		p, _ := Parse(lf.Next())
+		starts[p.Seq] = true
+	}
+	if len(starts) < 10 {
+		t.Errorf("Expected varied LockFree sequences, got %v", starts)
+	}
+	starts = make(map[uint16]bool)
+	for i := 0; i < 20; i++ {
+		mockTime++
+		p, _ := Parse(gen.NextForKey([]byte("user-1")))
+		starts[p.Seq] = true
+	}
+	if len(starts) < 10 {
+		t.Errorf("Expected varied NextForKey sequences, got %v", starts)
+	}
+}
@@ -0,0 +1,81 @@
+package uniqid
+
+import "time"
+
+// Close stops the background shard refresh started by
+// Config.RefreshShardInterval and waits for it to finish, so the shard
+// no longer changes once Close returns. The generator stays usable
+// with its current shard. Close is a no-op for generators without
+// refresh, may be called more than once, and must not be called from
+// Config.OnShardChange.
+func (g *Generator) Close() {
+	if g.stop != nil {
+		g.stopOnce.Do(func() { close(g.stop) })
+		<-g.stopped
+	}
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// refreshShard re-derives the shard every interval until Close is
+// called. A changed shard is switched to at once if the current tick
+// has no IDs yet, and otherwise by nextLocked when the next tick
+// starts. onChange is called once a switch has been observed.
+func (g *Generator) refreshShard(every time.Duration, onChange func(string, uint16, uint16)) {
+	defer close(g.stopped)
+	ticker := time.NewTicker(every)
+	defer ticker.Stop()
+	g.mu.Lock()
+	reported := g.shard
+	g.mu.Unlock()
+	for {
+		select {
+		case <-g.stop:
+			return
+		case <-ticker.C:
+		}
+		select {
+		case <-g.stop:
+			return
+		default:
+		}
+		g.mu.Lock()
+		d := g.deps
+		g.mu.Unlock()
+		shard, err := g.deriveShard(d)
+
+		g.mu.Lock()
+		if err == nil {
+			g.nextShard, g.switching = shard, shard != g.shard
+			if now := g.tick(); g.switching && now > g.lastMs {
+				g.switchShard(now)
+			}
+		}
+		current := g.shard
+		g.mu.Unlock()
+		if current != reported {
+			if onChange != nil {
+				onChange(g.name, reported, current)
+			}
+			reported = current
+		}
+	}
+}
+
+// switchShard moves the generator to the pending shard before the
+// first ID of tick nowMs, so every later ID sorts after the earlier
+// ones. It defers the switch if NextForKey has already issued IDs for
+// that shard at nowMs or later. It must be called with g.mu held.
+func (g *Generator) switchShard(nowMs int64) {
+	if st := g.keyed[g.nextShard]; st != nil && st.lastMs >= nowMs {
+		return
+	}
+	g.shard = g.nextShard
+	g.cfg.ShardID = int(g.shard)
+	// The generator's own sequence supersedes NextForKey's state for
+	// the new shard.
+	delete(g.keyed, g.shard)
+	g.switching = false
+}
@@ -0,0 +1,117 @@
+package uniqid
+
+import (
+	"net"
+	"testing"
+	"time"
+)
+
+// TestRefreshShard tests re-deriving the shard when the network changes
+func TestRefreshShard(t *testing.T) {
+	ifaces := func(mac ...byte) func() ([]net.Interface, error) {
+		return func() ([]net.Interface, error) {
+			return []net.Interface{{Name: "eth0", HardwareAddr: mac}}, nil
+		}
+	}
+	host := func() (string, error) { return "test-host", nil }
+	before, after := ifaces(0x02, 0, 0, 0, 0, 0x01), ifaces(0x02, 0, 0, 0, 0, 0x02)
+	shardBefore, _ := autoShardWithDeps(deps{ifacesFunc: before, hostFunc: host})
+	shardAfter, _ := autoShardWithDeps(deps{ifacesFunc: after, hostFunc: host})
+	if shardBefore == shardAfter {
+		t.Fatalf("Test interfaces derive the same shard %d", shardBefore)
+	}
+
+	changes := make(chan [2]uint16, 16)
+	gen, err := New(&Config{
+		ShardID:              -1,
+		Name:                 "refresh",
+		RefreshShardInterval: time.Millisecond,
+		OnShardChange: func(name string, oldShard, newShard uint16) {
+			if name != "refresh" {
+				t.Errorf("Expected name refresh, got %q", name)
+			}
+			changes <- [2]uint16{oldShard, newShard}
+		},
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer gen.Close()
+	setIfaces := func(f func() ([]net.Interface, error)) {
+		gen.mu.Lock()
+		gen.deps.ifacesFunc, gen.deps.hostFunc = f, host
+		gen.mu.Unlock()
+	}
+	waitShard := func(want uint16) {
+		t.Helper()
+		deadline := time.After(5 * time.Second)
+		for {
+			select {
+			case c := <-changes:
+				if c[1] == want {
+					return
+				}
+			case <-deadline:
+				t.Fatalf("Shard did not change to %d, still %d", want, gen.Config().ShardID)
+			}
+		}
+	}
+
+	// Test case 1: An idle generator switches on the next refresh
+	if gen.Config().ShardID != int(shardBefore) {
+		setIfaces(before)
+		waitShard(shardBefore)
+	}
+
+	// Test case 2: A busy generator switches without duplicates and
+	// keeps its IDs sorted
+	done := make(chan struct{})
+	ids := make(chan []uint64)
+	go func() {
+		var vals []uint64
+		for {
+			select {
+			case <-done:
+				ids <- vals
+				return
+			default:
+			}
+			val, _ := gen.next(true)
+			vals = append(vals, val)
+		}
+	}()
+	time.Sleep(5 * time.Millisecond)
+	setIfaces(after)
+	waitShard(shardAfter)
+	time.Sleep(5 * time.Millisecond)
+	close(done)
+	vals := <-ids
+
+	shards := make(map[uint16]int)
+	for i, val := range vals {
+		if i > 0 && val <= vals[i-1] {
+			t.Fatalf("ID %d does not sort after its predecessor", i)
+		}
+		shards[DefaultLayout.parts(val, defaultEpochMs, 1).Shard]++
+	}
+	if len(shards) != 2 || shards[shardBefore] == 0 || shards[shardAfter] == 0 {
+		t.Errorf("Expected IDs from shards %d and %d, got %v", shardBefore, shardAfter, shards)
+	}
+	if got := gen.Config().ShardID; got != int(shardAfter) {
+		t.Errorf("Expected Config().ShardID %d, got %d", shardAfter, got)
+	}
+
+	// Test case 3: Close stops refreshing
+	gen.Close()
+	gen.Close()
+	setIfaces(before)
+	time.Sleep(10 * time.Millisecond)
+	if got := gen.Config().ShardID; got != int(shardAfter) {
+		t.Errorf("Expected shard %d after Close, got %d", shardAfter, got)
+	}
+
+	// Test case 4: Negative intervals are rejected
+	if _, err := New(&Config{ShardID: -1, RefreshShardInterval: -time.Second}); err == nil {
+		t.Error("Expected error for negative RefreshShardInterval, got nil")
+	}
+}
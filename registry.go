@@ -0,0 +1,69 @@
+package uniqid
+
+import (
+	"errors"
+	"fmt"
+	"sync"
+)
+
+// ErrShardReserved is returned when a shard is already reserved in
+// this process.
+var ErrShardReserved = errors.New("shard already reserved in this process")
+
+// ShardConflictPolicy controls what NewMultiShard does when one of the
+// requested shards is already reserved in this process (see
+// ReserveShard).
+type ShardConflictPolicy int
+
+const (
+	// ShardConflictError fails with ErrShardReserved. This is the
+	// default.
+	ShardConflictError ShardConflictPolicy = iota
+	// ShardConflictSkipOccupied replaces each reserved shard with the
+	// next free shard in the layout's range, wrapping around.
+	ShardConflictSkipOccupied
+	// ShardConflictAllow uses the requested shards as given, neither
+	// checking nor reserving them. IDs may collide with the holder of
+	// a reserved shard.
+	ShardConflictAllow
+)
+
+// shardRegistry records the shards reserved in this process.
+// Not exported.
+var shardRegistry = struct {
+	mu   sync.Mutex
+	held map[int]bool
+}{held: make(map[int]bool)}
+
+// ReserveShard marks shard as in use by this process, so NewMultiShard
+// does not hand it out again. It returns ErrShardReserved if the shard
+// is already reserved. Generators created with New do not reserve
+// their shard; call ReserveShard for shards assigned elsewhere.
+//
+// Example:
+//
+//	if err := uniqid.ReserveShard(7); err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer uniqid.ReleaseShard(7)
+//	gen, _ := uniqid.New(&uniqid.Config{ShardID: 7})
+func ReserveShard(shard int) error {
+	if shard < 0 {
+		return fmt.Errorf("shard %d must not be negative", shard)
+	}
+	shardRegistry.mu.Lock()
+	defer shardRegistry.mu.Unlock()
+	if shardRegistry.held[shard] {
+		return fmt.Errorf("shard %d: %w", shard, ErrShardReserved)
+	}
+	shardRegistry.held[shard] = true
+	return nil
+}
+
+// ReleaseShard frees a shard reserved with ReserveShard. Releasing a
+// shard that is not reserved is a no-op.
+func ReleaseShard(shard int) {
+	shardRegistry.mu.Lock()
+	delete(shardRegistry.held, shard)
+	shardRegistry.mu.Unlock()
+}
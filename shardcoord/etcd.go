@@ -0,0 +1,129 @@
+package shardcoord
+
+import (
+	"context"
+	"fmt"
+	"time"
+)
+
+// EtcdClient is the part of an etcd v3 client the Etcd allocator
+// uses. Lease IDs are passed as int64. With go.etcd.io/etcd/client/v3
+// it is a thin adapter:
+//
+//	type etcdAdapter struct{ c *clientv3.Client }
+//
+//	func (a etcdAdapter) Grant(ctx context.Context, ttl int64) (int64, error) {
+//	    r, err := a.c.Grant(ctx, ttl)
+//	    if err != nil {
+//	        return 0, err
+//	    }
+//	    return int64(r.ID), nil
+//	}
+//
+//	func (a etcdAdapter) KeepAlive(ctx context.Context, id int64) (<-chan struct{}, error) {
+//	    ch, err := a.c.KeepAlive(ctx, clientv3.LeaseID(id))
+//	    if err != nil {
+//	        return nil, err
+//	    }
+//	    dead := make(chan struct{})
+//	    go func() {
+//	        for range ch {
+//	        }
+//	        close(dead)
+//	    }()
+//	    return dead, nil
+//	}
+//
+//	func (a etcdAdapter) PutIfAbsent(ctx context.Context, key, val string, id int64) (bool, error) {
+//	    r, err := a.c.Txn(ctx).
+//	        If(clientv3.Compare(clientv3.CreateRevision(key), "=", 0)).
+//	        Then(clientv3.OpPut(key, val, clientv3.WithLease(clientv3.LeaseID(id)))).
+//	        Commit()
+//	    if err != nil {
+//	        return false, err
+//	    }
+//	    return r.Succeeded, nil
+//	}
+//
+//	func (a etcdAdapter) Revoke(ctx context.Context, id int64) error {
+//	    _, err := a.c.Revoke(ctx, clientv3.LeaseID(id))
+//	    return err
+//	}
+type EtcdClient interface {
+	// Grant creates a lease expiring after ttl seconds unless renewed.
+	Grant(ctx context.Context, ttl int64) (int64, error)
+	// KeepAlive renews the lease until ctx is done. The returned
+	// channel is closed when renewal stops for any reason.
+	KeepAlive(ctx context.Context, lease int64) (<-chan struct{}, error)
+	// PutIfAbsent stores key, attached to the lease, only if it does
+	// not exist, and reports whether it did.
+	PutIfAbsent(ctx context.Context, key, val string, lease int64) (bool, error)
+	// Revoke ends the lease, deleting its keys.
+	Revoke(ctx context.Context, lease int64) error
+}
+
+// Etcd allocates shards as etcd keys attached to a lease: a shard is
+// held while its key "<Prefix><shard>" exists, and etcd deletes the
+// key once the lease expires, e.g. after the holder crashed.
+//
+// Fields:
+//   - Client: Connection to etcd (required).
+//   - Prefix: Key prefix for shard keys (default = "/uniqid/shards/").
+//   - TTL: How long a shard outlives its holder's last renewal,
+//     rounded up to whole seconds (default = 10s).
+//   - MaxShard: Largest shard to allocate (default = DefaultMaxShard).
+//   - Owner: Value stored in the shard key (default = hostname/pid).
+type Etcd struct {
+	Client   EtcdClient
+	Prefix   string
+	TTL      time.Duration
+	MaxShard int
+	Owner    string
+}
+
+// Acquire leases a free shard in [0, MaxShard], trying them from a
+// random start. It returns ErrNoFreeShard if all are taken.
+func (e *Etcd) Acquire(ctx context.Context) (*Lease, error) {
+	maxShard, err := checkMaxShard(e.MaxShard)
+	if err != nil {
+		return nil, err
+	}
+	prefix, owner := e.Prefix, e.Owner
+	if prefix == "" {
+		prefix = "/uniqid/shards/"
+	}
+	if owner == "" {
+		owner = defaultOwner()
+	}
+	ttl := e.TTL
+	if ttl <= 0 {
+		ttl = 10 * time.Second
+	}
+	lease, err := e.Client.Grant(ctx, int64((ttl+time.Second-1)/time.Second))
+	if err != nil {
+		return nil, fmt.Errorf("shardcoord: granting lease: %w", err)
+	}
+	revoke := func(ctx context.Context) error { return e.Client.Revoke(ctx, lease) }
+
+	keepCtx, stop := context.WithCancel(context.Background())
+	dead, err := e.Client.KeepAlive(keepCtx, lease)
+	if err != nil {
+		stop()
+		_ = revoke(ctx)
+		return nil, fmt.Errorf("shardcoord: renewing lease: %w", err)
+	}
+	for _, shard := range shardOrder(maxShard) {
+		ok, err := e.Client.PutIfAbsent(ctx, fmt.Sprintf("%s%d", prefix, shard), owner, lease)
+		if err != nil {
+			stop()
+			_ = revoke(ctx)
+			return nil, fmt.Errorf("shardcoord: claiming shard %d: %w", shard, err)
+		}
+		if ok {
+			return newLease(shard, dead, stop, revoke), nil
+		}
+	}
+	stop()
+	_ = revoke(ctx)
+	return nil, ErrNoFreeShard
+}
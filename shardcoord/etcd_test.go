@@ -0,0 +1,153 @@
+package shardcoord
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeEtcd is an in-memory EtcdClient.
+type fakeEtcd struct {
+	mu     sync.Mutex
+	next   int64
+	keys   map[string]int64
+	alive  map[int64]chan struct{}
+	ttl    int64
+	putErr error
+}
+
+func newFakeEtcd() *fakeEtcd {
+	return &fakeEtcd{keys: map[string]int64{}, alive: map[int64]chan struct{}{}}
+}
+
+func (f *fakeEtcd) Grant(_ context.Context, ttl int64) (int64, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	f.next++
+	f.ttl = ttl
+	f.alive[f.next] = make(chan struct{})
+	return f.next, nil
+}
+
+func (f *fakeEtcd) KeepAlive(ctx context.Context, lease int64) (<-chan struct{}, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	dead := make(chan struct{})
+	expired := f.alive[lease]
+	go func() {
+		select {
+		case <-ctx.Done():
+		case <-expired:
+		}
+		close(dead)
+	}()
+	return dead, nil
+}
+
+func (f *fakeEtcd) PutIfAbsent(_ context.Context, key, _ string, lease int64) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.putErr != nil {
+		return false, f.putErr
+	}
+	if _, ok := f.keys[key]; ok {
+		return false, nil
+	}
+	f.keys[key] = lease
+	return true, nil
+}
+
+func (f *fakeEtcd) Revoke(_ context.Context, lease int64) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	for k, l := range f.keys {
+		if l == lease {
+			delete(f.keys, k)
+		}
+	}
+	if ch, ok := f.alive[lease]; ok {
+		close(ch)
+		delete(f.alive, lease)
+	}
+	return nil
+}
+
+// TestEtcdAcquire tests leasing shards through etcd
+func TestEtcdAcquire(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeEtcd()
+	alloc := &Etcd{Client: client, MaxShard: 3, TTL: 1500 * time.Millisecond}
+
+	// Test case 1: Every shard is handed out once, then none is free
+	leases := map[uint16]*Lease{}
+	for i := 0; i < 4; i++ {
+		l, err := alloc.Acquire(ctx)
+		if err != nil {
+			t.Fatalf("Acquire %d failed: %v", i, err)
+		}
+		if _, dup := leases[l.Shard()]; dup {
+			t.Fatalf("Shard %d leased twice", l.Shard())
+		}
+		leases[l.Shard()] = l
+	}
+	if client.ttl != 2 {
+		t.Errorf("Expected the TTL rounded up to 2s, got %d", client.ttl)
+	}
+	if _, err := alloc.Acquire(ctx); err != ErrNoFreeShard {
+		t.Errorf("Expected ErrNoFreeShard, got %v", err)
+	}
+
+	// Test case 2: Release frees the shard without reporting it lost
+	if err := leases[2].Release(ctx); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	if err := leases[2].Release(ctx); err != nil {
+		t.Errorf("Expected a second Release to be a no-op, got %v", err)
+	}
+	if _, err := leases[2].ShardID(ctx); err != ErrLeaseLost {
+		t.Errorf("Expected ErrLeaseLost after Release, got %v", err)
+	}
+	select {
+	case <-leases[2].Lost():
+		t.Error("Expected a released lease not to be lost")
+	case <-time.After(10 * time.Millisecond):
+	}
+	l, err := alloc.Acquire(ctx)
+	if err != nil || l.Shard() != 2 {
+		t.Fatalf("Expected shard 2 to be free again, got %v, %v", l, err)
+	}
+	if shard, err := l.ShardID(ctx); err != nil || shard != 2 {
+		t.Errorf("Expected ShardID 2, got %d %v", shard, err)
+	}
+
+	// Test case 3: An expired lease is reported lost
+	client.mu.Lock()
+	for lease, ch := range client.alive {
+		if client.keys["/uniqid/shards/0"] == lease {
+			close(ch)
+			delete(client.alive, lease)
+		}
+	}
+	client.mu.Unlock()
+	select {
+	case <-leases[0].Lost():
+	case <-time.After(time.Second):
+		t.Error("Expected the expired lease to be lost")
+	}
+
+	// Test case 4: Backend errors are returned and the lease revoked
+	client.putErr = errors.New("etcd unavailable")
+	for k := range client.keys {
+		delete(client.keys, k)
+	}
+	if _, err := alloc.Acquire(ctx); !errors.Is(err, client.putErr) {
+		t.Errorf("Expected the backend error, got %v", err)
+	}
+
+	// Test case 5: Out-of-range MaxShard is rejected
+	if _, err := (&Etcd{Client: client, MaxShard: -1}).Acquire(ctx); err == nil {
+		t.Error("Expected error for negative MaxShard, got nil")
+	}
+}
@@ -0,0 +1,81 @@
+package shardcoord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/aprakasa/uniqid"
+)
+
+// FileLock allocates shards among the processes of one host by locking
+// a file per shard, "<Dir>/shard-<n>.lock". The operating system
+// releases the lock when the holder exits, even if it crashes, so
+// there is nothing to renew and the lease is never lost.
+//
+// Shards are tried from the host's auto-derived shard (see
+// uniqid.AutoShardDebug) upwards, so the first process on a host keeps
+// the shard it would have had anyway and the next ones take the
+// following shards. This separates processes on one host; it does not
+// coordinate hosts, whose auto-derived shards may still collide.
+//
+// Locking uses flock(2), which is only available on Unix; Acquire
+// fails elsewhere. Dir must be on a local file system.
+//
+// Fields:
+//   - Dir: Directory for the lock files, created if missing
+//     (default = "/var/run/uniqid").
+//   - MaxShard: Largest shard to allocate (default = DefaultMaxShard).
+type FileLock struct {
+	Dir      string
+	MaxShard int
+}
+
+// Acquire locks the first free shard and returns its lease. It returns
+// ErrNoFreeShard if every shard in [0, MaxShard] is locked.
+//
+// Example:
+//
+//	lease, err := (&shardcoord.FileLock{}).Acquire(ctx)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	gen, err := uniqid.New(&uniqid.Config{ShardProvider: lease})
+func (f *FileLock) Acquire(ctx context.Context) (*Lease, error) {
+	maxShard, err := checkMaxShard(f.MaxShard)
+	if err != nil {
+		return nil, err
+	}
+	dir := f.Dir
+	if dir == "" {
+		dir = "/var/run/uniqid"
+	}
+	if err := os.MkdirAll(dir, 0o755); err != nil {
+		return nil, fmt.Errorf("shardcoord: %w", err)
+	}
+	start, _, _, _ := uniqid.AutoShardDebug()
+	for i := 0; i <= maxShard; i++ {
+		if err := ctx.Err(); err != nil {
+			return nil, err
+		}
+		shard := uint16((int(start) + i) % (maxShard + 1))
+		file, err := lockFile(filepath.Join(dir, fmt.Sprintf("shard-%d.lock", shard)))
+		if errors.Is(err, errLocked) {
+			continue
+		}
+		if err != nil {
+			return nil, fmt.Errorf("shardcoord: locking shard %d: %w", shard, err)
+		}
+		_ = file.Truncate(0)
+		_, _ = file.WriteAt([]byte(defaultOwner()+"\n"), 0)
+		held, stop := context.WithCancel(context.Background())
+		release := func(context.Context) error { return file.Close() }
+		return newLease(shard, held.Done(), stop, release), nil
+	}
+	return nil, ErrNoFreeShard
+}
+
+// errLocked is returned by lockFile when another holder has the lock.
+var errLocked = errors.New("file is locked")
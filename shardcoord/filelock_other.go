@@ -0,0 +1,13 @@
+//go:build !unix
+
+package shardcoord
+
+import (
+	"errors"
+	"os"
+)
+
+// lockFile is not supported without flock.
+func lockFile(string) (*os.File, error) {
+	return nil, errors.New("file locks are only supported on Unix")
+}
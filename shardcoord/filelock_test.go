@@ -0,0 +1,67 @@
+package shardcoord
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/aprakasa/uniqid"
+)
+
+// TestFileLockAcquire tests allocating shards with lock files
+func TestFileLockAcquire(t *testing.T) {
+	ctx := context.Background()
+	dir := filepath.Join(t.TempDir(), "locks")
+	alloc := &FileLock{Dir: dir, MaxShard: 2}
+
+	// Test case 1: Each lock file is held by one lease at a time
+	start, _, _, _ := uniqid.AutoShardDebug()
+	first, err := alloc.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	if first.Shard() != start%3 {
+		t.Errorf("Expected the first lease to start at the auto shard %d, got %d", start%3, first.Shard())
+	}
+	second, err := alloc.Acquire(ctx)
+	if err != nil || second.Shard() == first.Shard() {
+		t.Fatalf("Expected a different second shard, got %v, %v", second, err)
+	}
+	third, err := alloc.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	if _, err := alloc.Acquire(ctx); err != ErrNoFreeShard {
+		t.Errorf("Expected ErrNoFreeShard, got %v", err)
+	}
+
+	// Test case 2: The lock file names its holder
+	b, err := os.ReadFile(filepath.Join(dir, fmt.Sprintf("shard-%d.lock", first.Shard())))
+	if err != nil || !strings.Contains(string(b), "/") {
+		t.Errorf("Expected the owner in the lock file, got %q, %v", b, err)
+	}
+
+	// Test case 3: Release unlocks the shard, and the lease works as a provider
+	if err := second.Release(ctx); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	again, err := alloc.Acquire(ctx)
+	if err != nil || again.Shard() != second.Shard() {
+		t.Fatalf("Expected shard %d to be free again, got %v, %v", second.Shard(), again, err)
+	}
+	gen, err := uniqid.New(&uniqid.Config{ShardProvider: again})
+	if err != nil || gen.Config().ShardID != int(again.Shard()) {
+		t.Errorf("Expected the generator on shard %d, got %v", again.Shard(), err)
+	}
+	select {
+	case <-first.Lost():
+		t.Error("Expected a file lock never to be lost")
+	default:
+	}
+	for _, l := range []*Lease{first, third, again} {
+		_ = l.Release(ctx)
+	}
+}
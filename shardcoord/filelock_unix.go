@@ -0,0 +1,27 @@
+//go:build unix
+
+package shardcoord
+
+import (
+	"errors"
+	"os"
+	"syscall"
+)
+
+// lockFile opens path and takes an exclusive flock on it without
+// waiting, returning errLocked if another open file holds it. Closing
+// the file releases the lock.
+func lockFile(path string) (*os.File, error) {
+	file, err := os.OpenFile(path, os.O_RDWR|os.O_CREATE, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	if err := syscall.Flock(int(file.Fd()), syscall.LOCK_EX|syscall.LOCK_NB); err != nil {
+		file.Close()
+		if errors.Is(err, syscall.EWOULDBLOCK) {
+			return nil, errLocked
+		}
+		return nil, err
+	}
+	return file, nil
+}
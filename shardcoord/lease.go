@@ -0,0 +1,152 @@
+// Package shardcoord allocates unique shard IDs for uniqid generators
+// through a coordination service, so that processes sharing a
+// deployment never use the same shard. Auto-derived shards (Config
+// ShardID -1) hash MAC addresses and hostnames and can collide in
+// large fleets; an allocator hands out each shard to one holder at a
+// time and takes it back when the holder goes away.
+//
+// Backends are reached through small interfaces rather than their
+// client libraries, so this package stays free of third-party
+// dependencies; each interface documents how to adapt the usual
+// client to it.
+//
+// Example:
+//
+//	lease, err := (&shardcoord.Etcd{Client: etcdAdapter{cli}}).Acquire(ctx)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	defer lease.Release(context.Background())
+//	gen, err := uniqid.New(&uniqid.Config{ShardProvider: lease})
+package shardcoord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"os"
+	"sync"
+)
+
+// ErrNoFreeShard is returned by Acquire when every shard in range is
+// held by someone else. Callers that prefer running with an
+// uncoordinated shard over not starting can fall back on it, e.g. to
+// uniqid's auto-derived shard.
+var ErrNoFreeShard = errors.New("shardcoord: no free shard")
+
+// ErrLeaseLost is returned by Lease.ShardID once the lease is no
+// longer held.
+var ErrLeaseLost = errors.New("shardcoord: lease lost")
+
+// DefaultMaxShard is the largest shard allocated when MaxShard is
+// left zero, matching uniqid.DefaultLayout's 10 shard bits.
+const DefaultMaxShard = 1023
+
+// Lease is a shard held by this process. It is renewed in the
+// background until Release is called. If renewal fails for longer
+// than the lease's TTL, the shard may be handed to someone else, and
+// Lost is closed: stop generating IDs with it.
+type Lease struct {
+	shard   uint16
+	lost    chan struct{}
+	stop    context.CancelFunc
+	release func(context.Context) error
+
+	mu       sync.Mutex
+	released bool
+}
+
+// Shard returns the leased shard ID.
+func (l *Lease) Shard() uint16 {
+	return l.shard
+}
+
+// ShardID implements uniqid.ShardProvider, so a lease can be passed
+// as Config.ShardProvider. It returns ErrLeaseLost once the lease is
+// lost or released, which makes New fail and a shard refresh keep the
+// current shard.
+func (l *Lease) ShardID(context.Context) (uint16, error) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	select {
+	case <-l.lost:
+		return 0, ErrLeaseLost
+	default:
+	}
+	if l.released {
+		return 0, ErrLeaseLost
+	}
+	return l.shard, nil
+}
+
+// Lost returns a channel that is closed if the lease is lost before
+// Release is called.
+func (l *Lease) Lost() <-chan struct{} {
+	return l.lost
+}
+
+// Release stops renewing the lease and frees the shard for others.
+// Only the first call has any effect; later calls return nil.
+func (l *Lease) Release(ctx context.Context) error {
+	l.mu.Lock()
+	if l.released {
+		l.mu.Unlock()
+		return nil
+	}
+	l.released = true
+	l.mu.Unlock()
+	l.stop()
+	return l.release(ctx)
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// newLease returns a lease on shard that is marked lost when dead is
+// closed before Release.
+func newLease(shard uint16, dead <-chan struct{}, stop context.CancelFunc, release func(context.Context) error) *Lease {
+	l := &Lease{shard: shard, lost: make(chan struct{}), stop: stop, release: release}
+	go func() {
+		<-dead
+		l.mu.Lock()
+		if !l.released {
+			close(l.lost)
+		}
+		l.mu.Unlock()
+	}()
+	return l
+}
+
+// shardOrder returns the shards 0..maxShard in the order to try them:
+// ascending from a random start, wrapping around, so that processes
+// starting together do not all race for shard 0.
+func shardOrder(maxShard int) []uint16 {
+	n := maxShard + 1
+	start := rand.IntN(n)
+	order := make([]uint16, n)
+	for i := range order {
+		order[i] = uint16((start + i) % n)
+	}
+	return order
+}
+
+// checkMaxShard returns the effective maximum shard for a configured
+// value: DefaultMaxShard for 0, or an error if out of range.
+func checkMaxShard(maxShard int) (int, error) {
+	switch {
+	case maxShard == 0:
+		return DefaultMaxShard, nil
+	case maxShard < 0 || maxShard > 0xFFFF:
+		return 0, fmt.Errorf("shardcoord: maxShard must be 0..65535, got %d", maxShard)
+	}
+	return maxShard, nil
+}
+
+// defaultOwner identifies this process in the values stored for its
+// shards, to help operators see who holds which shard.
+func defaultOwner() string {
+	host, _ := os.Hostname()
+	return fmt.Sprintf("%s/%d", host, os.Getpid())
+}
@@ -0,0 +1,148 @@
+package shardcoord
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"fmt"
+	"time"
+)
+
+// RedisClient is the part of a Redis client the Redis allocator uses.
+// Extend and Delete must compare and act atomically, which takes a
+// Lua script. With github.com/redis/go-redis/v9 it is a thin adapter:
+//
+//	var (
+//	    extendScript = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then
+//	        return redis.call("PEXPIRE", KEYS[1], ARGV[2]) end return 0`)
+//	    deleteScript = redis.NewScript(`if redis.call("GET", KEYS[1]) == ARGV[1] then
+//	        return redis.call("DEL", KEYS[1]) end return 0`)
+//	)
+//
+//	type redisAdapter struct{ c *redis.Client }
+//
+//	func (a redisAdapter) SetNX(ctx context.Context, key, val string, ttl time.Duration) (bool, error) {
+//	    return a.c.SetNX(ctx, key, val, ttl).Result()
+//	}
+//
+//	func (a redisAdapter) Extend(ctx context.Context, key, val string, ttl time.Duration) (bool, error) {
+//	    n, err := extendScript.Run(ctx, a.c, []string{key}, val, ttl.Milliseconds()).Int()
+//	    return n == 1, err
+//	}
+//
+//	func (a redisAdapter) Delete(ctx context.Context, key, val string) error {
+//	    return deleteScript.Run(ctx, a.c, []string{key}, val).Err()
+//	}
+type RedisClient interface {
+	// SetNX stores key with the expiry only if it does not exist
+	// (SET key val NX PX ttl), and reports whether it did.
+	SetNX(ctx context.Context, key, val string, ttl time.Duration) (bool, error)
+	// Extend resets the expiry of key to ttl only if it still holds
+	// val, and reports whether it did.
+	Extend(ctx context.Context, key, val string, ttl time.Duration) (bool, error)
+	// Delete removes key only if it still holds val.
+	Delete(ctx context.Context, key, val string) error
+}
+
+// Redis allocates shards as Redis keys with an expiry: a shard is held
+// while its key "<Prefix><shard>" exists, and a heartbeat keeps
+// extending it. If the holder dies, the key expires after TTL. The
+// stored value carries a random token, so a holder only ever extends
+// or deletes its own key.
+//
+// Fields:
+//   - Client: Connection to Redis (required).
+//   - Prefix: Key prefix for shard keys (default = "uniqid:shard:").
+//   - TTL: How long a shard outlives its holder's last heartbeat
+//     (default = 10s).
+//   - Heartbeat: Time between expiry extensions (default = TTL/3).
+//   - MaxShard: Largest shard to allocate (default = DefaultMaxShard).
+//   - Owner: Label stored in the shard key before the token
+//     (default = hostname/pid).
+type Redis struct {
+	Client    RedisClient
+	Prefix    string
+	TTL       time.Duration
+	Heartbeat time.Duration
+	MaxShard  int
+	Owner     string
+}
+
+// Acquire claims a free shard in [0, MaxShard], trying them from a
+// random start, and starts its heartbeat. It returns ErrNoFreeShard if
+// all are taken. The lease is lost if the key could not be extended
+// for a whole TTL, or was found to belong to someone else.
+//
+// Example:
+//
+//	lease, err := (&shardcoord.Redis{Client: redisAdapter{rdb}}).Acquire(ctx)
+//	if errors.Is(err, shardcoord.ErrNoFreeShard) {
+//	    cfg.ShardID = -1 // fall back to an auto-derived shard
+//	}
+func (r *Redis) Acquire(ctx context.Context) (*Lease, error) {
+	maxShard, err := checkMaxShard(r.MaxShard)
+	if err != nil {
+		return nil, err
+	}
+	prefix, owner := r.Prefix, r.Owner
+	if prefix == "" {
+		prefix = "uniqid:shard:"
+	}
+	if owner == "" {
+		owner = defaultOwner()
+	}
+	var token [8]byte
+	_, _ = rand.Read(token[:])
+	val := owner + "#" + hex.EncodeToString(token[:])
+	ttl := r.TTL
+	if ttl <= 0 {
+		ttl = 10 * time.Second
+	}
+	beat := r.Heartbeat
+	if beat <= 0 {
+		beat = ttl / 3
+	}
+
+	for _, shard := range shardOrder(maxShard) {
+		key := fmt.Sprintf("%s%d", prefix, shard)
+		ok, err := r.Client.SetNX(ctx, key, val, ttl)
+		if err != nil {
+			return nil, fmt.Errorf("shardcoord: claiming shard %d: %w", shard, err)
+		}
+		if !ok {
+			continue
+		}
+		beatCtx, stop := context.WithCancel(context.Background())
+		dead := make(chan struct{})
+		go r.heartbeat(beatCtx, key, val, ttl, beat, dead)
+		release := func(ctx context.Context) error { return r.Client.Delete(ctx, key, val) }
+		return newLease(shard, dead, stop, release), nil
+	}
+	return nil, ErrNoFreeShard
+}
+
+// heartbeat extends key every beat until ctx is done, closing dead
+// when it stops. It gives up once the key belongs to someone else or
+// no extension has succeeded for ttl.
+func (r *Redis) heartbeat(ctx context.Context, key, val string, ttl, beat time.Duration, dead chan<- struct{}) {
+	defer close(dead)
+	ticker := time.NewTicker(beat)
+	defer ticker.Stop()
+	renewed := time.Now()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+		ok, err := r.Client.Extend(ctx, key, val, ttl)
+		switch {
+		case err == nil && !ok:
+			return
+		case err == nil:
+			renewed = time.Now()
+		case time.Since(renewed) >= ttl:
+			return
+		}
+	}
+}
@@ -0,0 +1,121 @@
+package shardcoord
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// fakeRedis is an in-memory RedisClient with key expiry.
+type fakeRedis struct {
+	mu      sync.Mutex
+	vals    map[string]string
+	expires map[string]time.Time
+	err     error
+}
+
+func newFakeRedis() *fakeRedis {
+	return &fakeRedis{vals: map[string]string{}, expires: map[string]time.Time{}}
+}
+
+// live reports whether key exists and has not expired. f.mu must be held.
+func (f *fakeRedis) live(key string) bool {
+	if exp, ok := f.expires[key]; ok && time.Now().After(exp) {
+		delete(f.vals, key)
+		delete(f.expires, key)
+	}
+	_, ok := f.vals[key]
+	return ok
+}
+
+func (f *fakeRedis) SetNX(_ context.Context, key, val string, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return false, f.err
+	}
+	if f.live(key) {
+		return false, nil
+	}
+	f.vals[key], f.expires[key] = val, time.Now().Add(ttl)
+	return true, nil
+}
+
+func (f *fakeRedis) Extend(_ context.Context, key, val string, ttl time.Duration) (bool, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.err != nil {
+		return false, f.err
+	}
+	if !f.live(key) || f.vals[key] != val {
+		return false, nil
+	}
+	f.expires[key] = time.Now().Add(ttl)
+	return true, nil
+}
+
+func (f *fakeRedis) Delete(_ context.Context, key, val string) error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if f.live(key) && f.vals[key] == val {
+		delete(f.vals, key)
+		delete(f.expires, key)
+	}
+	return nil
+}
+
+// TestRedisAcquire tests leasing shards through Redis
+func TestRedisAcquire(t *testing.T) {
+	ctx := context.Background()
+	client := newFakeRedis()
+	alloc := &Redis{Client: client, MaxShard: 1, TTL: 60 * time.Millisecond, Heartbeat: 10 * time.Millisecond}
+
+	// Test case 1: Shards are handed out once and kept alive past their TTL
+	a, err := alloc.Acquire(ctx)
+	if err != nil {
+		t.Fatalf("Acquire failed: %v", err)
+	}
+	b, err := alloc.Acquire(ctx)
+	if err != nil || b.Shard() == a.Shard() {
+		t.Fatalf("Expected a second, different shard, got %v, %v", b, err)
+	}
+	time.Sleep(150 * time.Millisecond)
+	if _, err := alloc.Acquire(ctx); err != ErrNoFreeShard {
+		t.Errorf("Expected ErrNoFreeShard while heartbeats run, got %v", err)
+	}
+
+	// Test case 2: Release frees the shard for the next caller
+	if err := a.Release(ctx); err != nil {
+		t.Fatalf("Release failed: %v", err)
+	}
+	c, err := alloc.Acquire(ctx)
+	if err != nil || c.Shard() != a.Shard() {
+		t.Fatalf("Expected shard %d to be free again, got %v, %v", a.Shard(), c, err)
+	}
+
+	// Test case 3: A key taken over by someone else loses the lease
+	client.mu.Lock()
+	client.vals[fmt.Sprintf("uniqid:shard:%d", b.Shard())] = "intruder"
+	client.mu.Unlock()
+	select {
+	case <-b.Lost():
+	case <-time.After(time.Second):
+		t.Error("Expected the lease to be lost after a takeover")
+	}
+
+	// Test case 4: Failing heartbeats lose the lease after the TTL
+	client.mu.Lock()
+	client.err = errors.New("redis unavailable")
+	client.mu.Unlock()
+	select {
+	case <-c.Lost():
+	case <-time.After(time.Second):
+		t.Error("Expected the lease to be lost when Redis is unreachable")
+	}
+	if _, err := alloc.Acquire(ctx); !errors.Is(err, client.err) {
+		t.Errorf("Expected the backend error, got %v", err)
+	}
+}
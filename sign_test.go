@@ -0,0 +1,59 @@
+package uniqid
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// TestSigningKey tests signing IDs and rejecting tampered ones
+func TestSigningKey(t *testing.T) {
+	key := bytes.Repeat([]byte("k"), 32)
+	gen, err := New(&Config{ShardID: 2, SigningKey: key, Prefix: "doc_", Checksum: true})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	// Test case 1: Signed IDs carry the signature and verify
+	id := gen.Next()
+	if len(id) != gen.Len() || gen.Len() != 4+11+1+signatureChars {
+		t.Fatalf("Unexpected length of %q, Len %d", id, gen.Len())
+	}
+	if !gen.VerifySigned(id) {
+		t.Fatalf("VerifySigned(%q) = false", id)
+	}
+	if p, err := gen.Parse(id); err != nil || p.Shard != 2 {
+		t.Errorf("Parse(%q) = %+v, %v", id, p, err)
+	}
+	d, _ := gen.NextDelimited(5, '.')
+	if !gen.VerifySigned(d) {
+		t.Errorf("VerifySigned(%q) = false", d)
+	}
+
+	// Test case 2: Altered IDs, forged signatures and other keys are rejected
+	other, _ := New(&Config{ShardID: 2, SigningKey: bytes.Repeat([]byte("x"), 32), Prefix: "doc_", Checksum: true})
+	forged := []byte(id)
+	forged[len(forged)-1] = map[bool]byte{true: 'B', false: 'A'}[forged[len(forged)-1] == 'A']
+	for _, bad := range []string{string(forged), other.Next(), id[:len(id)-signatureChars]} {
+		if gen.VerifySigned(bad) {
+			t.Errorf("VerifySigned(%q) = true", bad)
+		}
+	}
+	if _, err := gen.Parse(string(forged)); !errors.Is(err, ErrSignatureMismatch) || !errors.Is(err, ErrInvalidID) {
+		t.Errorf("Expected ErrSignatureMismatch, got %v", err)
+	}
+	// The signature covers the prefix, so IDs cannot change type.
+	inv, _ := New(&Config{ShardID: 2, SigningKey: key, Prefix: "inv_", Checksum: true})
+	if inv.VerifySigned("inv_" + id[4:]) {
+		t.Error("Expected a re-prefixed ID to fail verification")
+	}
+
+	// Test case 3: Unsigned generators never verify, and short keys are rejected
+	plain, _ := New(&Config{ShardID: 2})
+	if plain.VerifySigned(plain.Next()) {
+		t.Error("Expected VerifySigned to fail without a signing key")
+	}
+	if _, err := New(&Config{ShardID: 2, SigningKey: []byte("short")}); err == nil {
+		t.Error("Expected error for a short signing key, got nil")
+	}
+}
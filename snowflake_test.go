@@ -0,0 +1,70 @@
+package uniqid
+
+import (
+	"testing"
+	"time"
+)
+
+// TestTwitterSnowflake tests bit-identical Twitter Snowflake IDs
+func TestTwitterSnowflake(t *testing.T) {
+	// Test case 1: A known Snowflake ID decodes to its time and machine
+	const known = int64(1541815603606036480)
+	p, err := ParseSnowflake(known)
+	if err != nil {
+		t.Fatalf("ParseSnowflake failed: %v", err)
+	}
+	wantTime := time.Date(2022, 6, 28, 16, 7, 40, 105e6, time.UTC)
+	if !p.Time.Equal(wantTime) || p.Shard != 378 || p.Seq != 0 {
+		t.Errorf("Unexpected parts: %+v", p)
+	}
+
+	// Test case 2: The generator reproduces it
+	gen, err := New(TwitterSnowflakeConfig(378))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	gen.deps.nowFunc = func() int64 { return wantTime.UnixMilli() }
+	if id, _ := gen.NextInt64(); id != known {
+		t.Errorf("Expected %d, got %d", known, id)
+	}
+	second, _ := gen.NextInt64()
+	if p, _ := ParseSnowflake(second); p.Seq != 1 || p.Shard != 378 {
+		t.Errorf("Unexpected parts for second ID: %+v", p)
+	}
+
+	// Test case 3: Negative values are rejected
+	if _, err := ParseSnowflake(-1); err != ErrInvalidID {
+		t.Errorf("Expected ErrInvalidID, got %v", err)
+	}
+}
+
+// TestSonyflake tests bit-identical Sonyflake IDs
+func TestSonyflake(t *testing.T) {
+	now := time.Date(2024, 3, 1, 12, 0, 0, 123e6, time.UTC).UnixMilli()
+	gen, err := New(SonyflakeConfig(0xBEEF))
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	gen.deps.nowFunc = func() int64 { return now }
+
+	// Test case 1: Bits follow Sonyflake's time | sequence | machine order
+	elapsed := (now - SonyflakeEpochMs) / 10
+	for seq := int64(0); seq < 3; seq++ {
+		want := elapsed<<24 | seq<<16 | 0xBEEF
+		if id, _ := gen.NextInt64(); id != want {
+			t.Errorf("Expected %d, got %d", want, id)
+		}
+	}
+
+	// Test case 2: ParseSonyflake reverses it
+	p, err := ParseSonyflake(elapsed<<24 | 2<<16 | 0xBEEF)
+	if err != nil {
+		t.Fatalf("ParseSonyflake failed: %v", err)
+	}
+	if p.Shard != 0xBEEF || p.Seq != 2 || p.Time.UnixMilli() != now-now%10 {
+		t.Errorf("Unexpected parts: %+v", p)
+	}
+	if _, err := ParseSonyflake(-1); err != ErrInvalidID {
+		t.Errorf("Expected ErrInvalidID, got %v", err)
+	}
+}
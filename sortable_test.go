@@ -0,0 +1,74 @@
+package uniqid
+
+import (
+	"math/rand/v2"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestCheckSortable tests verifying lexicographic sortability of a configuration
+func TestCheckSortable(t *testing.T) {
+	// Test case 1: An ASCII-ordered alphabet is sortable
+	for _, cfg := range []*Config{
+		{Alphabet: SortableAlphabet},
+		{Alphabet: SortableAlphabet, VersionPrefix: 'v'},
+		{Alphabet: SortableAlphabet, NoShard: true},
+		{Alphabet: SortableAlphabet, Layout: Layout{TimestampBits: 20, SequenceBits: 4}},
+	} {
+		if err := CheckSortable(cfg); err != nil {
+			t.Errorf("Expected %+v to be sortable, got %v", cfg, err)
+		}
+	}
+
+	// Test case 2: The default base64url alphabet is not
+	for _, cfg := range []*Config{nil, {}} {
+		err := CheckSortable(cfg)
+		if err == nil || !strings.Contains(err.Error(), "sorts before") {
+			t.Errorf("Expected sort violation for default alphabet, got %v", err)
+		}
+	}
+
+	// Test case 3: Invalid configurations are reported
+	if err := CheckSortable(&Config{Alphabet: "abc"}); err == nil {
+		t.Error("Expected error for invalid config, got nil")
+	}
+}
+
+// TestCompare tests ordering IDs by their decoded values
+func TestCompare(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	gen, _ := New(&Config{ShardID: 1})
+	gen.deps.nowFunc = func() int64 { return mockTime }
+	var ids []string
+	for i := 0; i < 200; i++ {
+		if i%50 == 0 {
+			mockTime++
+		}
+		ids = append(ids, gen.Next())
+	}
+
+	// Test case 1: Sorting shuffled IDs restores generation order
+	shuffled := slices.Clone(ids)
+	rand.Shuffle(len(shuffled), func(i, j int) { shuffled[i], shuffled[j] = shuffled[j], shuffled[i] })
+	slices.SortFunc(shuffled, Compare)
+	if !slices.Equal(shuffled, ids) {
+		t.Error("Expected Compare to sort IDs in generation order")
+	}
+	if !Before(ids[0], ids[1]) || !After(ids[1], ids[0]) || Before(ids[0], ids[0]) || Compare(ids[3], ids[3]) != 0 {
+		t.Error("Unexpected Before/After results")
+	}
+
+	// Test case 2: Invalid strings sort first, byte-wise
+	if Compare("bad", ids[0]) != -1 || Compare(ids[0], "bad") != 1 || Compare("a", "b") != -1 {
+		t.Error("Expected invalid IDs to sort before valid ones")
+	}
+
+	// Test case 3: Generator.Compare decodes its own format
+	obf, _ := New(&Config{ShardID: 1, Prefix: "ev_", ObfuscationKey: []byte("0123456789abcdef")})
+	a, b := obf.Next(), obf.Next()
+	if obf.Compare(a, b) != -1 || obf.Compare(b, a) != 1 {
+		t.Errorf("Expected %q before %q", a, b)
+	}
+}
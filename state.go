@@ -0,0 +1,139 @@
+package uniqid
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"time"
+)
+
+// generatorState is the JSON form of a generator used by ExportJSON
+// and ImportJSON.
+// Not exported.
+type generatorState struct {
+	Name          string   `json:"name,omitempty"`
+	Shard         int      `json:"shard"`
+	EpochMs       int64    `json:"epochMs"`
+	Layout        Layout   `json:"layout"`
+	Prefix        string   `json:"prefix,omitempty"`
+	Checksum      bool     `json:"checksum,omitempty"`
+	VersionPrefix string   `json:"versionPrefix,omitempty"`
+	UnitMs        int64    `json:"timestampUnitMs,omitempty"`
+	Alphabet      string   `json:"alphabet,omitempty"`
+	Encoding      Encoding `json:"encoding,omitempty"`
+	Salt          uint16   `json:"salt,omitempty"`
+	BurstOverflow bool     `json:"burstOverflow,omitempty"`
+	RandomizeSeq  bool     `json:"randomizeSequence,omitempty"`
+	Banned        []string `json:"bannedSubstrings,omitempty"`
+	LastMs        int64    `json:"lastMs"`
+	Seq           uint32   `json:"seq"`
+	Counter       uint64   `json:"counter"`
+	Overflow      uint16   `json:"overflow,omitempty"`
+}
+
+// ExportJSON serializes the generator's configuration (name, shard,
+// epoch, layout, prefix, checksum, version prefix, timestamp unit,
+// alphabet, encoding, salt, burst overflow, randomized sequence and
+// banned substrings) and runtime state (last issued timestamp tick,
+// sequence, issue counter and overflow count) as human-readable JSON.
+// The fields after the version prefix are omitted when they are the
+// defaults.
+//
+// Keys do not belong in a human-readable file, so ExportJSON returns
+// an error for generators with Config.SigningKey, ObfuscationKey or a
+// token key, whose IDs a restored generator could not reproduce. It
+// also fails for a ByteOrder other than big-endian, which has no JSON
+// form.
+//
+// Example output:
+//
+//	{"shard":1,"epochMs":1577836800000,"layout":{"timestampBits":39,
+//	"shardBits":10,"sequenceBits":15,"reservedBits":0},
+//	"lastMs":180000000000,"seq":3,"counter":42}
+func (g *Generator) ExportJSON() ([]byte, error) {
+	switch {
+	case len(g.signKey) > 0 || len(g.obfKey) > 0 || len(g.tokenKey) > 0:
+		return nil, errors.New("exportJSON does not serialize signing, obfuscation or token keys")
+	case g.order != binary.BigEndian:
+		return nil, errors.New("exportJSON only supports the big-endian byteOrder")
+	}
+	g.mu.Lock()
+	lastMs, seq := g.position()
+	st := generatorState{
+		Name:          g.name,
+		Shard:         int(g.shard),
+		EpochMs:       g.baseEpoch,
+		Layout:        g.layout,
+		Prefix:        g.prefix,
+		Checksum:      g.checksum,
+		LastMs:        lastMs,
+		Seq:           seq,
+		Counter:       g.counter,
+		Salt:          g.salt,
+		Overflow:      g.ovf,
+		BurstOverflow: g.overflow,
+		RandomizeSeq:  g.randSeq,
+		Banned:        g.banned,
+	}
+	g.mu.Unlock()
+	if g.unit > 1 {
+		st.UnitMs = g.unit
+	}
+	if g.codec == crockfordCodec {
+		st.Encoding = EncodingCrockford
+	} else if g.codec != defaultCodec {
+		st.Alphabet = g.codec.alphabet
+	}
+	if g.version != 0 {
+		st.VersionPrefix = string(g.version)
+	}
+	return json.Marshal(st)
+}
+
+// ImportJSON creates a generator from JSON produced by ExportJSON,
+// possibly hand-edited. The layout, shard range and sequence are
+// validated as in New. The generator resumes after the recorded
+// state, so it never reissues an ID at or before lastMs/seq.
+func ImportJSON(b []byte) (*Generator, error) {
+	var st generatorState
+	if err := json.Unmarshal(b, &st); err != nil {
+		return nil, err
+	}
+	if st.Shard < 0 {
+		return nil, errors.New("shard must not be negative")
+	}
+	if len(st.VersionPrefix) > 1 {
+		return nil, errors.New("versionPrefix must be a single character")
+	}
+	cfg := &Config{
+		ShardID:           st.Shard,
+		CustomEpochMs:     st.EpochMs,
+		UnixEpoch:         st.EpochMs == 0,
+		Name:              st.Name,
+		Layout:            st.Layout,
+		Prefix:            st.Prefix,
+		Checksum:          st.Checksum,
+		TimestampUnit:     time.Duration(st.UnitMs) * time.Millisecond,
+		Alphabet:          st.Alphabet,
+		Encoding:          st.Encoding,
+		Salt:              st.Salt,
+		BurstOverflow:     st.BurstOverflow,
+		RandomizeSequence: st.RandomizeSeq,
+		BannedSubstrings:  st.Banned,
+	}
+	if st.VersionPrefix != "" {
+		cfg.VersionPrefix = st.VersionPrefix[0]
+	}
+	g, err := New(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if st.LastMs < 0 || int(st.Seq) > g.layout.MaxSequence() || uint64(st.Overflow) > g.layout.MaxSalt() {
+		return nil, errors.New("lastMs, seq or overflow out of range for layout")
+	}
+	g.lastMs = st.LastMs
+	g.seq = st.Seq
+	g.counter = st.Counter
+	g.ovf = st.Overflow
+	return g, nil
+}
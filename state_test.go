@@ -0,0 +1,99 @@
+package uniqid
+
+import (
+	"encoding/binary"
+	"slices"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestExportImportJSON tests round-tripping generator state via JSON
+func TestExportImportJSON(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	gen, _ := New(&Config{ShardID: 12, Name: "orders", VersionPrefix: 'v', TimestampUnit: 2 * time.Millisecond})
+	gen.deps.nowFunc = func() int64 { return mockTime }
+	for i := 0; i < 3; i++ {
+		_ = gen.Next()
+	}
+
+	b, err := gen.ExportJSON()
+	if err != nil {
+		t.Fatalf("ExportJSON failed: %v", err)
+	}
+	if !strings.Contains(string(b), `"shard":12`) || !strings.Contains(string(b), `"seq":2`) ||
+		!strings.Contains(string(b), `"timestampUnitMs":2`) {
+		t.Errorf("Unexpected JSON: %s", b)
+	}
+
+	imported, err := ImportJSON(b)
+	if err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+	if imported.shard != 12 || imported.baseEpoch != gen.baseEpoch || imported.layout != gen.layout ||
+		imported.lastMs != gen.lastMs || imported.seq != gen.seq || imported.name != "orders" || imported.version != 'v' || imported.unit != 2 {
+		t.Errorf("Imported generator differs from original")
+	}
+
+	// The imported generator continues after the exported state
+	imported.deps.nowFunc = gen.deps.nowFunc
+	want := gen.Next()
+	if got := imported.Next(); got != want {
+		t.Errorf("Expected imported generator to produce %q, got %q", want, got)
+	}
+}
+
+// TestImportJSONInvalid tests rejecting invalid JSON state
+func TestImportJSONInvalid(t *testing.T) {
+	layout := `"layout":{"timestampBits":39,"shardBits":10,"sequenceBits":15,"reservedBits":0}`
+	cases := map[string]string{
+		"out-of-range shard": `{"shard":1024,"epochMs":0,` + layout + `,"lastMs":0,"seq":0}`,
+		"negative shard":     `{"shard":-1,"epochMs":0,` + layout + `,"lastMs":0,"seq":0}`,
+		"invalid layout":     `{"shard":1,"epochMs":0,"layout":{"timestampBits":60,"shardBits":10,"sequenceBits":15},"lastMs":0,"seq":0}`,
+		"sequence overflow":  `{"shard":1,"epochMs":0,` + layout + `,"lastMs":0,"seq":40000}`,
+		"long prefix":        `{"shard":1,"epochMs":0,` + layout + `,"versionPrefix":"v1","lastMs":0,"seq":0}`,
+		"negative unit":      `{"shard":1,"epochMs":0,` + layout + `,"timestampUnitMs":-4,"lastMs":0,"seq":0}`,
+		"malformed":          `{"shard":`,
+	}
+	for name, js := range cases {
+		if _, err := ImportJSON([]byte(js)); err == nil {
+			t.Errorf("%s: expected error, got nil", name)
+		}
+	}
+}
+
+// TestExportJSONUnsupported tests refusing to export settings JSON
+// cannot carry, and round-tripping the other filters
+func TestExportJSONUnsupported(t *testing.T) {
+	key := []byte("0123456789abcdef")
+
+	// Test case 1: Keys and byte orders fail instead of being dropped
+	for _, cfg := range []*Config{
+		{ShardID: 1, SigningKey: key},
+		{ShardID: 1, ObfuscationKey: key},
+		{ShardID: 1, TokenKey: key},
+		{ShardID: 1, ByteOrder: binary.LittleEndian},
+	} {
+		gen, err := New(cfg)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		if b, err := gen.ExportJSON(); err == nil {
+			t.Errorf("Expected error exporting %+v, got %s", cfg, b)
+		}
+	}
+
+	// Test case 2: RandomizeSequence and BannedSubstrings survive
+	gen, _ := New(&Config{ShardID: 1, RandomizeSequence: true, BannedSubstrings: LookAlikeRuns})
+	b, err := gen.ExportJSON()
+	if err != nil {
+		t.Fatalf("ExportJSON failed: %v", err)
+	}
+	imported, err := ImportJSON(b)
+	if err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+	if !imported.randSeq || !slices.Equal(imported.banned, LookAlikeRuns) {
+		t.Errorf("Expected the filters to be restored from %s", b)
+	}
+}
@@ -0,0 +1,52 @@
+package uniqid
+
+import (
+	"context"
+	"iter"
+)
+
+// Iter returns an endless sequence of new IDs from Next, for use with
+// range. Each iteration generates one ID; stop by breaking out of the
+// loop.
+//
+// Example:
+//
+//	for id := range gen.Iter() {
+//	    if !process(id) {
+//	        break
+//	    }
+//	}
+func (g *Generator) Iter() iter.Seq[string] {
+	return func(yield func(string) bool) {
+		for yield(g.Next()) {
+		}
+	}
+}
+
+// Stream returns a channel delivering new IDs from Next until ctx is
+// done, after which the channel is closed. buf is the channel's
+// capacity; IDs waiting in the buffer keep the timestamp they were
+// generated with. The sending goroutine runs until ctx is done, so
+// cancel ctx once the stream is no longer read.
+//
+// Example:
+//
+//	ctx, cancel := context.WithCancel(ctx)
+//	defer cancel()
+//	for id := range gen.Stream(ctx, 64) {
+//	    rows <- Row{ID: id}
+//	}
+func (g *Generator) Stream(ctx context.Context, buf int) <-chan string {
+	ch := make(chan string, max(buf, 0))
+	go func() {
+		defer close(ch)
+		for ctx.Err() == nil {
+			select {
+			case ch <- g.Next():
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return ch
+}
@@ -0,0 +1,67 @@
+package uniqid
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+// TestIterAndStream tests ranging over generated IDs
+func TestIterAndStream(t *testing.T) {
+	gen, err := New(&Config{ShardID: 1})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+
+	// Test case 1: Iter yields increasing IDs until the loop breaks
+	var ids []string
+	for id := range gen.Iter() {
+		ids = append(ids, id)
+		if len(ids) == 100 {
+			break
+		}
+	}
+	for i := 1; i < len(ids); i++ {
+		if a, b := mustDecode(t, ids[i-1]), mustDecode(t, ids[i]); a >= b {
+			t.Fatalf("Iter IDs not increasing: %q then %q", ids[i-1], ids[i])
+		}
+	}
+
+	// Test case 2: Stream delivers IDs and closes once ctx is cancelled
+	ctx, cancel := context.WithCancel(context.Background())
+	stream := gen.Stream(ctx, 8)
+	last := ids[len(ids)-1]
+	for i := 0; i < 100; i++ {
+		id := <-stream
+		if mustDecode(t, last) >= mustDecode(t, id) {
+			t.Fatalf("Stream IDs not increasing: %q then %q", last, id)
+		}
+		last = id
+	}
+	cancel()
+	timeout := time.After(time.Second)
+	for open := true; open; {
+		select {
+		case _, open = <-stream:
+		case <-timeout:
+			t.Fatal("Expected the stream to close after cancel")
+		}
+	}
+
+	// Test case 3: A negative buffer gives an unbuffered stream
+	ctx, cancel = context.WithCancel(context.Background())
+	defer cancel()
+	if id := <-gen.Stream(ctx, -1); id == "" {
+		t.Error("Expected an ID from an unbuffered stream")
+	}
+}
+
+// mustDecode returns the packed value of a default-format ID.
+func mustDecode(t *testing.T, id string) uint64 {
+	t.Helper()
+	val, err := decode(id, DefaultLayout)
+	if err != nil {
+		t.Fatalf("decode(%q) failed: %v", id, err)
+	}
+	return val
+}
@@ -0,0 +1,42 @@
+package uniqid
+
+import "errors"
+
+// ErrTagOutOfRange is returned by NextTagged when the tag does not fit
+// the layout's tag field.
+var ErrTagOutOfRange = errors.New("tag out of range for layout")
+
+// NextTagged generates a new ID like Next, with tag stored in the
+// layout's tag field (see Layout.TagBits). Unlike reserved bits, the
+// tag can differ on every call, e.g. to embed the tenant an ID belongs
+// to so it can be routed without a lookup. Generator.Parse reports it
+// as Parts.Tag.
+//
+// The tag does not take part in uniqueness: IDs are unique even if
+// every call uses the same tag. It returns ErrTagOutOfRange if tag is
+// greater than Layout.MaxTag, and ErrTooManyBanned like NextE.
+//
+// Example:
+//
+//	gen, _ := uniqid.New(&uniqid.Config{
+//	    NoShard: true,
+//	    Layout:  uniqid.Layout{TimestampBits: 39, SequenceBits: 5, TagBits: 20},
+//	})
+//	id, err := gen.NextTagged(tenantID)
+func (g *Generator) NextTagged(tag uint64) (string, error) {
+	if tag > g.layout.MaxTag() {
+		return "", ErrTagOutOfRange
+	}
+	if g.lockFree {
+		val, _ := g.next(true)
+		return g.format(g.layout.withTag(val, tag)), nil
+	}
+	// Filter the tagged ID, as that is the one issued.
+	g.mu.Lock()
+	val, err := g.nextTaggedLocked(true, g.tick(), tag)
+	g.mu.Unlock()
+	if err != nil {
+		return "", err
+	}
+	return g.format(val), nil
+}
@@ -0,0 +1,61 @@
+package uniqid
+
+import (
+	"errors"
+	"testing"
+	"time"
+)
+
+// TestNextTagged tests embedding per-call tags in IDs
+func TestNextTagged(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	layout := Layout{TimestampBits: 39, ShardBits: 2, SequenceBits: 3, TagBits: 20}
+	gen, err := New(&Config{ShardID: 2, Layout: layout})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	// Test case 1: Tags round-trip alongside the other fields
+	seen := make(map[string]bool)
+	for i, tag := range []uint64{0, 1, 42, 0xABCDE, layout.MaxTag(), 42} {
+		id, err := gen.NextTagged(tag)
+		if err != nil {
+			t.Fatalf("NextTagged(%d) failed: %v", tag, err)
+		}
+		if seen[id] {
+			t.Errorf("Duplicate ID %q", id)
+		}
+		seen[id] = true
+		parts, err := gen.Parse(id)
+		if err != nil {
+			t.Fatalf("Parse failed: %v", err)
+		}
+		if parts.Tag != tag || parts.Shard != 2 || int(parts.Seq) != i || parts.Time.UnixMilli() != mockTime {
+			t.Errorf("Unexpected parts for tag %d: %+v", tag, parts)
+		}
+	}
+
+	// Test case 2: Next leaves the tag zero
+	if parts, _ := gen.Parse(gen.Next()); parts.Tag != 0 {
+		t.Errorf("Expected zero tag from Next, got %d", parts.Tag)
+	}
+
+	// Test case 3: Tags that do not fit are rejected without consuming a slot
+	before := gen.Stats().Generated
+	if _, err := gen.NextTagged(1 << 20); !errors.Is(err, ErrTagOutOfRange) {
+		t.Errorf("Expected ErrTagOutOfRange, got %v", err)
+	}
+	def, _ := New(&Config{ShardID: 1})
+	if _, err := def.NextTagged(1); !errors.Is(err, ErrTagOutOfRange) {
+		t.Errorf("Expected ErrTagOutOfRange without a tag field, got %v", err)
+	}
+	if gen.Stats().Generated != before {
+		t.Error("Expected rejected tag not to generate an ID")
+	}
+
+	// Test case 4: Negative tag width is invalid
+	if err := (Layout{TimestampBits: 39, SequenceBits: 5, TagBits: -1}).Validate(); err == nil {
+		t.Error("Expected error for negative TagBits, got nil")
+	}
+}
@@ -0,0 +1,60 @@
+package uniqid
+
+import "time"
+
+// MinIDForTime returns the smallest default-format ID that can carry
+// the millisecond of t: its timestamp with every other field zero.
+// Together with MaxIDForTime it bounds the IDs generated in a time
+// range, across all shards and sequences, for range scans on an
+// ID-indexed column without a separate timestamp column. Times before
+// the epoch or past the layout's horizon are clamped to the first or
+// last representable millisecond.
+//
+// The bounds are values: compare them as IDs or integers (e.g. in a
+// BIGINT column). Default-format strings do not sort in value order.
+//
+// Example:
+//
+//	rows, err := db.Query("SELECT * FROM events WHERE id BETWEEN $1 AND $2",
+//	    uniqid.MinIDForTime(t1), uniqid.MaxIDForTime(t2))
+func MinIDForTime(t time.Time) ID {
+	return ID(timeBound(DefaultLayout, defaultEpochMs, 1, t, false))
+}
+
+// MaxIDForTime returns the largest default-format ID that can carry
+// the millisecond of t: its timestamp with every other field set to
+// all ones. See MinIDForTime.
+func MaxIDForTime(t time.Time) ID {
+	return ID(timeBound(DefaultLayout, defaultEpochMs, 1, t, true))
+}
+
+// MinIDForTime is like the package-level MinIDForTime but returns the
+// bound in g's format: layout, epoch, timestamp unit, encoding and
+// version prefix. The strings order like the values only if g's
+// alphabet is sortable; see CheckSortable.
+func (g *Generator) MinIDForTime(t time.Time) string {
+	return g.format(timeBound(g.layout, g.baseEpoch, g.unit, t, false))
+}
+
+// MaxIDForTime is like the package-level MaxIDForTime but returns the
+// bound in g's format. See Generator.MinIDForTime.
+func (g *Generator) MaxIDForTime(t time.Time) string {
+	return g.format(timeBound(g.layout, g.baseEpoch, g.unit, t, true))
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// timeBound returns the packed value of layout l with the timestamp
+// tick of t, clamped to the layout's range, and all lower bits clear,
+// or set if upper is true.
+func timeBound(l Layout, baseEpoch, unit int64, t time.Time, upper bool) uint64 {
+	_, _, timeShift := l.shifts()
+	tick := min(max((t.UnixMilli()-baseEpoch)/unit, 0), int64(1)<<uint(l.TimestampBits)-1)
+	val := uint64(tick) << timeShift
+	if upper {
+		val |= 1<<timeShift - 1
+	}
+	return val
+}
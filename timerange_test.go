@@ -0,0 +1,46 @@
+package uniqid
+
+import (
+	"testing"
+	"time"
+)
+
+// TestIDForTime tests the ID bounds of a time range
+func TestIDForTime(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	gen, _ := New(&Config{ShardID: 1023})
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	// Test case 1: IDs of a millisecond fall within its bounds
+	at := time.UnixMilli(mockTime)
+	lo, hi := MinIDForTime(at), MaxIDForTime(at)
+	for i := 0; i < 3; i++ {
+		if id, _ := gen.NextID(); id < lo || id > hi {
+			t.Errorf("ID %v outside [%v, %v]", id, lo, hi)
+		}
+	}
+	if !lo.Time().Equal(at) || !hi.Time().Equal(at) || lo.Shard() != 0 || hi.Shard() != 1023 {
+		t.Errorf("Unexpected bounds %v %v", lo.Time(), hi.Time())
+	}
+
+	// Test case 2: Bounds of adjacent milliseconds are adjacent
+	if MinIDForTime(at.Add(time.Millisecond)) != hi+1 {
+		t.Error("Expected the next millisecond to start right after the max bound")
+	}
+
+	// Test case 3: Out-of-range times are clamped
+	if MinIDForTime(time.UnixMilli(0)) != 0 {
+		t.Error("Expected a time before the epoch to clamp to 0")
+	}
+	if MaxIDForTime(time.Date(2200, 1, 1, 0, 0, 0, 0, time.UTC)) != ^ID(0) {
+		t.Error("Expected a time past the horizon to clamp to the maximum")
+	}
+
+	// Test case 4: Generator bounds use its format
+	custom, _ := New(&Config{ShardID: 2, CustomEpochMs: 1700000000000, Alphabet: SortableAlphabet, VersionPrefix: 'v'})
+	custom.deps.nowFunc = func() int64 { return mockTime }
+	id := custom.Next()
+	if lo, hi := custom.MinIDForTime(at), custom.MaxIDForTime(at); id < lo || id > hi || lo[0] != 'v' {
+		t.Errorf("ID %q outside [%q, %q]", id, lo, hi)
+	}
+}
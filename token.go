@@ -0,0 +1,65 @@
+package uniqid
+
+import "errors"
+
+// ErrNoTokenKey is returned by NextToken when Config.TokenKey is empty.
+var ErrNoTokenKey = errors.New("no token key configured")
+
+// NextToken generates a new ID and returns it as an unguessable
+// 11-character token (13 with EncodingCrockford): the packed value is
+// run through a keyed permutation (a Feistel network keyed by
+// Config.TokenKey, as for ObfuscationKey) before encoding. Tokens are
+// still unique, and DecodeToken recovers the ID for holders of the
+// key.
+//
+// This is a format-preserving obfuscation, not a substitute for real
+// authentication: it hides the timestamp and sequence from observers
+// but proves nothing about who created the token.
+func (g *Generator) NextToken() (string, error) {
+	if len(g.tokenKey) == 0 {
+		return "", ErrNoTokenKey
+	}
+	val, _ := g.next(true)
+	return g.codec.encode64(permute(g.tokenKey, val, 64, false)), nil
+}
+
+// DecodeToken reverses NextToken for a generator configured with
+// TokenKey, returning the underlying ID as g would format it.
+func (g *Generator) DecodeToken(s string) (string, error) {
+	if len(g.tokenKey) == 0 {
+		return "", ErrNoTokenKey
+	}
+	val, err := g.codec.decode(s, DefaultLayout)
+	if err != nil {
+		return "", err
+	}
+	return g.format(permute(g.tokenKey, val, 64, true)), nil
+}
+
+// DecodeToken reverses NextToken given the token key, returning the
+// underlying ID in the default 11-character form. Use
+// Generator.DecodeToken for generators with a custom layout or
+// version prefix.
+func DecodeToken(s string, key []byte) (string, error) {
+	if len(key) == 0 {
+		return "", ErrNoTokenKey
+	}
+	val, err := decode(s, DefaultLayout)
+	if err != nil {
+		return "", err
+	}
+	return defaultCodec.encode64(permute(key, val, 64, true)), nil
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// encode64 encodes a full 64-bit value, as 11 characters with a
+// 64-character alphabet.
+func (c *codec) encode64(val uint64) string {
+	var out [16]byte
+	n := c.chars(DefaultLayout)
+	c.encode(out[:n], val)
+	return string(out[:n])
+}
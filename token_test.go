@@ -0,0 +1,85 @@
+package uniqid
+
+import (
+	"testing"
+	"time"
+)
+
+// TestNextToken tests round-tripping tokens through DecodeToken
+func TestNextToken(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	key := []byte("secret-token-key")
+	gen, _ := New(&Config{ShardID: 3, TokenKey: key})
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	// Predict the underlying ID with a second generator in lockstep
+	twin, _ := New(&Config{ShardID: 3})
+	twin.deps.nowFunc = gen.deps.nowFunc
+
+	for i := 0; i < 3; i++ {
+		token, err := gen.NextToken()
+		if err != nil {
+			t.Fatalf("NextToken failed: %v", err)
+		}
+		want := twin.Next()
+		if token == want {
+			t.Errorf("Token %q equals the plain ID", token)
+		}
+		if len(token) != 11 {
+			t.Errorf("Expected 11-character token, got %q", token)
+		}
+
+		id, err := DecodeToken(token, key)
+		if err != nil {
+			t.Fatalf("DecodeToken failed: %v", err)
+		}
+		if id != want {
+			t.Errorf("DecodeToken returned %q, want %q", id, want)
+		}
+		if id, _ := gen.DecodeToken(token); id != want {
+			t.Errorf("Generator.DecodeToken returned %q, want %q", id, want)
+		}
+	}
+
+	// A wrong key does not recover the ID
+	token, _ := gen.NextToken()
+	want := twin.Next()
+	if id, _ := DecodeToken(token, []byte("other-key")); id == want {
+		t.Error("DecodeToken with the wrong key recovered the ID")
+	}
+}
+
+// TestNextTokenKeys tests that different keys give different tokens
+func TestNextTokenKeys(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	a, _ := New(&Config{ShardID: 3, TokenKey: []byte("token-key-a-0123")})
+	b, _ := New(&Config{ShardID: 3, TokenKey: []byte("token-key-b-0123")})
+	a.deps.nowFunc = func() int64 { return mockTime }
+	b.deps.nowFunc = a.deps.nowFunc
+
+	ta, _ := a.NextToken()
+	tb, _ := b.NextToken()
+	if ta == tb {
+		t.Errorf("Different keys produced the same token %q", ta)
+	}
+
+	// Without a key, tokens are unavailable
+	gen, _ := New(&Config{ShardID: 3})
+	if _, err := gen.NextToken(); err != ErrNoTokenKey {
+		t.Errorf("Expected ErrNoTokenKey, got %v", err)
+	}
+	if _, err := gen.DecodeToken(ta); err != ErrNoTokenKey {
+		t.Errorf("Expected ErrNoTokenKey, got %v", err)
+	}
+	if _, err := DecodeToken(ta, nil); err != ErrNoTokenKey {
+		t.Errorf("Expected ErrNoTokenKey, got %v", err)
+	}
+	if _, err := DecodeToken("bad", []byte("token-key-a-0123")); err != ErrInvalidID {
+		t.Errorf("Expected ErrInvalidID, got %v", err)
+	}
+
+	// Short keys are rejected like short signing keys
+	if _, err := New(&Config{ShardID: 3, TokenKey: []byte("k")}); err == nil {
+		t.Error("Expected error for a 1-byte token key, got nil")
+	}
+}
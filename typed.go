@@ -0,0 +1,88 @@
+package uniqid
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+)
+
+// Kind names an entity type for TypedGenerator. Implement it on an
+// empty struct per entity; Prefix returns the type prefix of that
+// entity's IDs, e.g. "usr_", and must not depend on the receiver's
+// value.
+type Kind interface {
+	Prefix() string
+}
+
+// TypedID is an ID of entity kind K. IDs of different kinds are
+// distinct Go types, so a user ID cannot be passed where an order ID
+// is expected. The underlying string is the full ID, prefix included.
+type TypedID[K Kind] string
+
+// String returns id as a plain string.
+func (id TypedID[K]) String() string {
+	return string(id)
+}
+
+// TypedGenerator issues TypedIDs of kind K from an underlying
+// Generator, prefixed with K's Prefix. Several TypedGenerators may
+// share one Generator, and so one clock, shard and sequence, which
+// keeps IDs of every kind unique across kinds. It is safe for
+// concurrent use by multiple goroutines.
+//
+// Example:
+//
+//	type user struct{}
+//
+//	func (user) Prefix() string { return "usr_" }
+//
+//	type UserID = uniqid.TypedID[user]
+//
+//	users, err := uniqid.NewTyped[user](gen)
+//	if err != nil {
+//	    log.Fatal(err)
+//	}
+//	var id UserID = users.Next() // "usr_Ab3Xyz0LmN_"
+type TypedGenerator[K Kind] struct {
+	gen    *Generator
+	prefix string
+}
+
+// NewTyped returns a TypedGenerator for kind K drawing IDs from gen.
+// K's Prefix must be a valid Config.Prefix; it goes in front of any
+// prefixes gen itself adds.
+func NewTyped[K Kind](gen *Generator) (*TypedGenerator[K], error) {
+	if gen == nil {
+		return nil, errors.New("generator is required")
+	}
+	var k K
+	prefix := k.Prefix()
+	if err := validatePrefix(prefix); err != nil {
+		return nil, fmt.Errorf("kind %T: %w", k, err)
+	}
+	return &TypedGenerator[K]{gen: gen, prefix: prefix}, nil
+}
+
+// Next returns a new ID of kind K.
+func (t *TypedGenerator[K]) Next() TypedID[K] {
+	return TypedID[K](t.prefix + t.gen.Next())
+}
+
+// Parse checks that s is an ID of kind K from the underlying
+// generator's format and returns it as a TypedID. It returns
+// ErrInvalidID if s lacks K's prefix or is otherwise malformed.
+func (t *TypedGenerator[K]) Parse(s string) (TypedID[K], error) {
+	if _, err := t.Parts(TypedID[K](s)); err != nil {
+		return "", err
+	}
+	return TypedID[K](s), nil
+}
+
+// Parts decomposes id into its components, as Generator.Parse does.
+func (t *TypedGenerator[K]) Parts(id TypedID[K]) (Parts, error) {
+	rest, ok := strings.CutPrefix(string(id), t.prefix)
+	if !ok {
+		return Parts{}, ErrInvalidID
+	}
+	return t.gen.Parse(rest)
+}
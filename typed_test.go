@@ -0,0 +1,62 @@
+package uniqid
+
+import (
+	"strings"
+	"testing"
+)
+
+// testUser, testOrder and testBadKind are entity kinds for the tests.
+type testUser struct{}
+
+func (testUser) Prefix() string { return "usr_" }
+
+type testOrder struct{}
+
+func (testOrder) Prefix() string { return "ord_" }
+
+type testBadKind struct{}
+
+func (testBadKind) Prefix() string { return "bad kind" }
+
+// TestTypedGenerator tests issuing and parsing IDs of distinct kinds
+func TestTypedGenerator(t *testing.T) {
+	gen, err := New(&Config{ShardID: 9})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	users, err := NewTyped[testUser](gen)
+	if err != nil {
+		t.Fatalf("NewTyped failed: %v", err)
+	}
+	orders, _ := NewTyped[testOrder](gen)
+
+	// Test case 1: IDs carry their kind's prefix and the shared shard
+	uid, oid := users.Next(), orders.Next()
+	if !strings.HasPrefix(uid.String(), "usr_") || !strings.HasPrefix(string(oid), "ord_") {
+		t.Fatalf("Unexpected IDs %q, %q", uid, oid)
+	}
+	if uid.String()[4:] == string(oid)[4:] {
+		t.Error("Expected kinds sharing a generator to get distinct IDs")
+	}
+	if p, err := users.Parts(uid); err != nil || p.Shard != 9 {
+		t.Errorf("Parts(%q) = %+v, %v", uid, p, err)
+	}
+
+	// Test case 2: Parse accepts only IDs of its own kind
+	if got, err := users.Parse(uid.String()); err != nil || got != uid {
+		t.Errorf("Parse(%q) = %q, %v", uid, got, err)
+	}
+	for _, bad := range []string{string(oid), uid.String()[4:], "usr_", "usr_!!!!!!!!!!!"} {
+		if _, err := users.Parse(bad); err == nil {
+			t.Errorf("Parse(%q): expected error, got nil", bad)
+		}
+	}
+
+	// Test case 3: Invalid kinds and a nil generator are rejected
+	if _, err := NewTyped[testBadKind](gen); err == nil {
+		t.Error("Expected error for a prefix with a space, got nil")
+	}
+	if _, err := NewTyped[testUser](nil); err == nil {
+		t.Error("Expected error for a nil generator, got nil")
+	}
+}
@@ -0,0 +1,55 @@
+package uniqid
+
+// NextULID generates a new ID as a standard 26-character ULID, for
+// services that already store and index ULIDs. It is built from the
+// same clock and sequence state as Next: the 48-bit timestamp is Unix
+// milliseconds (the start of the tick with a coarser TimestampUnit),
+// followed by the shard and sequence in 16 bits each and 48 random
+// bits read from the generator's random source (Config.RandReader or
+// crypto/rand). ULIDs from one generator are therefore unique and
+// strictly increasing, and sort as strings in generation order.
+//
+// It returns an error only if the random source fails.
+//
+// Example:
+//
+//	id, err := gen.NextULID() // "01HQ3M5V2KC0A0008ZK4X7RT9B"
+func (g *Generator) NextULID() (string, error) {
+	var random [6]byte
+	p, err := g.nextRandomized(random[:])
+	if err != nil {
+		return "", err
+	}
+	hi := uint64(p.Time.UnixMilli())<<16 | uint64(p.Shard)
+	lo := uint64(p.Seq)<<48 | uint64(random[0])<<40 | uint64(random[1])<<32 |
+		uint64(random[2])<<24 | uint64(random[3])<<16 | uint64(random[4])<<8 | uint64(random[5])
+	var out [26]byte
+	encodeULID(&out, hi, lo)
+	return string(out[:]), nil
+}
+
+// -------------------------------------------------------------------
+// Internal helpers (not exported).
+// -------------------------------------------------------------------
+
+// nextRandomized fills random from the generator's random source and
+// then generates a new ID, returning its parts. No ID is consumed if
+// the random source fails.
+func (g *Generator) nextRandomized(random []byte) (Parts, error) {
+	if _, err := g.deps.randFunc(random); err != nil {
+		return Parts{}, err
+	}
+	val, _ := g.next(true)
+	return g.layout.parts(val, g.baseEpoch, g.unit), nil
+}
+
+// encodeULID writes the 128-bit value hi:lo as 26 Crockford Base32
+// characters, most significant first; the first character carries the
+// top 3 bits.
+func encodeULID(dst *[26]byte, hi, lo uint64) {
+	for i := len(dst) - 1; i >= 0; i-- {
+		dst[i] = crockfordAlphabet[lo&31]
+		lo = lo>>5 | hi<<59
+		hi >>= 5
+	}
+}
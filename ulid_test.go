@@ -0,0 +1,68 @@
+package uniqid
+
+import (
+	"bytes"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+)
+
+// TestNextULID tests generating standard ULIDs
+func TestNextULID(t *testing.T) {
+	// Test case 1: The encoding matches the ULID specification's example
+	var out [26]byte
+	encodeULID(&out, 1469922850259<<16, 0)
+	if got := string(out[:10]); got != "01ARZ3NDEK" {
+		t.Errorf("Expected timestamp 01ARZ3NDEK, got %q", got)
+	}
+
+	mockTime := time.Now().UnixMilli()
+	gen, err := New(&Config{ShardID: 7})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	// Test case 2: ULIDs carry the Unix millisecond time and increase
+	prev := ""
+	for i := 0; i < 2000; i++ {
+		if i%500 == 0 {
+			mockTime++
+		}
+		id, err := gen.NextULID()
+		if err != nil {
+			t.Fatalf("NextULID failed: %v", err)
+		}
+		if len(id) != 26 || id[0] > '7' {
+			t.Fatalf("Invalid ULID %q", id)
+		}
+		var ms int64
+		for j := 0; j < 10; j++ {
+			ms = ms<<5 | int64(strings.IndexByte(crockfordAlphabet, id[j]))
+		}
+		if ms != mockTime {
+			t.Fatalf("ULID %q has time %d, expected %d", id, ms, mockTime)
+		}
+		if id <= prev {
+			t.Fatalf("ULID %q does not sort after %q", id, prev)
+		}
+		prev = id
+	}
+
+	// Test case 3: Random bits come from the configured source
+	src, _ := New(&Config{ShardID: 7, RandReader: bytes.NewReader(make([]byte, 6))})
+	id, err := src.NextULID()
+	if err != nil || !strings.HasSuffix(id, "0000000000") {
+		t.Errorf("Expected zero random bits, got %q, %v", id, err)
+	}
+	if _, err := src.NextULID(); err == nil {
+		t.Error("Expected error from exhausted random source, got nil")
+	}
+
+	// Test case 4: Random source failures are reported
+	gen.deps.randFunc = func([]byte) (int, error) { return 0, errors.New("rand error") }
+	if _, err := gen.NextULID(); err == nil {
+		t.Error("Expected error from failing random source, got nil")
+	}
+}
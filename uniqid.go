@@ -1,28 +1,392 @@
 package uniqid
 
 import (
+	"cmp"
+	"context"
 	"crypto/rand"
 	"encoding/binary"
 	"errors"
+	"fmt"
 	"hash/fnv"
+	"io"
 	"net"
+	"net/http"
 	"os"
 	"runtime"
+	"strconv"
+	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 )
 
 const alphabet = "ABCDEFGHIJKLMNOPQRSTUVWXYZabcdefghijklmnopqrstuvwxyz0123456789-_"
 const defaultEpochMs = int64(1577836800000) // 2020-01-01
+const defaultSpinSleep = 10 * time.Microsecond
+const defaultMaxBlockSize = 1 << 16
+const maxPrefixLen = 16
+
+// ErrSequenceExhausted is returned by TryNext, and by NextE under
+// OverflowError, when all sequence numbers for the current millisecond
+// have been used.
+var ErrSequenceExhausted = errors.New("sequence exhausted for current millisecond")
+
+// ErrBeforeEpoch is returned by TryNext, and by NextE under
+// OverflowError, when the clock reads earlier than the generator's
+// epoch. Next waits for the epoch instead; see WaitUntilEpoch.
+var ErrBeforeEpoch = errors.New("clock is before the epoch")
 
 // Config defines options for creating a Generator.
 //
 // Fields:
 //   - ShardID: Node identifier [0..1023]. Use -1 to auto-detect.
-//   - CustomEpochMs: Custom epoch in milliseconds (default = Unix epoch).
+//   - CustomEpochMs: Custom epoch in milliseconds
+//     (default = 2020-01-01).
+//   - UnixEpoch: Use the Unix epoch, which CustomEpochMs 0 cannot
+//     express.
+//   - Name: Optional label identifying the generator in metrics and logs.
+//   - SpinSleep: Sleep between clock polls while waiting for the next
+//     millisecond (0 = platform default, negative = yield only).
+//   - RandReader: Entropy source for the random auto-shard fallback
+//     (default = crypto/rand).
+//   - Prefix: Optional type prefix prepended to every ID, e.g. "ord_"
+//     (default = none).
+//   - Checksum: Append a check character to every ID to catch typos.
+//   - VersionPrefix: Optional character prepended to every ID
+//     (0 = none).
+//   - CachedClock: Read time from a shared, periodically refreshed
+//     clock instead of the system clock.
+//   - Clock: Time source (default = the system clock).
+//   - StateStore: Where to persist the last issued timestamp across
+//     restarts (default = none).
+//   - StateFile: Path of a file to use as StateStore.
+//   - WallClock: Follow the system wall clock as it is adjusted,
+//     instead of the monotonic clock anchored at creation.
+//   - Layout: Bit layout of the packed value (default = DefaultLayout).
+//   - NoShard: Drop the shard field for shorter single-node IDs.
+//   - BatchClockEvery: Re-read the clock every N IDs in NextN
+//     (0 = once per batch).
+//   - TokenKey: Secret key enabling NextToken.
+//   - SigningKey: Secret key for signing every ID (default = unsigned).
+//   - ObfuscationKey: Secret key for encrypting the value of every ID
+//     (default = none).
+//   - ByteOrder: Byte order of binary IDs (default = big-endian).
+//   - TimestampUnit: Time represented by one tick of the timestamp
+//     field (default = 1ms).
+//   - OnShardConflict: What NewMultiShard does with shards already
+//     reserved in this process (default = ShardConflictError).
+//   - TrackHistogram: Record how many IDs each active millisecond
+//     produced, for Histogram and P99PerMs.
+//   - Alphabet: 64 characters used to encode IDs (default = URL-safe
+//     base64 characters, A-Z a-z 0-9 - _).
+//   - MaxBlockSize: Largest block ReserveBlock hands out
+//     (0 = 65536).
+//   - ShardSource: Input used to auto-derive the shard ID
+//     (default = ShardSourceAuto).
+//   - ShardMetadataTag: Instance tag holding the shard number, for the
+//     cloud shard sources (default = hash the instance ID).
+//   - ShardProvider: Custom source of the shard ID, used instead of
+//     ShardID (default = none).
+//   - CheckClockResolution: Make New fail if the clock advances in
+//     steps coarser than 2ms.
+//   - Salt: Environment marker stored in the layout's reserved bits
+//     (0 = none).
+//   - BannedSubstrings: Substrings that generated IDs must not
+//     contain (default = none).
+//   - RandomizeSequence: Start each millisecond's sequence at a random
+//     offset instead of 0.
+//   - BurstOverflow: Absorb bursts in the layout's reserved bits
+//     instead of waiting when the sequence runs out.
+//   - RefreshShardInterval: How often to re-derive an auto shard ID
+//     (0 = never).
+//   - OnShardChange: Called after a refresh changes the shard ID.
+//   - Encoding: How IDs are rendered as text
+//     (default = EncodingBase64).
+//   - OverflowPolicy: What to do when the sequence runs out
+//     (default = OverflowSpin).
+//   - WaitStrategy: How to wait for the next millisecond when the
+//     sequence runs out (default = WaitSpinSleep).
+//   - ClockDriftPolicy: What NextE does when the clock moves backwards
+//     (default = ClockDriftTolerate).
+//   - MaxClockDriftMs: Clock regression tolerated before
+//     ClockDriftPolicy and OnClockDrift apply, in milliseconds
+//     (default = 0).
+//   - OnClockDrift: Called when the clock moves backwards by more than
+//     MaxClockDriftMs.
+//   - LockFree: Claim sequence slots with an atomic compare-and-swap
+//     instead of a mutex.
 type Config struct {
-	ShardID       int
-	CustomEpochMs int64
+	ShardID              int
+	CustomEpochMs        int64
+	UnixEpoch            bool
+	Name                 string
+	SpinSleep            time.Duration
+	RandReader           io.Reader
+	Prefix               string
+	Checksum             bool
+	VersionPrefix        byte
+	CachedClock          bool
+	Clock                Clock
+	WallClock            bool
+	StateStore           StateStore
+	StateFile            string
+	Layout               Layout
+	NoShard              bool
+	BatchClockEvery      int
+	TokenKey             []byte
+	SigningKey           []byte
+	ObfuscationKey       []byte
+	ByteOrder            binary.ByteOrder
+	TimestampUnit        time.Duration
+	OnShardConflict      ShardConflictPolicy
+	TrackHistogram       bool
+	Alphabet             string
+	MaxBlockSize         int
+	ShardSource          ShardSource
+	ShardMetadataTag     string
+	ShardProvider        ShardProvider
+	CheckClockResolution bool
+	Salt                 uint16
+	BannedSubstrings     []string
+	RandomizeSequence    bool
+	BurstOverflow        bool
+	RefreshShardInterval time.Duration
+	OnShardChange        func(name string, oldShard, newShard uint16)
+	Encoding             Encoding
+	OverflowPolicy       OverflowPolicy
+	WaitStrategy         WaitStrategy
+	ClockDriftPolicy     ClockDriftPolicy
+	MaxClockDriftMs      int64
+	OnClockDrift         func(drift time.Duration)
+	LockFree             bool
+}
+
+// ShardProvider supplies the shard ID for a generator; see
+// Config.ShardProvider. ShardID is called by New, and by the refresh
+// goroutine with Config.RefreshShardInterval, so it must be safe for
+// concurrent use.
+type ShardProvider interface {
+	ShardID(ctx context.Context) (uint16, error)
+}
+
+// ShardSource selects the input used to derive the shard ID when
+// Config.ShardID is -1.
+type ShardSource int
+
+const (
+	// ShardSourceAuto uses the MAC address of the only network
+	// interface; with several interfaces, a hash of all their MAC
+	// addresses and the hostname, so that cloned VMs sharing one MAC
+	// still differ. Without interfaces it falls back to the hostname,
+	// then to randomness.
+	ShardSourceAuto ShardSource = iota
+	// ShardSourceFirstMAC uses the first non-loopback interface's MAC
+	// address only.
+	ShardSourceFirstMAC
+	// ShardSourceAllMACs hashes the MAC addresses of all non-loopback
+	// interfaces.
+	ShardSourceAllMACs
+	// ShardSourceHostname uses the hostname only.
+	ShardSourceHostname
+	// ShardSourceRandom picks a random shard.
+	ShardSourceRandom
+	// ShardSourceKubernetes uses the ordinal of a Kubernetes
+	// StatefulSet pod as the shard, unhashed. It reads the
+	// PodOrdinalEnv environment variable, or else parses the hostname
+	// ("web-0", "web-1", ...) when running in Kubernetes. Ordinals are
+	// unique and stable only within one StatefulSet: they start at 0
+	// in every StatefulSet, so pods of different StatefulSets, or of
+	// other workloads, sharing an ID space get the same shards. Use it
+	// only where one StatefulSet generates the IDs, or set
+	// PodOrdinalEnv to disjoint ranges per StatefulSet.
+	ShardSourceKubernetes
+	// ShardSourceAWS hashes the EC2 instance ID, read from the
+	// instance metadata service (IMDSv2), or the task ARN when
+	// running as an ECS task. Unlike virtual MAC addresses, these are
+	// unique per instance and task.
+	ShardSourceAWS
+	// ShardSourceGCP hashes the Compute Engine instance ID from the
+	// GCP metadata server.
+	ShardSourceGCP
+	// ShardSourceAzure hashes the VM ID from the Azure Instance
+	// Metadata Service.
+	ShardSourceAzure
+)
+
+// PodOrdinalEnv is the environment variable ShardSourceKubernetes reads
+// for the StatefulSet pod ordinal, e.g. set with the downward API from
+// the pod-index label:
+//
+//	env:
+//	- name: POD_INDEX
+//	  valueFrom:
+//	    fieldRef:
+//	      fieldPath: metadata.labels['apps.kubernetes.io/pod-index']
+const PodOrdinalEnv = "POD_INDEX"
+
+// Validate checks the configuration for invalid values and
+// contradictory options, returning an error naming the problem.
+// New calls it before creating a generator.
+func (c *Config) Validate() error {
+	layout := c.layout()
+	if err := layout.Validate(); err != nil {
+		return err
+	}
+	if c.NoShard {
+		if layout.ShardBits != 0 {
+			return errors.New("noShard conflicts with a layout that has shard bits")
+		}
+		if c.ShardID > 0 {
+			return errors.New("noShard conflicts with an explicit shardID")
+		}
+	} else if c.ShardID > layout.MaxShard() {
+		return fmt.Errorf("shardID must be 0..%d", layout.MaxShard())
+	}
+	if c.Encoding < EncodingBase64 || c.Encoding > EncodingCrockford {
+		return errors.New("unknown encoding")
+	}
+	if c.Alphabet != "" {
+		if c.Encoding != EncodingBase64 {
+			return errors.New("alphabet only applies to EncodingBase64")
+		}
+		if err := ValidateAlphabet(c.Alphabet); err != nil {
+			return err
+		}
+	}
+	if err := validatePrefix(c.Prefix); err != nil {
+		return err
+	}
+	if len(c.SigningKey) > 0 && len(c.SigningKey) < minSigningKeyLen {
+		return fmt.Errorf("signingKey must be at least %d bytes", minSigningKeyLen)
+	}
+	if len(c.ObfuscationKey) > 0 && len(c.ObfuscationKey) < minSigningKeyLen {
+		return fmt.Errorf("obfuscationKey must be at least %d bytes", minSigningKeyLen)
+	}
+	if len(c.TokenKey) > 0 && len(c.TokenKey) < minSigningKeyLen {
+		return fmt.Errorf("tokenKey must be at least %d bytes", minSigningKeyLen)
+	}
+	if c.VersionPrefix != 0 && strings.IndexByte(c.alphabet(), c.VersionPrefix) < 0 {
+		return errors.New("versionPrefix must be a character of the ID alphabet")
+	}
+	if c.TimestampUnit < 0 || c.TimestampUnit%time.Millisecond != 0 {
+		return errors.New("timestampUnit must be a positive multiple of 1ms")
+	}
+	if c.ShardSource < ShardSourceAuto || c.ShardSource > ShardSourceAzure {
+		return errors.New("unknown shardSource")
+	}
+	if c.ShardProvider != nil && (c.NoShard || c.ShardID > 0) {
+		return errors.New("shardProvider conflicts with noShard or an explicit shardID")
+	}
+	if c.ShardMetadataTag != "" && c.ShardSource < ShardSourceAWS {
+		return errors.New("shardMetadataTag needs a cloud shardSource")
+	}
+	if uint64(c.Salt) > layout.MaxSalt() {
+		return fmt.Errorf("salt %d does not fit the layout's %d reserved bits", c.Salt, layout.ReservedBits)
+	}
+	if c.BurstOverflow {
+		if layout.ReservedBits == 0 {
+			return errors.New("burstOverflow needs a layout with reserved bits")
+		}
+		if c.Salt != 0 {
+			return errors.New("burstOverflow conflicts with salt, both use the reserved bits")
+		}
+	}
+	if c.RefreshShardInterval < 0 {
+		return errors.New("refreshShardInterval must not be negative")
+	}
+	if c.OverflowPolicy < OverflowSpin || c.OverflowPolicy > OverflowError {
+		return errors.New("unknown overflowPolicy")
+	}
+	if c.WaitStrategy < WaitSpinSleep || c.WaitStrategy > WaitTimer {
+		return errors.New("unknown waitStrategy")
+	}
+	if c.WaitStrategy != WaitSpinSleep && c.OverflowPolicy == OverflowSleep {
+		return errors.New("waitStrategy conflicts with overflowPolicy OverflowSleep")
+	}
+	if c.ClockDriftPolicy < ClockDriftTolerate || c.ClockDriftPolicy > ClockDriftError {
+		return errors.New("unknown clockDriftPolicy")
+	}
+	if c.MaxClockDriftMs < 0 {
+		return errors.New("maxClockDriftMs must not be negative")
+	}
+	if c.LockFree {
+		switch {
+		case layout.TimestampBits > 48:
+			return errors.New("lockFree needs a layout with at most 48 timestamp bits")
+		case layout.CounterBits > 0:
+			return errors.New("lockFree conflicts with a layout that has counter bits")
+		case c.TrackHistogram:
+			return errors.New("lockFree conflicts with trackHistogram")
+		case c.BurstOverflow:
+			return errors.New("lockFree conflicts with burstOverflow")
+		case c.RefreshShardInterval > 0:
+			return errors.New("lockFree conflicts with refreshShardInterval")
+		}
+	}
+	if c.UnixEpoch && c.CustomEpochMs != 0 {
+		return errors.New("unixEpoch conflicts with customEpochMs")
+	}
+	if c.StateStore != nil && c.StateFile != "" {
+		return errors.New("stateStore conflicts with stateFile")
+	}
+	for _, b := range c.BannedSubstrings {
+		if len(b) < 2 {
+			return fmt.Errorf("banned substring %q must be at least 2 characters", b)
+		}
+	}
+	if c.LockFree && len(c.BannedSubstrings) > 0 {
+		return errors.New("lockFree conflicts with bannedSubstrings")
+	}
+	if c.LockFree && (c.StateStore != nil || c.StateFile != "") {
+		return errors.New("lockFree conflicts with state persistence")
+	}
+	if c.Clock != nil && c.CachedClock {
+		return errors.New("clock conflicts with cachedClock")
+	}
+	if c.MaxBlockSize < 0 {
+		return errors.New("maxBlockSize must not be negative")
+	}
+	if c.OnShardConflict < ShardConflictError || c.OnShardConflict > ShardConflictAllow {
+		return errors.New("unknown onShardConflict policy")
+	}
+	return nil
+}
+
+// alphabet returns the effective alphabet: Crockford's for
+// EncodingCrockford, else Alphabet if set, otherwise the default.
+// Not exported.
+func (c *Config) alphabet() string {
+	if c.Encoding == EncodingCrockford {
+		return crockfordAlphabet
+	}
+	if c.Alphabet != "" {
+		return c.Alphabet
+	}
+	return alphabet
+}
+
+// layout returns the effective layout: Layout if set, otherwise
+// the default for the NoShard setting.
+// Not exported.
+func (c *Config) layout() Layout {
+	switch {
+	case c.Layout != (Layout{}):
+		return c.Layout
+	case c.NoShard:
+		return NoShardLayout
+	default:
+		return DefaultLayout
+	}
+}
+
+// epoch returns the effective epoch in Unix milliseconds: 0 with
+// UnixEpoch, else CustomEpochMs if set, otherwise the default.
+// Not exported.
+func (c *Config) epoch() int64 {
+	if c.UnixEpoch {
+		return 0
+	}
+	return cmp.Or(c.CustomEpochMs, defaultEpochMs)
 }
 
 // Generator produces unique, time-sortable IDs.
@@ -30,12 +394,81 @@ type Config struct {
 type Generator struct {
 	mu        sync.Mutex
 	lastMs    int64
-	seq       uint16
+	seq       uint32
 	shard     uint16
 	baseEpoch int64
+	layout    Layout
+	name      string
+	spinSleep time.Duration
+	prefix    string
+	checksum  bool
+	version   byte
+	batchRead int
+	unit      int64
+	keyed     map[uint16]*keyedSeq
+	hist      *histogram
+	codec     *codec
+	maxBlock  int
+	salt      uint16
+	overflow  bool
+	randSeq   bool
+	banned    []string
+	reroll    bool
+	ovf       uint16
+	stop      chan struct{}
+	stopped   chan struct{}
+	stopOnce  sync.Once
+	nextShard uint16
+	switching bool
+	policy    OverflowPolicy
+	wait      waitFunc
+	drift     ClockDriftPolicy
+	maxDrift  int64
+	onDrift   func(time.Duration)
+	driftSeen atomic.Bool
+	tokenKey  []byte
+	signKey   []byte
+	obfKey    []byte
+	order     binary.ByteOrder
+	cfg       Config
+	stats     Stats
+	issued    uint64
+	counter   uint64
+	reuse     bool
+	manual    bool
+	provider  ShardProvider
+	store     StateStore
+	stateMark int64
+	markTick  int64
+	manualMs  atomic.Int64
+	lockFree  bool
+	word      atomic.Uint64
+	astats    atomicStats
 	deps      deps
 }
 
+// Stats holds counters describing a generator's activity since it was
+// created. All counters are monotonically increasing.
+//
+// Fields:
+//   - Generated: Total number of IDs produced.
+//   - Rollovers: Times the per-millisecond sequence was exhausted and
+//     the generator had to wait for the next millisecond.
+//   - ClockBackwards: Times the system clock was observed moving
+//     backwards relative to the last issued timestamp. A clock before
+//     the epoch is not counted.
+//   - Overflows: IDs issued from the reserved bits after the sequence
+//     ran out, with Config.BurstOverflow.
+//   - Skipped: Sequence slots left unused because their ID contained
+//     one of Config.BannedSubstrings; not counted in Generated.
+type Stats struct {
+	Generated      uint64
+	Rollovers      uint64
+	ClockBackwards uint64
+	Overflows      uint64
+	Skipped        uint64
+}
+
 var autoShardFunc = autoShardWithDeps
 
 // New creates a new ID generator with the given configuration.
@@ -46,9 +479,310 @@ var autoShardFunc = autoShardWithDeps
 //     If set to -1, the shard ID will be auto-derived from
 //     network interface, hostname, or randomness.
 //   - CustomEpochMs (int64):
-//     Custom epoch timestamp in milliseconds (default is Unix epoch).
-//     Useful if you want to shorten IDs by moving the epoch closer
-//     to the present time.
+//     Custom epoch timestamp in Unix milliseconds (0 selects the
+//     default, 2020-01-01). Useful if you want to shorten IDs by
+//     moving the epoch closer to the present time.
+//   - UnixEpoch (bool):
+//     Use the Unix epoch (1970-01-01) itself. CustomEpochMs must then
+//     be 0.
+//   - SpinSleep (time.Duration):
+//     How long to sleep between clock polls when the per-millisecond
+//     sequence is exhausted. Zero picks a platform default: 10µs, or
+//     no sleep at all on platforms where sub-millisecond sleeps round
+//     up to a scheduler tick (Windows). A negative value disables the
+//     sleep so the wait only yields with runtime.Gosched.
+//   - RandReader (io.Reader):
+//     Source of randomness used when the shard ID falls back to a
+//     random value, e.g. a hardware RNG. It is read with io.ReadFull.
+//     Defaults to crypto/rand.
+//   - Prefix (string):
+//     A type prefix prepended to every ID, before any VersionPrefix,
+//     for self-describing IDs such as "ord_Ab3Xyz0LmN_" that show
+//     what they refer to in logs and support tickets. Generator.Parse
+//     and ParseWith strip it and reject IDs without it, so an order ID
+//     cannot be passed where an invoice ID is expected. At most 16
+//     bytes of printable ASCII, without spaces. Package-level
+//     functions such as Parse and ParseID do not accept prefixed IDs.
+//   - Checksum (bool):
+//     Append a check character to every ID, making it one character
+//     longer, so IDs typed by people (from invoices, over the phone)
+//     can be checked with Verify before a database lookup. It is the
+//     Luhn mod N character over the encoded ID, N being the alphabet
+//     size, and catches every single mistyped character and most
+//     swaps of adjacent characters. Generator.Parse and ParseWith
+//     check and strip it, failing with ErrChecksumMismatch.
+//   - VersionPrefix (byte):
+//     A stable character prepended to every ID, making the ID 12
+//     characters long (e.g. '1'). It lets services version their ID
+//     scheme so old and new IDs are distinguishable at a glance and
+//     in routing. Generator.Parse strips and checks it. Must be a
+//     character of the ID alphabet, so IDs stay URL-path safe.
+//   - CachedClock (bool):
+//     Read the current time from a process-wide clock refreshed every
+//     250µs by a background goroutine, so generating an ID costs an
+//     atomic load instead of a system clock read. Timestamps may lag
+//     real time by up to 250µs; uniqueness and monotonicity are kept.
+//     Useful under heavy contention, where every clock read happens
+//     while holding the generator's lock.
+//   - Clock (Clock):
+//     Where the generator reads the current time, instead of the
+//     system clock, e.g. a ManualClock in tests or a simulated clock
+//     in replays. Cannot be combined with CachedClock.
+//   - StateStore (StateStore):
+//     Persists a high-water mark one second ahead of the timestamps
+//     issued, including by NextForKey, and on creation makes the
+//     generator and each key shard resume after the stored mark, so a
+//     restart followed by a backwards clock correction cannot reissue
+//     IDs. If the clock is behind the mark, IDs wait for it as for any
+//     clock regression (NextE under ClockDriftError fails instead).
+//     The store is written at most about once a second, holding the
+//     generator's lock; a failed write is retried with the next
+//     millisecond's first ID. Use one store per generator. Cannot be
+//     combined with LockFree.
+//   - StateFile (string):
+//     Shortcut for StateStore: NewFileStateStore(StateFile).
+//   - WallClock (bool):
+//     By default the generator reads the wall clock once, when it is
+//     created, and measures time from there with the monotonic clock,
+//     so NTP steps and VM clock jumps cannot move its timestamps
+//     backwards; IDs stay sortable across restarts because each
+//     process starts from the wall clock. Timestamps then drift from
+//     wall time as the monotonic clock does, and on some platforms
+//     (e.g. Linux) it stops while the machine is suspended. Set
+//     WallClock to read the wall clock on every ID instead, as
+//     CachedClock does.
+//   - Layout (Layout):
+//     How the packed value is split into timestamp, shard, sequence
+//     and reserved bits. The zero value selects DefaultLayout. ShardID
+//     must fit the layout's shard field; auto-derived shard IDs are
+//     reduced to fit it.
+//   - NoShard (bool):
+//     For single-node deployments: use a layout without a shard field
+//     (NoShardLayout unless Layout is set), giving shorter 10-character
+//     IDs with a wider timestamp and sequence. ShardID must be left
+//     0 or -1, and Parse reports shard 0. IDs generated with and
+//     without a shard field are not interchangeable.
+//   - BatchClockEvery (int):
+//     How often NextN re-reads the clock within a batch. With 0 the
+//     clock is read once and the batch fills the sequence, reading it
+//     again only when the sequence is exhausted; this is fastest, but
+//     timestamps in very large batches can lag real time. With N > 0
+//     the clock is re-read every N IDs, keeping timestamps closer to
+//     real time at some cost in throughput. IDs are monotonic either
+//     way.
+//   - TokenKey ([]byte):
+//     Secret key, at least 16 bytes, for NextToken and
+//     Generator.DecodeToken. Tokens are IDs run through a keyed
+//     permutation, so they cannot be guessed or decoded without the
+//     key.
+//   - SigningKey ([]byte):
+//     Secret key, at least 16 bytes, for appending an 8-character
+//     signature to every ID: a truncated HMAC-SHA256 of the ID's
+//     value and Prefix. Public IDs, e.g. in URLs, then cannot be
+//     forged or altered without the key, and Generator.VerifySigned
+//     rejects tampered ones without a database lookup. Generator.Parse
+//     and ParseWith check and strip the signature, failing with
+//     ErrSignatureMismatch. The signature adds 48 bits (40 with
+//     EncodingCrockford) of protection against guessing; the ID
+//     itself stays readable; set ObfuscationKey as well to hide it.
+//   - ObfuscationKey ([]byte):
+//     Secret key, at least 16 bytes, for encrypting the packed value
+//     of every ID with a keyed permutation of the layout's width
+//     before encoding, so outsiders cannot read the creation time,
+//     shard or issue rate from IDs. The IDs keep their length and
+//     stay unique, but are no longer time-sortable. Generator.Parse
+//     and ParseWith with the same key recover the components. Only
+//     the text IDs are encrypted: binary, numeric and UUID forms, such
+//     as NextBinary and NextUUIDv7, expose the plain value, and the
+//     bounds from MinIDForTime and MaxIDForTime are meaningless.
+//     Generating an ID costs about 8 HMAC-SHA256 computations more.
+//   - ByteOrder (binary.ByteOrder):
+//     Byte order of the 8-byte IDs returned by NextBinary and read by
+//     Generator.ParseBinary, and of the random bytes the auto-shard
+//     fallback decodes. Defaults to binary.BigEndian, under which the
+//     raw bytes sort in generation order like the string form does.
+//     binary.LittleEndian matches some existing on-disk formats but
+//     loses that property: little-endian bytes do not sort by time.
+//   - TimestampUnit (time.Duration):
+//     How much time one tick of the timestamp field represents; must
+//     be a multiple of 1ms (default 1ms). A coarser unit such as 4ms
+//     multiplies the layout's horizon by 4 without widening the
+//     timestamp field, at the cost of precision: decoded times are
+//     rounded down to the unit, and all IDs within one tick share its
+//     sequence space, so peak throughput per millisecond drops by the
+//     same factor. Parse with a generator configured with the same
+//     unit.
+//   - OnShardConflict (ShardConflictPolicy):
+//     Only used by NewMultiShard: how to handle a requested shard
+//     that is already reserved in this process's shard registry.
+//     ShardConflictError (default) fails, ShardConflictSkipOccupied
+//     substitutes the next free shard within the layout's range, and
+//     ShardConflictAllow uses it anyway.
+//   - TrackHistogram (bool):
+//     Count how many IDs were generated in each active timestamp tick
+//     (millisecond by default), exposed by Histogram and P99PerMs, to
+//     see whether a node approaches the per-millisecond ceiling. It
+//     costs a map update under the generator's lock once per tick.
+//   - Alphabet (string):
+//     The 64 characters IDs are encoded with, in digit order. It must
+//     pass ValidateAlphabet: 64 distinct printable ASCII characters,
+//     no whitespace. Generator.Parse decodes with it; the package-level
+//     Parse only understands the default alphabet. SortableAlphabet
+//     makes IDs sort as strings in generation order.
+//   - MaxBlockSize (int):
+//     The largest size ReserveBlock accepts (default 65536). Other
+//     callers wait while a block is generated, so this bounds their
+//     worst-case latency.
+//   - ShardSource (ShardSource):
+//     Which input auto-derivation (ShardID -1) hashes into a shard.
+//     ShardSourceAuto, the default, prefers MAC addresses, combining
+//     all of them with the hostname when there are several
+//     interfaces, then the hostname, then randomness. A StatefulSet
+//     pod ordinal is only used with ShardSourceKubernetes, as
+//     ordinals repeat across StatefulSets. The other sources use only
+//     the named input and make New fail if it is unavailable, except
+//     ShardSourceRandom. The cloud sources (ShardSourceAWS,
+//     ShardSourceGCP, ShardSourceAzure) query the instance metadata
+//     service, which New waits up to 2s per request for.
+//     Use AutoShardDebug to see which input won.
+//   - ShardMetadataTag (string):
+//     With a cloud ShardSource, the name of an instance tag (on GCP,
+//     a metadata attribute) whose value is the shard number to use,
+//     e.g. assigned by the provisioning tooling. It is used as is
+//     rather than hashed, so distinct values never collide. AWS needs
+//     tags in instance metadata enabled.
+//   - ShardProvider (ShardProvider):
+//     Asked for the shard ID instead of ShardID or auto-derivation, to
+//     plug in an allocation strategy of your own, e.g. a config
+//     service or a database row lock. New fails if it returns an error
+//     or a shard beyond the layout's maximum. ShardID must be left 0
+//     (or -1). RefreshShardInterval asks it again periodically.
+//   - CheckClockResolution (bool):
+//     Probe the clock in New and return ErrCoarseClock if it advances
+//     in steps coarser than 2ms. On such platforms (some older
+//     systems tick every ~15ms) each sequence covers the whole step,
+//     so it runs out far sooner than expected and Next waits often.
+//     The probe takes a few milliseconds.
+//   - Salt (uint16):
+//     A per-environment value (e.g. 1 = staging, 2 = production)
+//     stored in the reserved bits of every ID, so IDs leaking from one
+//     environment into another can be detected: Generator.Parse and
+//     ParseWith reject IDs whose salt differs with ErrSaltMismatch,
+//     and Parts.Salt exposes it. The layout needs enough ReservedBits
+//     to hold the value.
+//   - BannedSubstrings ([]string):
+//     Substrings, such as profanity or runs of look-alike characters
+//     (see LookAlikeRuns), that customer-facing IDs printed on
+//     documents must not contain. They match regardless of letter
+//     case, anywhere after the Prefix and VersionPrefix. An ID that
+//     contains one is never issued, by any method. If the layout has
+//     ReservedBits not used by Salt or BurstOverflow, the generator
+//     first re-rolls them, trying the values 1, 2, ... in turn, so
+//     the ID keeps its sequence slot; Generator.Parse and ParseWith
+//     with the same settings ignore them. Otherwise, or if no value
+//     helps, it skips the slot and takes the next. Either way no ID
+//     is repeated, and the outcome depends only on the IDs
+//     themselves. Each entry must be at least 2 characters; long
+//     lists cost capacity and time, as every ID is checked against
+//     all of them. An entry matching the ID's slowly changing
+//     timestamp characters would ban every slot for a while, so after
+//     skipping a tick's worth of slots (Layout.MaxSequence+1) in a
+//     row the generator gives up: NextE, TryNext, NextCtx and
+//     NextTagged return ErrTooManyBanned, while methods that cannot
+//     fail, like Next and NextForKey, wait for the next tick and try
+//     again, indefinitely if the entry matches the shard's characters.
+//     ReserveRange returns ErrTooManyBanned if an ID of its range is
+//     banned and cannot be re-rolled. Cannot be combined with LockFree.
+//   - RandomizeSequence (bool):
+//     Start each millisecond's sequence at a random offset in the
+//     lower half of its range instead of at 0, so the sequence numbers
+//     of sampled IDs do not reveal how many IDs were issued (the
+//     "German tank problem"). IDs within a millisecond still increase.
+//     The cost is capacity: in the worst case only half the sequence
+//     range is left for a millisecond before the generator waits for
+//     the next, and ReserveRange accepts at most half a tick's slots.
+//   - BurstOverflow (bool):
+//     When the sequence for the current millisecond runs out, keep
+//     issuing IDs instead of waiting: the sequence stays at its
+//     maximum and an overflow count is stored in the layout's reserved
+//     bits, giving up to Layout.MaxSalt extra IDs per millisecond
+//     (e.g. 255 with 8 reserved bits). The count resets on the next
+//     millisecond, and the generator only waits once it is used up.
+//     IDs stay unique and sortable; Parts.Overflow reports the count.
+//     Needs ReservedBits and cannot be combined with Salt. IDs from
+//     NextForKey do not overflow.
+//   - RefreshShardInterval (time.Duration):
+//     Only used when the shard ID is auto-derived (ShardID -1) or
+//     comes from ShardProvider: re-run the derivation this often in
+//     a background goroutine, so a long-running process whose
+//     network changes (a laptop resuming from sleep, a re-addressed
+//     container) picks up its new shard. A changed shard takes effect
+//     at the start of a millisecond, before its first ID, so every
+//     later ID has a newer timestamp and IDs from one generator stay
+//     sortable across the switch. The old shard may be derived by
+//     another node afterwards, so IDs issued before the switch are
+//     unique only if that node's clock is not behind.
+//     Call Close to stop the goroutine.
+//   - OnShardChange (func(name string, oldShard, newShard uint16)):
+//     Called with Config.Name after a refresh changed the shard, e.g.
+//     to log it. It runs on the refresh goroutine without the
+//     generator's lock held, at most one interval after the switch.
+//   - Encoding (Encoding):
+//     EncodingBase64 (default) encodes 6 bits per character with
+//     Alphabet. EncodingCrockford encodes 5 bits per character in
+//     Crockford's Base32 for IDs that people read out or type, such as
+//     over the phone: case-insensitive and without I, L, O and U, at
+//     the cost of length (13 characters for a 64-bit layout instead of
+//     11). It cannot be combined with Alphabet, and a VersionPrefix
+//     must be one of its characters. Parse with the generator's Parse
+//     method or ParseWith.
+//   - OverflowPolicy (OverflowPolicy):
+//     How to wait when the sequence for the current millisecond runs
+//     out. OverflowSpin (default) polls the clock, pausing SpinSleep
+//     between polls; OverflowSleep sleeps for the rest of the
+//     millisecond instead, using less CPU at the cost of timer
+//     precision. OverflowError makes NextE fail with
+//     ErrSequenceExhausted instead of waiting; methods that cannot
+//     return an error, such as Next, spin.
+//   - WaitStrategy (WaitStrategy):
+//     How Next and the other blocking methods wait for the next
+//     millisecond once its sequence is used up, trading latency
+//     against CPU use. WaitSpinSleep (default) polls the clock,
+//     yielding and pausing SpinSleep between polls. WaitBusySpin polls
+//     without pausing, for the lowest latency at the cost of a busy
+//     core; WaitYield only yields between polls; WaitBackoff sleeps
+//     for SpinSleep, doubling each poll; WaitTimer sleeps until the
+//     millisecond is due, using the least CPU but adding timer
+//     latency. OverflowSleep implies WaitTimer, so only the default
+//     may be combined with it. NextCtx always polls as WaitSpinSleep
+//     does, to watch its context.
+//   - ClockDriftPolicy (ClockDriftPolicy):
+//     What NextE does when the clock reads earlier than the last ID.
+//     ClockDriftTolerate (default) keeps issuing IDs at the last
+//     timestamp until the clock catches up, as every method does;
+//     ClockDriftError makes NextE fail with ErrClockBackwards instead.
+//     Only regressions larger than MaxClockDriftMs count.
+//   - MaxClockDriftMs (int64):
+//     How far the clock may move backwards, in milliseconds, before
+//     the regression is reported to OnClockDrift and NextE applies
+//     ClockDriftPolicy. Smaller regressions, such as NTP slewing a few
+//     milliseconds, are always tolerated. 0 (default) reports every
+//     regression.
+//   - OnClockDrift (func(drift time.Duration)):
+//     Called with how far the clock lags the last ID when a regression
+//     larger than MaxClockDriftMs is first seen, e.g. to log or alert.
+//     It is called once per regression, not for every ID issued until
+//     the clock catches up. It may run with the generator's lock held,
+//     so it must return quickly and must not call the generator.
+//   - LockFree (bool):
+//     Keep the last timestamp and sequence in one atomic word and
+//     claim slots with compare-and-swap, so Next never takes the
+//     generator's lock. This scales better under heavy contention
+//     from many goroutines. It needs a layout with at most 48
+//     timestamp bits and no counter bits, and cannot be combined with
+//     TrackHistogram, BurstOverflow or RefreshShardInterval. IDs from
+//     NextN may interleave with concurrent calls, ReserveBlock fails,
+//     and a Speculate rollback never hands its slot back.
 //
 // Example:
 //
@@ -62,37 +796,93 @@ var autoShardFunc = autoShardWithDeps
 //	id := gen.Next()
 //	fmt.Println(id) // Example: "Ab3Xyz0LmN_"
 //
-// If cfg is nil, defaults are used (auto shard ID, epoch = 1970).
+// If cfg is nil, defaults are used (auto shard ID, epoch = 2020).
 func New(cfg *Config) (*Generator, error) {
 	if cfg == nil {
 		cfg = &Config{ShardID: -1, CustomEpochMs: defaultEpochMs}
 	}
-	epoch := cfg.CustomEpochMs
-	if epoch == 0 {
-		epoch = defaultEpochMs
+	if err := cfg.Validate(); err != nil {
+		return nil, err
 	}
+	epoch := cfg.epoch()
+	layout := cfg.layout()
 
 	g := &Generator{
 		baseEpoch: epoch,
-		deps: deps{
-			nowFunc:    func() int64 { return time.Now().UnixMilli() },
-			ifacesFunc: net.Interfaces,
-			hostFunc:   os.Hostname,
-			randFunc:   rand.Read,
-		},
+		layout:    layout,
+		name:      cfg.Name,
+		spinSleep: resolveSpinSleep(cfg.SpinSleep, runtime.GOOS),
+		prefix:    cfg.Prefix,
+		checksum:  cfg.Checksum,
+		version:   cfg.VersionPrefix,
+		batchRead: cfg.BatchClockEvery,
+		tokenKey:  append([]byte(nil), cfg.TokenKey...),
+		signKey:   append([]byte(nil), cfg.SigningKey...),
+		obfKey:    append([]byte(nil), cfg.ObfuscationKey...),
+		order:     byteOrder(cfg.ByteOrder),
+		unit:      max(cfg.TimestampUnit.Milliseconds(), 1),
+		hist:      newHistogram(cfg.TrackHistogram),
+		codec:     codecFor(cfg.Encoding, cfg.alphabet()),
+		maxBlock:  cmp.Or(cfg.MaxBlockSize, defaultMaxBlockSize),
+		salt:      cfg.Salt,
+		overflow:  cfg.BurstOverflow,
+		randSeq:   cfg.RandomizeSequence,
+		banned:    lowerAll(cfg.BannedSubstrings),
+		reroll:    cfg.reroll(),
+		policy:    cfg.OverflowPolicy,
+		wait:      waitFor(cfg.WaitStrategy, cfg.OverflowPolicy),
+		drift:     cfg.ClockDriftPolicy,
+		maxDrift:  cfg.MaxClockDriftMs,
+		onDrift:   cfg.OnClockDrift,
+		lockFree:  cfg.LockFree,
+		deps:      newDeps(cfg),
 	}
 
-	if cfg.ShardID >= 0 {
-		if cfg.ShardID > 1023 {
-			return nil, errors.New("shardID must be 0..1023")
+	if cfg.CheckClockResolution {
+		if err := checkClockResolution(g.deps.nowFunc); err != nil {
+			return nil, err
 		}
+	}
+	if err := g.loadState(cfg); err != nil {
+		return nil, err
+	}
+
+	if cfg.NoShard {
+		g.shard = 0
+	} else if cfg.ShardID >= 0 && cfg.ShardProvider == nil {
 		g.shard = uint16(cfg.ShardID)
 	} else {
-		shard, err := autoShardFunc(g.deps)
+		g.provider = cfg.ShardProvider
+		shard, err := g.deriveShard(g.deps)
 		if err != nil {
 			return nil, err
 		}
 		g.shard = shard
+		if cfg.RefreshShardInterval > 0 {
+			g.stop, g.stopped = make(chan struct{}), make(chan struct{})
+			go g.refreshShard(cfg.RefreshShardInterval, cfg.OnShardChange)
+		}
+	}
+
+	g.cfg = *cfg
+	g.cfg.ShardID = int(g.shard)
+	g.cfg.CustomEpochMs = epoch
+	g.cfg.UnixEpoch = epoch == 0
+	g.cfg.Layout = layout
+	g.cfg.TokenKey = g.tokenKey
+	g.cfg.SigningKey = g.signKey
+	g.cfg.ObfuscationKey = g.obfKey
+	g.cfg.ByteOrder = g.order
+	g.cfg.TimestampUnit = time.Duration(g.unit) * time.Millisecond
+	if cfg.Encoding == EncodingBase64 {
+		g.cfg.Alphabet = g.codec.alphabet
+	}
+	g.cfg.MaxBlockSize = g.maxBlock
+	if g.cfg.SpinSleep == 0 {
+		g.cfg.SpinSleep = g.spinSleep
+		if g.spinSleep == 0 {
+			g.cfg.SpinSleep = -1
+		}
 	}
 
 	return g, nil
@@ -114,11 +904,20 @@ var newFunc = New
 //
 //	id, err := uniqid.Gen()
 //
-// If a config is provided, it creates a new generator for this specific
-// call. This is useful for one-off ID generation with special settings.
+// If a config is provided, the ID comes from a generator kept for that
+// config, so repeated calls with an equal config never collide. This
+// is useful for occasional IDs with special settings. Configs are
+// compared by value, ignoring function and interface fields such as
+// Clock, ShardProvider and the hooks.
 // Example:
 //
 //	id, err := uniqid.Gen(&uniqid.Config{ShardID: 2})
+//
+// Generators for the 64 most recently used configs are kept. Calling
+// Gen with frequently changing configs is an anti-pattern: entries are
+// evicted and recreated, losing their sequence state, and if that
+// happens too often Gen returns ErrGenCacheThrash. Create generators
+// with New and reuse them instead.
 func Gen(cfgs ...*Config) (string, error) {
 	if len(cfgs) == 0 {
 		defaultGenOnce.Do(func() {
@@ -129,7 +928,7 @@ func Gen(cfgs ...*Config) (string, error) {
 		}
 		return defaultGen.Next(), nil
 	}
-	g, err := newFunc(cfgs[0])
+	g, err := genConfigs.get(cfgs[0])
 	if err != nil {
 		return "", err
 	}
@@ -142,32 +941,341 @@ func Gen(cfgs ...*Config) (string, error) {
 //   - Collision-free (with 15-bit sequence per millisecond)
 //   - Shard-aware (10-bit shard ID)
 //
+// Field widths and ID length above are for DefaultLayout; see Layout.
+//
+// If the sequence for the current millisecond is exhausted, Next
+// waits for the next millisecond, and before the epoch it waits for
+// the epoch. Use TryNext to fail fast instead.
+//
 // Example output: "Ab3Xyz0LmN_"
 func (g *Generator) Next() string {
+	val, _ := g.next(true)
+	return g.format(val)
+}
+
+// TryNext is like Next but never waits. If the sequence for the
+// current millisecond is exhausted it returns ErrSequenceExhausted
+// immediately, letting the caller back off or shed load, and if the
+// clock is before the epoch it returns ErrBeforeEpoch.
+//
+// Example:
+//
+//	id, err := gen.TryNext()
+//	if errors.Is(err, uniqid.ErrSequenceExhausted) {
+//	    // at capacity for this millisecond, retry later
+//	}
+func (g *Generator) TryNext() (string, error) {
+	val, err := g.next(false)
+	if err != nil {
+		return "", err
+	}
+	return g.format(val), nil
+}
+
+// NextN generates n IDs in one call, holding the generator's lock for
+// the whole batch so the IDs are consecutive. How often the clock is
+// read during the batch is controlled by Config.BatchClockEvery.
+// With Config.LockFree the lock does not keep out concurrent calls, so
+// the IDs are increasing but not necessarily consecutive.
+// It returns nil if n <= 0.
+func (g *Generator) NextN(n int) []string {
+	if n <= 0 {
+		return nil
+	}
+	return g.nextBatch(n, g.batchRead)
+}
+
+// ReserveBlock generates size consecutive IDs in one step, for handing
+// a pre-allocated range to sub-workers. The generator's lock is held
+// throughout, so the block is exclusively the caller's: no other call
+// gets a sequence slot inside it. A block larger than one
+// millisecond's capacity spills into the following milliseconds. IDs
+// are returned in order.
+//
+// It returns an error if size is not positive or exceeds
+// Config.MaxBlockSize, which bounds how long other callers can be
+// blocked, or if the generator is Config.LockFree, where the lock
+// cannot keep other calls out of the block.
+func (g *Generator) ReserveBlock(size int) ([]string, error) {
+	if size <= 0 {
+		return nil, errors.New("block size must be positive")
+	}
+	if size > g.maxBlock {
+		return nil, fmt.Errorf("block size %d exceeds the maximum of %d", size, g.maxBlock)
+	}
+	if g.lockFree {
+		return nil, errors.New("reserveBlock is not supported with lockFree")
+	}
+	return g.nextBatch(size, 0), nil
+}
+
+// NextIf generates an ID only if pred holds for the current time,
+// returning ("", false) without consuming a sequence slot otherwise.
+// It supports maintenance windows and rate gates without wrapping the
+// generator. pred sees the same timestamp the ID is generated with; if
+// the sequence for that millisecond is exhausted, NextIf waits for the
+// next one and asks pred again.
+//
+// pred is called with the generator's lock held and must not use g.
+//
+// Example:
+//
+//	id, ok := gen.NextIf(func(t time.Time) bool { return t.Hour() != 3 })
+func (g *Generator) NextIf(pred func(time.Time) bool) (string, bool) {
 	g.mu.Lock()
-	nowMs := max(g.deps.nowFunc()-g.baseEpoch, g.lastMs)
-	if nowMs == g.lastMs {
-		g.seq++
-		if g.seq >= 1<<15 {
+	for {
+		nowMs := g.tick()
+		lastMs, _ := g.position()
+		if !pred(time.UnixMilli(g.baseEpoch + max(nowMs, lastMs)*g.unit)) {
 			g.mu.Unlock()
-			spinUntilNextMs(g.baseEpoch, nowMs, g.deps.nowFunc)
-			g.mu.Lock()
-			nowMs = g.deps.nowFunc() - g.baseEpoch
-			g.lastMs = nowMs
-			g.seq = 0
+			return "", false
 		}
-	} else {
-		g.seq = 0
-		g.lastMs = nowMs
+		val, err := g.nextLocked(false, nowMs)
+		if err == nil {
+			g.mu.Unlock()
+			return g.format(val), true
+		}
+		if err == ErrBeforeEpoch {
+			g.waitEpochLocked()
+			continue
+		}
+		g.stats.Rollovers++
+		lastMs, _ = g.position()
+		nowFunc := g.deps.nowFunc
+		g.mu.Unlock()
+		g.wait(g.baseEpoch, lastMs*g.unit+g.unit-1, nowFunc, g.spinSleep)
+		g.mu.Lock()
 	}
-	val := (uint64(nowMs) << 25) | (uint64(g.shard) << 15) | uint64(g.seq)
+}
+
+// NextExcluding is like Next but never returns an ID present in seen.
+// It is a safety belt for at-least-once processing where the caller
+// holds IDs it has already used. Under normal operation IDs never
+// repeat, so this does not loop; it only generates again in the
+// event that the result is already in seen.
+func (g *Generator) NextExcluding(seen map[string]struct{}) string {
+	for {
+		id := g.Next()
+		if _, dup := seen[id]; !dup {
+			return id
+		}
+	}
+}
+
+// Speculate reserves an ID for a flow that may still be rolled back,
+// e.g. a database transaction. Call commit once the ID is used for
+// good, or rollback to hand its sequence slot back so the next ID
+// reuses it, reducing sequence-space waste in high-abort workloads.
+//
+// Rollback is best-effort: it only succeeds if no other ID has been
+// generated since, and is a no-op otherwise. Only the first call to
+// either commit or rollback has any effect.
+//
+// Example:
+//
+//	id, commit, rollback := gen.Speculate()
+//	if err := tx.Insert(id); err != nil {
+//	    rollback()
+//	    return err
+//	}
+//	commit()
+func (g *Generator) Speculate() (id string, commit func(), rollback func()) {
+	g.mu.Lock()
+	val := g.nextWaitLocked(g.tick())
+	ticket := g.issued
 	g.mu.Unlock()
-	var out [11]byte
-	for i := 10; i >= 0; i-- {
-		out[i] = alphabet[val&63]
-		val >>= 6
+
+	var once sync.Once
+	commit = func() { once.Do(func() {}) }
+	rollback = func() {
+		once.Do(func() {
+			g.mu.Lock()
+			if g.issued == ticket && !g.lockFree {
+				g.reuse = true
+			}
+			g.mu.Unlock()
+		})
 	}
-	return string(out[:])
+	return g.format(val), commit, rollback
+}
+
+// Config returns the generator's effective configuration. Defaults
+// and auto-derived values are resolved: ShardID holds the actual shard
+// rather than -1, and CustomEpochMs, Layout and SpinSleep hold the
+// values in use. Passing the result to New creates a generator with
+// identical settings.
+func (g *Generator) Config() Config {
+	g.mu.Lock()
+	cfg := g.cfg
+	g.mu.Unlock()
+	cfg.TokenKey = append([]byte(nil), g.cfg.TokenKey...)
+	cfg.SigningKey = append([]byte(nil), g.cfg.SigningKey...)
+	cfg.ObfuscationKey = append([]byte(nil), g.cfg.ObfuscationKey...)
+	return cfg
+}
+
+// Name returns the label set via Config.Name, or "" if none was given.
+func (g *Generator) Name() string {
+	return g.name
+}
+
+// Stats returns a consistent snapshot of the generator's counters.
+// It is safe to call concurrently with Next.
+func (g *Generator) Stats() Stats {
+	g.mu.Lock()
+	s := g.stats
+	g.mu.Unlock()
+	return g.astats.addTo(s)
+}
+
+// State returns a consistent snapshot of the generator's position for
+// live debugging: the timestamp of the last issued ID in Unix
+// milliseconds (the epoch itself before the first ID) and its sequence
+// number. A lastMs that stops advancing points at a clock problem; a
+// seq that keeps reaching Layout.MaxSequence points at throughput
+// limits. It is read-only and safe to call concurrently with Next.
+func (g *Generator) State() (lastMs int64, seq uint16) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	last, s := g.position()
+	return g.baseEpoch + last*g.unit, uint16(s)
+}
+
+// Peek returns the ID the next call to Next would return if made now,
+// without issuing it, e.g. to set up a test of code that must react
+// to a particular ID. The prediction holds as long as no other ID is
+// generated and the clock does not reach a new timestamp tick first.
+// With Config.RandomizeSequence a new tick starts at a random
+// sequence, which Peek cannot predict and reports as 0. It returns ""
+// if Config.BannedSubstrings rejects so many IDs that Next would wait
+// for a later tick.
+//
+// Example:
+//
+//	next := gen.Peek()
+//	seen := map[string]struct{}{next: {}}
+//	id := gen.NextExcluding(seen) // != next
+func (g *Generator) Peek() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	nowMs := g.tick()
+	lastMs, seq := g.position()
+	ovf, counter, reuse := g.ovf, g.counter, g.reuse && !g.lockFree
+	// Follow claimLocked's and nextTaggedLocked's steps without
+	// changing g.
+	for skips := 1; ; skips++ {
+		switch {
+		case nowMs > lastMs:
+			lastMs, seq, ovf = nowMs, 0, 0
+		case reuse:
+		case int(seq) < g.layout.MaxSequence():
+			seq++
+		case g.overflow && uint64(ovf) < g.layout.MaxSalt():
+			ovf++
+		default:
+			// Next would wait for the following tick.
+			lastMs, seq, ovf = lastMs+1, 0, 0
+		}
+		if !reuse {
+			counter++
+		}
+		reuse = false
+		val := g.layout.pack(lastMs, g.shard, seq, counter-1) | uint64(g.salt|ovf)
+		if val, ok := g.allowed(val); ok {
+			return g.format(val)
+		}
+		if skips == g.bannedLimit() {
+			return ""
+		}
+		counter--
+		nowMs = lastMs
+	}
+}
+
+// AdvanceTo fast-forwards the generator's clock to ms (Unix
+// milliseconds) and freezes it there. It is meant for tests in
+// downstream projects that need deterministic timestamps. To share
+// one simulated clock between generators, pass a ManualClock as
+// Config.Clock instead.
+//
+// After the first call the generator no longer reads the system clock;
+// each later call can only move the clock forward, and earlier values
+// are ignored, so IDs stay monotonic. A call blocked waiting for the
+// next millisecond resumes once the clock is advanced. With
+// Config.LockFree, make the first call before generating IDs from
+// other goroutines.
+//
+// Example:
+//
+//	gen.AdvanceTo(time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli())
+//	id := gen.Next() // timestamped 2030-01-01
+func (g *Generator) AdvanceTo(ms int64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if !g.manual {
+		g.manual = true
+		g.manualMs.Store(g.deps.nowFunc())
+		g.deps.nowFunc = g.manualMs.Load
+	}
+	if ms > g.manualMs.Load() {
+		g.manualMs.Store(ms)
+	}
+}
+
+// WaitUntilEpoch blocks until the generator's clock reaches its epoch
+// (Config.CustomEpochMs), or until ctx is done, in which case it
+// returns ctx.Err(). It returns immediately if the epoch has passed.
+//
+// It supports coordinated cutovers where a fleet is deployed with an
+// epoch slightly in the future. Until the epoch Next blocks and
+// TryNext fails with ErrBeforeEpoch, so wait before serving traffic.
+//
+// Example:
+//
+//	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+//	defer cancel()
+//	if err := gen.WaitUntilEpoch(ctx); err != nil {
+//	    log.Fatal(err)
+//	}
+func (g *Generator) WaitUntilEpoch(ctx context.Context) error {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+	for {
+		g.mu.Lock()
+		wait := time.Duration(g.baseEpoch-g.deps.nowFunc()) * time.Millisecond
+		g.mu.Unlock()
+		if wait <= 0 {
+			return nil
+		}
+		// Poll rather than sleep the whole way, in case the clock jumps.
+		timer.Reset(min(wait, 10*time.Millisecond))
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-timer.C:
+		}
+	}
+}
+
+// AutoShardDebug explains how New(nil) derives its shard ID, for
+// troubleshooting shard collisions. It runs the same derivation and
+// reports the source ("mac", "macs", "macs+hostname", "hostname" or
+// "random"; see ShardSourceAuto) and the inputs used, e.g.
+// "01:02:03:04:05:06 (eth0)" for a MAC address. A random shard
+// differs on every call, so only its source is meaningful.
+//
+// Auto-derivation does not try a Kubernetes pod ordinal before the
+// MAC addresses: ordinals start at 0 in every StatefulSet, so pods of
+// different StatefulSets sharing an ID space would get the same shard.
+// The ordinal is only used when a Config opts in with
+// ShardSourceKubernetes, which New(nil) never does.
+//
+// Example:
+//
+//	shard, source, detail, err := uniqid.AutoShardDebug()
+//	log.Printf("shard=%d source=%s detail=%q err=%v", shard, source, detail, err)
+func AutoShardDebug() (shard uint16, source string, detail string, err error) {
+	return autoShardExplain(newDeps(&Config{}))
 }
 
 // -------------------------------------------------------------------
@@ -182,38 +1290,537 @@ type deps struct {
 	ifacesFunc func() ([]net.Interface, error)
 	hostFunc   func() (string, error)
 	randFunc   func([]byte) (int, error)
+	envFunc    func(string) string
+	metaFunc   func(method, url string, header http.Header) (string, error)
+	order      binary.ByteOrder
+	source     ShardSource
+	tag        string
+}
+
+// newDeps returns the real system dependencies for cfg.
+// Not exported.
+func newDeps(cfg *Config) deps {
+	d := deps{
+		nowFunc:    monotonicClock(),
+		ifacesFunc: net.Interfaces,
+		hostFunc:   os.Hostname,
+		randFunc:   rand.Read,
+		envFunc:    os.Getenv,
+		metaFunc:   fetchMetadata,
+		order:      byteOrder(cfg.ByteOrder),
+		source:     cfg.ShardSource,
+		tag:        cfg.ShardMetadataTag,
+	}
+	if cfg.WallClock {
+		d.nowFunc = SystemClock.NowMs
+	}
+	if cfg.CachedClock {
+		d.nowFunc = cachedNowMs
+	}
+	if cfg.Clock != nil {
+		d.nowFunc = cfg.Clock.NowMs
+	}
+	if r := cfg.RandReader; r != nil {
+		d.randFunc = func(b []byte) (int, error) { return io.ReadFull(r, b) }
+	}
+	return d
 }
 
 // autoShardWithDeps tries to derive a shard ID automatically from
-// network interface MAC, hostname, or random fallback.
+// network interface MACs, hostname, or random fallback, as selected by
+// d.source (see ShardSource).
+// Hostnames without per-machine entropy (see degenerateHostname)
+// are skipped.
 // Used internally when Config.ShardID = -1.
 func autoShardWithDeps(d deps) (uint16, error) {
-	if ifs, _ := d.ifacesFunc(); len(ifs) > 0 {
+	shard, _, _, err := autoShardExplain(d)
+	return shard, err
+}
+
+// autoShardExplain implements autoShardWithDeps, also reporting which
+// source produced the shard and the input it was derived from.
+// Not exported.
+func autoShardExplain(d deps) (shard uint16, source, detail string, err error) {
+	if d.source >= ShardSourceAWS {
+		// Cloud sources need neither the interfaces nor the hostname.
+		return cloudShard(d)
+	}
+	var macs []net.Interface
+	if ifs, err := d.ifacesFunc(); err == nil {
 		for _, in := range ifs {
-			if in.Flags&net.FlagLoopback != 0 || len(in.HardwareAddr) == 0 {
-				continue
+			if in.Flags&net.FlagLoopback == 0 && len(in.HardwareAddr) > 0 {
+				macs = append(macs, in)
 			}
-			h := fnv.New32a()
-			_, _ = h.Write(in.HardwareAddr)
-			return uint16(h.Sum32() & 0x3FF), nil
 		}
 	}
-	if hn, err := d.hostFunc(); err == nil {
-		h := fnv.New32a()
+	hn, hostErr := d.hostname()
+
+	if d.source == ShardSourceKubernetes {
+		shard, detail, ok, err := podOrdinal(d, hn)
+		switch {
+		case err != nil:
+			return 0, "", "", err
+		case !ok:
+			return 0, "", "", errors.New("no Kubernetes pod ordinal")
+		}
+		return shard, "pod-ordinal", detail, nil
+	}
+
+	switch d.source {
+	case ShardSourceFirstMAC:
+		if len(macs) == 0 {
+			return 0, "", "", errors.New("no network interface with a MAC address")
+		}
+		shard, detail = hashShard(macs[:1], "")
+		return shard, "mac", detail, nil
+	case ShardSourceAllMACs:
+		if len(macs) == 0 {
+			return 0, "", "", errors.New("no network interface with a MAC address")
+		}
+		shard, detail = hashShard(macs, "")
+		return shard, "macs", detail, nil
+	case ShardSourceHostname:
+		if hostErr != nil || degenerateHostname(hn) {
+			return 0, "", "", errors.New("no usable hostname")
+		}
+		shard, detail = hashShard(nil, hn)
+		return shard, "hostname", detail, nil
+	case ShardSourceRandom:
+		return randomShard(d)
+	}
+
+	switch {
+	case len(macs) == 1:
+		shard, detail = hashShard(macs, "")
+		return shard, "mac", detail, nil
+	case len(macs) > 1:
+		// Cloned VMs may share a MAC; combine every MAC and the
+		// hostname so one duplicate alone does not collide.
+		if hostErr != nil || degenerateHostname(hn) {
+			hn = ""
+		}
+		shard, detail = hashShard(macs, hn)
+		if hn != "" {
+			return shard, "macs+hostname", detail, nil
+		}
+		return shard, "macs", detail, nil
+	case hostErr == nil && !degenerateHostname(hn):
+		shard, detail = hashShard(nil, hn)
+		return shard, "hostname", detail, nil
+	}
+	shard, source, detail, err = randomShard(d)
+	if err != nil {
+		return 0, "", "", errors.New("could not determine shard ID")
+	}
+	return shard, source, detail, nil
+}
+
+// hostname calls hostFunc, treating a missing one as an error.
+// Not exported.
+func (d deps) hostname() (string, error) {
+	if d.hostFunc == nil {
+		return "", errors.New("no hostname source")
+	}
+	return d.hostFunc()
+}
+
+// getenv calls envFunc, treating a missing one as an empty environment.
+// Not exported.
+func (d deps) getenv(key string) string {
+	if d.envFunc == nil {
+		return ""
+	}
+	return d.envFunc(key)
+}
+
+// deriveShard returns the shard from g.provider if set, else the one
+// auto-derived from d, limited to the layout's shard bits.
+// Not exported.
+func (g *Generator) deriveShard(d deps) (uint16, error) {
+	if g.provider == nil {
+		shard, err := autoShardFunc(d)
+		return shard & uint16(g.layout.MaxShard()), err
+	}
+	shard, err := g.provider.ShardID(context.Background())
+	if err != nil {
+		return 0, fmt.Errorf("shard provider: %w", err)
+	}
+	if int(shard) > g.layout.MaxShard() {
+		return 0, fmt.Errorf("shard provider returned %d, beyond the layout's maximum of %d", shard, g.layout.MaxShard())
+	}
+	return shard, nil
+}
+
+// metadata calls metaFunc, treating a missing one as an error.
+// Not exported.
+func (d deps) metadata(method, url string, header http.Header) (string, error) {
+	if d.metaFunc == nil {
+		return "", errors.New("no instance metadata source")
+	}
+	return d.metaFunc(method, url, header)
+}
+
+// podOrdinal returns the StatefulSet pod ordinal from PodOrdinalEnv,
+// or else from hostname hn when running in Kubernetes, and describes
+// where it came from. ok is false if there is none. An out-of-range
+// PodOrdinalEnv is an error; hostnames only match with an ordinal of
+// at most 1023, which also rules out Deployment pods, whose random
+// suffix is 5 characters long.
+// Not exported.
+func podOrdinal(d deps, hn string) (shard uint16, detail string, ok bool, err error) {
+	if v := d.getenv(PodOrdinalEnv); v != "" {
+		n, err := strconv.ParseUint(v, 10, 16)
+		if err != nil || n > 0x3FF {
+			return 0, "", false, fmt.Errorf("%s=%q is not a pod ordinal in 0..1023", PodOrdinalEnv, v)
+		}
+		return uint16(n), PodOrdinalEnv + "=" + v, true, nil
+	}
+	if d.getenv("KUBERNETES_SERVICE_HOST") == "" {
+		return 0, "", false, nil
+	}
+	i := strings.LastIndexByte(hn, '-')
+	digits := hn[i+1:]
+	if i <= 0 || len(digits) > 4 || (len(digits) > 1 && digits[0] == '0') {
+		return 0, "", false, nil
+	}
+	n, err := strconv.ParseUint(digits, 10, 16)
+	if err != nil || n > 0x3FF {
+		return 0, "", false, nil
+	}
+	return uint16(n), hn, true, nil
+}
+
+// hashShard hashes the MAC addresses of ifs, then hn if non-empty,
+// into a 10-bit shard, and describes the inputs.
+// Not exported.
+func hashShard(ifs []net.Interface, hn string) (uint16, string) {
+	h := fnv.New32a()
+	parts := make([]string, 0, len(ifs)+1)
+	for _, in := range ifs {
+		_, _ = h.Write(in.HardwareAddr)
+		parts = append(parts, fmt.Sprintf("%s (%s)", in.HardwareAddr, in.Name))
+	}
+	if hn != "" {
+		if len(ifs) > 0 {
+			_, _ = h.Write([]byte{0})
+		}
 		_, _ = h.Write([]byte(hn))
-		return uint16(h.Sum32() & 0x3FF), nil
+		parts = append(parts, hn)
 	}
+	return uint16(h.Sum32() & 0x3FF), strings.Join(parts, ", ")
+}
+
+// randomShard draws a 10-bit shard from d.randFunc.
+// Not exported.
+func randomShard(d deps) (uint16, string, string, error) {
 	var b [2]byte
-	if _, err := d.randFunc(b[:]); err == nil {
-		return binary.BigEndian.Uint16(b[:]) & 0x3FF, nil
+	if _, err := d.randFunc(b[:]); err != nil {
+		return 0, "", "", fmt.Errorf("reading random shard: %w", err)
+	}
+	return byteOrder(d.order).Uint16(b[:]) & 0x3FF, "random", fmt.Sprintf("%#x", b[:]), nil
+}
+
+// next reserves the next (timestamp, sequence) slot and returns the
+// packed 64-bit value. When the sequence is exhausted it waits for the
+// next millisecond if block is true, or returns ErrSequenceExhausted.
+// Not exported.
+func (g *Generator) next(block bool) (uint64, error) {
+	if g.lockFree {
+		return g.nextAtomic(block, false, g.tick())
+	}
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if block {
+		return g.nextWaitLocked(g.tick()), nil
+	}
+	return g.nextLocked(false, g.tick())
+}
+
+// nextLocked is next for callers already holding g.mu. The lock is
+// released while waiting for the next millisecond and held again on
+// return. nowMs is the clock reading (relative to the epoch) to
+// generate for, in timestamp ticks; passing g.lastMs assumes the
+// clock has not moved since the last ID. The clock is read again if
+// the sequence runs out. IDs rejected by Config.BannedSubstrings are
+// skipped; see nextTaggedLocked.
+// Not exported.
+func (g *Generator) nextLocked(block bool, nowMs int64) (uint64, error) {
+	return g.nextTaggedLocked(block, nowMs, 0)
+}
+
+// nextWaitLocked is nextLocked for callers that cannot fail: when
+// Config.BannedSubstrings rejects every ID it tries, it waits for the
+// next timestamp tick and tries again.
+// Not exported.
+func (g *Generator) nextWaitLocked(nowMs int64) uint64 {
+	for {
+		// With block set, ErrTooManyBanned is the only error.
+		val, err := g.nextLocked(true, nowMs)
+		if err == nil {
+			return val
+		}
+		lastMs, nowFunc := g.lastMs, g.deps.nowFunc
+		g.mu.Unlock()
+		g.wait(g.baseEpoch, lastMs*g.unit+g.unit-1, nowFunc, g.spinSleep)
+		g.mu.Lock()
+		nowMs = g.tick()
+	}
+}
+
+// nextTaggedLocked is nextLocked for an ID carrying tag, which the
+// returned value includes. An ID rejected by Config.BannedSubstrings
+// has its reserved bits re-rolled if they are free, and otherwise its
+// slot is skipped; after bannedLimit skipped slots in a row it gives
+// up with ErrTooManyBanned, so a banned ID is never returned.
+// Not exported.
+func (g *Generator) nextTaggedLocked(block bool, nowMs int64, tag uint64) (uint64, error) {
+	val, err := g.claimLocked(block, nowMs)
+	for skips := 1; err == nil; skips++ {
+		var ok bool
+		if val, ok = g.allowed(g.layout.withTag(val, tag)); ok {
+			break
+		}
+		g.unclaim()
+		if skips == g.bannedLimit() {
+			return 0, ErrTooManyBanned
+		}
+		val, err = g.claimLocked(block, g.tick())
+	}
+	if err != nil {
+		return 0, err
+	}
+	if g.hist != nil {
+		g.hist.record(g.lastMs)
+	}
+	return val, nil
+}
+
+// claimLocked claims the next sequence slot for nextLocked and returns
+// its packed value. The caller records it in g.hist.
+// Not exported.
+func (g *Generator) claimLocked(block bool, nowMs int64) (uint64, error) {
+	if g.lockFree {
+		return g.nextAtomic(block, true, nowMs)
+	}
+	for {
+		if beforeEpoch(nowMs, g.lastMs) {
+			if !block {
+				return 0, ErrBeforeEpoch
+			}
+			g.waitEpochLocked()
+			nowMs = g.tick()
+			continue
+		}
+		if nowMs < g.lastMs {
+			g.stats.ClockBackwards++
+			g.clockDrift(nowMs, g.lastMs)
+			nowMs = g.lastMs
+		}
+		if nowMs > g.lastMs {
+			g.driftSeen.Store(false)
+			if g.switching {
+				g.switchShard(nowMs)
+			}
+			g.saveState(nowMs)
+			g.lastMs = nowMs
+			g.seq = g.firstSeq()
+			g.ovf = 0
+			break
+		}
+		if g.reuse {
+			// The slot at (lastMs, seq) was rolled back; issue it again.
+			break
+		}
+		if int(g.seq) < g.layout.MaxSequence() {
+			g.seq++
+			break
+		}
+		if g.overflow && uint64(g.ovf) < g.layout.MaxSalt() {
+			// Borrow the reserved bits rather than wait; the IDs
+			// still sort after those with the maximum sequence.
+			g.ovf++
+			g.stats.Overflows++
+			break
+		}
+		if !block {
+			return 0, ErrSequenceExhausted
+		}
+		g.stats.Rollovers++
+		nowFunc := g.deps.nowFunc
+		g.mu.Unlock()
+		// Wait for the last millisecond of the current tick to pass.
+		g.wait(g.baseEpoch, nowMs*g.unit+g.unit-1, nowFunc, g.spinSleep)
+		g.mu.Lock()
+		// Re-check: another goroutine may have claimed the new millisecond.
+		nowMs = g.tick()
+	}
+	if !g.reuse {
+		// A reissued slot keeps the rolled-back ID's counter value.
+		g.counter++
+	}
+	g.reuse = false
+	g.issued++
+	g.stats.Generated++
+	return g.layout.pack(g.lastMs, g.shard, g.seq, g.counter-1) | uint64(g.salt|g.ovf), nil
+}
+
+// nextBatch generates n > 0 IDs under one lock, reading the clock for
+// the first and then every readEvery IDs (0 = only when the sequence
+// runs out).
+// Not exported.
+func (g *Generator) nextBatch(n, readEvery int) []string {
+	ids := make([]string, n)
+	g.mu.Lock()
+	for i := range ids {
+		nowMs, _ := g.position()
+		if i == 0 || (readEvery > 0 && i%readEvery == 0) {
+			nowMs = g.tick()
+		}
+		ids[i] = g.format(g.nextWaitLocked(nowMs))
+	}
+	g.mu.Unlock()
+	return ids
+}
+
+// beforeEpoch reports whether the clock reading nowMs, in timestamp
+// ticks, is before the epoch while lastMs is still at it. That is not
+// a clock regression, as no ID has a later timestamp yet; the caller
+// waits for the epoch or fails with ErrBeforeEpoch.
+// Not exported.
+func beforeEpoch(nowMs, lastMs int64) bool {
+	return nowMs < 0 && lastMs <= 0
+}
+
+// waitEpochLocked releases g.mu until the clock reaches the epoch. It
+// sleeps rather than spins, as the epoch may be far off.
+// Not exported.
+func (g *Generator) waitEpochLocked() {
+	nowFunc := g.deps.nowFunc
+	g.mu.Unlock()
+	sleepUntilNextMs(g.baseEpoch, -1, nowFunc, 0)
+	g.mu.Lock()
+}
+
+// tick reads the clock and returns the current timestamp field value:
+// time since the epoch in units of Config.TimestampUnit.
+// Not exported.
+func (g *Generator) tick() int64 {
+	return (g.deps.nowFunc() - g.baseEpoch) / g.unit
+}
+
+// format renders a packed value as an ID, including the generator's
+// prefix, version prefix, check character and signature if
+// configured.
+// Not exported.
+func (g *Generator) format(val uint64) string {
+	var out [16]byte
+	return string(g.appendID(out[:0], val))
+}
+
+// appendID appends the ID for a packed value to dst, as format does.
+// Not exported.
+func (g *Generator) appendID(dst []byte, val uint64) []byte {
+	dst = append(dst, g.prefix...)
+	if g.version != 0 {
+		dst = append(dst, g.version)
+	}
+	n := len(dst)
+	dst = append(dst, make([]byte, g.codec.chars(g.layout))...)
+	if len(g.obfKey) > 0 {
+		g.codec.encode(dst[n:], permute(g.obfKey, val, g.layout.Bits(), false))
+	} else {
+		g.codec.encode(dst[n:], val)
+	}
+	if g.checksum {
+		dst = append(dst, g.codec.checkChar(dst[n:]))
+	}
+	if len(g.signKey) > 0 {
+		n = len(dst)
+		dst = append(dst, make([]byte, signatureChars)...)
+		g.signature(dst[n:], val)
+	}
+	return dst
+}
+
+// encodeTo writes val into dst using the default alphabet; see
+// codec.encode.
+// Not exported.
+func encodeTo(dst []byte, val uint64) {
+	defaultCodec.encode(dst, val)
+}
+
+// resolveSpinSleep maps Config.SpinSleep to the sleep used between
+// clock polls: 0 selects the default for goos, negative disables it.
+// Not exported.
+func resolveSpinSleep(d time.Duration, goos string) time.Duration {
+	switch {
+	case d < 0:
+		return 0
+	case d > 0:
+		return d
+	case goos == "windows":
+		// Sub-millisecond sleeps round up to a full timer tick here.
+		return 0
+	default:
+		return defaultSpinSleep
+	}
+}
+
+// validatePrefix reports whether p is usable as an ID prefix: at most
+// maxPrefixLen bytes of printable ASCII without spaces.
+// Not exported.
+func validatePrefix(p string) error {
+	if len(p) > maxPrefixLen {
+		return fmt.Errorf("prefix must be at most %d bytes", maxPrefixLen)
+	}
+	for i := 0; i < len(p); i++ {
+		if p[i] <= ' ' || p[i] > '~' {
+			return errors.New("prefix must be printable ASCII without spaces")
+		}
+	}
+	return nil
+}
+
+// byteOrder returns o, or binary.BigEndian if o is nil.
+// Not exported.
+func byteOrder(o binary.ByteOrder) binary.ByteOrder {
+	if o == nil {
+		return binary.BigEndian
+	}
+	return o
+}
+
+// degenerateHostname reports whether hn carries no per-machine entropy:
+// empty, or a loopback name that many machines share by default.
+// Hashing such a name would give every machine the same shard, so the
+// auto-shard logic treats it as a miss and falls back to randomness.
+// Not exported.
+func degenerateHostname(hn string) bool {
+	switch strings.ToLower(strings.TrimSpace(hn)) {
+	case "", "localhost", "localhost.localdomain", "localhost6", "ip6-localhost":
+		return true
+	}
+	return false
+}
+
+// sleepUntilNextMs is like spinUntilNextMs but sleeps for the time
+// remaining instead of polling; sleep is unused.
+// Not exported.
+func sleepUntilNextMs(baseEpoch, lastMs int64, nowFunc func() int64, _ time.Duration) {
+	for {
+		now := nowFunc() - baseEpoch
+		if now > lastMs {
+			return
+		}
+		time.Sleep(time.Duration(lastMs+1-now) * time.Millisecond)
 	}
-	return 0, errors.New("could not determine shard ID")
 }
 
 // spinUntilNextMs blocks until the next millisecond tick.
 // Used to ensure monotonic IDs when the per-ms counter overflows.
+// A zero sleep only yields the processor between polls.
 // Not exported.
-func spinUntilNextMs(baseEpoch, lastMs int64, nowFunc func() int64) {
+func spinUntilNextMs(baseEpoch, lastMs int64, nowFunc func() int64, sleep time.Duration) {
 	target := lastMs + 1
 	for {
 		now := nowFunc() - baseEpoch
@@ -221,6 +1828,8 @@ func spinUntilNextMs(baseEpoch, lastMs int64, nowFunc func() int64) {
 			return
 		}
 		runtime.Gosched()
-		time.Sleep(10 * time.Microsecond)
+		if sleep > 0 {
+			time.Sleep(sleep)
+		}
 	}
 }
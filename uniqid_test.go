@@ -1,10 +1,15 @@
 package uniqid
 
 import (
+	"bytes"
+	"context"
 	"crypto/rand"
+	"encoding/binary"
 	"errors"
 	"net"
+	"reflect"
 	"sync"
+	"sync/atomic"
 	"testing"
 	"time"
 )
@@ -75,6 +80,78 @@ func TestNewGenerator(t *testing.T) {
 	autoShardFunc = originalAutoShard
 }
 
+// TestConfigValidate tests detection of invalid and conflicting options
+func TestConfigValidate(t *testing.T) {
+	cases := []struct {
+		name    string
+		cfg     Config
+		wantErr bool
+	}{
+		{"zero config", Config{}, false},
+		{"auto shard", Config{ShardID: -1}, false},
+		{"max shard", Config{ShardID: 1023}, false},
+		{"shard out of range", Config{ShardID: 1024}, true},
+		{"shard outside custom layout", Config{ShardID: 32, Layout: Layout{TimestampBits: 41, ShardBits: 5, SequenceBits: 12}}, true},
+		{"invalid layout", Config{Layout: Layout{TimestampBits: 50, ShardBits: 10, SequenceBits: 15}}, true},
+		{"noShard", Config{NoShard: true}, false},
+		{"noShard with auto shard", Config{NoShard: true, ShardID: -1}, false},
+		{"noShard with explicit shard", Config{NoShard: true, ShardID: 3}, true},
+		{"noShard with sharded layout", Config{NoShard: true, Layout: DefaultLayout}, true},
+		{"prefix in alphabet", Config{VersionPrefix: 'v'}, false},
+		{"prefix outside alphabet", Config{VersionPrefix: '#'}, true},
+	}
+	for _, c := range cases {
+		err := c.cfg.Validate()
+		if (err != nil) != c.wantErr {
+			t.Errorf("%s: Validate() error = %v, wantErr %v", c.name, err, c.wantErr)
+		}
+		if _, newErr := New(&c.cfg); (newErr != nil) != c.wantErr {
+			t.Errorf("%s: New() error = %v, wantErr %v", c.name, newErr, c.wantErr)
+		}
+	}
+}
+
+// TestGeneratorConfig tests reading back the effective configuration
+func TestGeneratorConfig(t *testing.T) {
+	gen, err := New(&Config{ShardID: -1, Name: "orders", VersionPrefix: 'v', TokenKey: bytes.Repeat([]byte("k"), 16)})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	cfg := gen.Config()
+	if cfg.ShardID != int(gen.shard) {
+		t.Errorf("Expected resolved shard %d, got %d", gen.shard, cfg.ShardID)
+	}
+	if cfg.CustomEpochMs != defaultEpochMs || cfg.Layout != DefaultLayout {
+		t.Errorf("Expected resolved epoch and layout, got %d and %+v", cfg.CustomEpochMs, cfg.Layout)
+	}
+
+	clone, err := New(&cfg)
+	if err != nil {
+		t.Fatalf("New(gen.Config()) failed: %v", err)
+	}
+	if !reflect.DeepEqual(clone.Config(), cfg) {
+		t.Errorf("Clone config %+v differs from %+v", clone.Config(), cfg)
+	}
+	if clone.shard != gen.shard || clone.baseEpoch != gen.baseEpoch || clone.layout != gen.layout ||
+		clone.spinSleep != gen.spinSleep || clone.version != gen.version || clone.name != gen.name {
+		t.Error("Clone settings differ from the original generator")
+	}
+
+	// The returned config does not alias the generator's key
+	cfg.TokenKey[0] = 'x'
+	if gen.Config().TokenKey[0] != 'k' {
+		t.Error("Config should return a copy of TokenKey")
+	}
+
+	// A disabled spin sleep round-trips as disabled
+	gen, _ = New(&Config{ShardID: 1, SpinSleep: -1})
+	cfg = gen.Config()
+	clone, _ = New(&cfg)
+	if clone.spinSleep != 0 {
+		t.Errorf("Expected disabled spin sleep, got %v", clone.spinSleep)
+	}
+}
+
 // TestGenFunction tests the new Gen() wrapper function
 func TestGenFunction(t *testing.T) {
 	// Test case 1: Successful call with no config (uses default generator)
@@ -181,7 +258,26 @@ func TestAutoShardLogic(t *testing.T) {
 		t.Error("Expected error from autoShardWithDeps(d4), got nil")
 	}
 
-	// 5. Loopback interface should be skipped
+	// 5. Degenerate hostnames fall through to the random path
+	for _, hn := range []string{"", "localhost", "LOCALHOST", "localhost.localdomain"} {
+		var randUsed bool
+		d := deps{
+			ifacesFunc: func() ([]net.Interface, error) { return nil, errors.New("net error") },
+			hostFunc:   func() (string, error) { return hn, nil },
+			randFunc: func(b []byte) (int, error) {
+				randUsed = true
+				return rand.Read(b)
+			},
+		}
+		if _, err := autoShardWithDeps(d); err != nil {
+			t.Fatalf("autoShardWithDeps with hostname %q failed: %v", hn, err)
+		}
+		if !randUsed {
+			t.Errorf("Expected random fallback for hostname %q", hn)
+		}
+	}
+
+	// 6. Loopback interface should be skipped
 	d5 := deps{
 		ifacesFunc: func() ([]net.Interface, error) {
 			return []net.Interface{
@@ -198,7 +294,7 @@ func TestAutoShardLogic(t *testing.T) {
 		t.Error("Expected a non-zero shard from the non-loopback MAC address")
 	}
 
-	// 6. Interface with no MAC address should be skipped
+	// 7. Interface with no MAC address should be skipped
 	d6 := deps{
 		ifacesFunc: func() ([]net.Interface, error) {
 			return []net.Interface{
@@ -216,6 +312,292 @@ func TestAutoShardLogic(t *testing.T) {
 	}
 }
 
+// TestShardSource tests selecting the auto-shard input
+func TestShardSource(t *testing.T) {
+	eth0 := net.Interface{Name: "eth0", HardwareAddr: net.HardwareAddr{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}}
+	eth1 := net.Interface{Name: "eth1", HardwareAddr: net.HardwareAddr{0x0A, 0x0B, 0x0C, 0x0D, 0x0E, 0x0F}}
+	ifaces := func(ifs ...net.Interface) func() ([]net.Interface, error) {
+		return func() ([]net.Interface, error) { return ifs, nil }
+	}
+	host := func(hn string) func() (string, error) {
+		return func() (string, error) { return hn, nil }
+	}
+	explain := func(d deps) (uint16, string, string) {
+		t.Helper()
+		shard, source, detail, err := autoShardExplain(d)
+		if err != nil {
+			t.Fatalf("autoShardExplain failed: %v", err)
+		}
+		return shard, source, detail
+	}
+
+	// Test case 1: Auto with a single interface uses its MAC alone
+	single, source, _ := explain(deps{ifacesFunc: ifaces(eth0), hostFunc: host("vm-a")})
+	if source != "mac" {
+		t.Errorf("Expected source mac, got %q", source)
+	}
+	if other, _, _ := explain(deps{ifacesFunc: ifaces(eth0), hostFunc: host("vm-b")}); other != single {
+		t.Error("Expected single-interface shard not to depend on the hostname")
+	}
+
+	// Test case 2: Auto with several interfaces combines all MACs and the hostname
+	vmA, source, detail := explain(deps{ifacesFunc: ifaces(eth0, eth1), hostFunc: host("vm-a")})
+	if source != "macs+hostname" || detail != "01:02:03:04:05:06 (eth0), 0a:0b:0c:0d:0e:0f (eth1), vm-a" {
+		t.Errorf("Unexpected combined explanation: %q %q", source, detail)
+	}
+	// Cloned VMs sharing both MACs still differ by hostname
+	if vmB, _, _ := explain(deps{ifacesFunc: ifaces(eth0, eth1), hostFunc: host("vm-b")}); vmB == vmA {
+		t.Error("Expected different hostnames to change the combined shard")
+	}
+	// Without a usable hostname only the MACs are combined
+	if _, source, _ := explain(deps{ifacesFunc: ifaces(eth0, eth1), hostFunc: host("localhost")}); source != "macs" {
+		t.Errorf("Expected source macs, got %q", source)
+	}
+
+	// Test case 3: Explicit sources use only their input
+	d := deps{ifacesFunc: ifaces(eth0, eth1), hostFunc: host("vm-a"), source: ShardSourceFirstMAC}
+	if shard, source, _ := explain(d); shard != single || source != "mac" {
+		t.Errorf("Expected FirstMAC to match the single-interface shard, got %d %q", shard, source)
+	}
+	d.source = ShardSourceAllMACs
+	if _, source, detail := explain(d); source != "macs" || detail != "01:02:03:04:05:06 (eth0), 0a:0b:0c:0d:0e:0f (eth1)" {
+		t.Errorf("Unexpected AllMACs explanation: %q %q", source, detail)
+	}
+	d.source = ShardSourceHostname
+	if _, source, detail := explain(d); source != "hostname" || detail != "vm-a" {
+		t.Errorf("Unexpected Hostname explanation: %q %q", source, detail)
+	}
+	d.source = ShardSourceRandom
+	d.randFunc = bytes.NewReader([]byte{0x12, 0x34}).Read
+	if shard, source, _ := explain(d); shard != 0x1234&0x3FF || source != "random" {
+		t.Errorf("Unexpected Random explanation: %d %q", shard, source)
+	}
+
+	// Test case 4: Explicit sources fail when their input is missing
+	none := deps{ifacesFunc: ifaces(), hostFunc: host("localhost"), randFunc: rand.Read}
+	for _, src := range []ShardSource{ShardSourceFirstMAC, ShardSourceAllMACs, ShardSourceHostname} {
+		none.source = src
+		if _, err := autoShardWithDeps(none); err == nil {
+			t.Errorf("Expected error for source %d without input, got nil", src)
+		}
+	}
+
+	// Test case 5: Unknown sources are rejected
+	if _, err := New(&Config{ShardID: -1, ShardSource: 9}); err == nil {
+		t.Error("Expected error for unknown ShardSource, got nil")
+	}
+}
+
+// TestPodOrdinalShard tests deriving the shard from a StatefulSet pod ordinal
+func TestPodOrdinalShard(t *testing.T) {
+	eth0 := net.Interface{Name: "eth0", HardwareAddr: net.HardwareAddr{0x01, 0x02, 0x03, 0x04, 0x05, 0x06}}
+	ifaces := func() ([]net.Interface, error) { return []net.Interface{eth0}, nil }
+	newDeps := func(hn string, env map[string]string) deps {
+		return deps{
+			ifacesFunc: ifaces,
+			hostFunc:   func() (string, error) { return hn, nil },
+			envFunc:    func(k string) string { return env[k] },
+			source:     ShardSourceKubernetes,
+		}
+	}
+	inK8s := map[string]string{"KUBERNETES_SERVICE_HOST": "10.0.0.1"}
+
+	// Test case 1: StatefulSet hostnames in Kubernetes give their ordinal
+	for hn, want := range map[string]uint16{"web-0": 0, "orders-db-7": 7, "web-1023": 1023} {
+		shard, source, detail, err := autoShardExplain(newDeps(hn, inK8s))
+		if err != nil || shard != want || source != "pod-ordinal" || detail != hn {
+			t.Errorf("%s: got %d %q %q %v, want shard %d", hn, shard, source, detail, err, want)
+		}
+	}
+
+	// Test case 2: Other hostnames, or none in Kubernetes, have no ordinal
+	for hn, env := range map[string]map[string]string{
+		"web-0":               nil,
+		"web-7d9f8b6c5-24567": inK8s,
+		"web-1024":            inK8s,
+		"web-07":              inK8s,
+		"web-abc":             inK8s,
+		"standalone":          inK8s,
+	} {
+		if _, err := autoShardWithDeps(newDeps(hn, env)); err == nil {
+			t.Errorf("%s: expected error without a pod ordinal, got nil", hn)
+		}
+	}
+
+	// Test case 3: The downward-API variable wins, and must be in range
+	env := map[string]string{PodOrdinalEnv: "42"}
+	if shard, _, detail, err := autoShardExplain(newDeps("web-3", env)); err != nil || shard != 42 || detail != "POD_INDEX=42" {
+		t.Errorf("Expected shard 42 from %s, got %d %q %v", PodOrdinalEnv, shard, detail, err)
+	}
+	env[PodOrdinalEnv] = "5000"
+	if _, err := autoShardWithDeps(newDeps("web-3", env)); err == nil {
+		t.Error("Expected error for an out-of-range ordinal, got nil")
+	}
+
+	// Test case 4: ShardSourceAuto ignores ordinals, which repeat
+	// across StatefulSets
+	d := newDeps("web-5", map[string]string{"KUBERNETES_SERVICE_HOST": "10.0.0.1", PodOrdinalEnv: "5"})
+	d.source = ShardSourceAuto
+	if _, source, _, err := autoShardExplain(d); err != nil || source != "mac" {
+		t.Errorf("Expected the MAC source, got %q %v", source, err)
+	}
+}
+
+// shardFunc adapts a function to ShardProvider.
+type shardFunc func() (uint16, error)
+
+func (f shardFunc) ShardID(context.Context) (uint16, error) { return f() }
+
+// TestShardProvider tests taking the shard ID from a custom provider
+func TestShardProvider(t *testing.T) {
+	// Test case 1: The provider's shard is used as is
+	gen, err := New(&Config{ShardProvider: shardFunc(func() (uint16, error) { return 321, nil })})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	if p, _ := gen.Parse(gen.Next()); p.Shard != 321 || gen.Config().ShardID != 321 {
+		t.Errorf("Expected shard 321, got %d", p.Shard)
+	}
+
+	// Test case 2: Provider errors and out-of-range shards fail New
+	failing := shardFunc(func() (uint16, error) { return 0, errors.New("config service down") })
+	if _, err := New(&Config{ShardProvider: failing}); err == nil {
+		t.Error("Expected the provider error, got nil")
+	}
+	tooBig := shardFunc(func() (uint16, error) { return 1024, nil })
+	if _, err := New(&Config{ShardProvider: tooBig}); err == nil {
+		t.Error("Expected error for shard 1024, got nil")
+	}
+
+	// Test case 3: The provider conflicts with explicit shards
+	ok := shardFunc(func() (uint16, error) { return 1, nil })
+	for _, bad := range []*Config{{ShardProvider: ok, ShardID: 5}, {ShardProvider: ok, NoShard: true}} {
+		if _, err := New(bad); err == nil {
+			t.Errorf("Expected error for %+v, got nil", bad)
+		}
+	}
+
+	// Test case 4: Refresh asks the provider again
+	var next atomic.Uint32
+	next.Store(10)
+	gen, err = New(&Config{
+		ShardProvider:        shardFunc(func() (uint16, error) { return uint16(next.Load()), nil }),
+		RefreshShardInterval: time.Millisecond,
+	})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	defer gen.Close()
+	next.Store(11)
+	deadline := time.Now().Add(2 * time.Second)
+	for gen.Config().ShardID != 11 && time.Now().Before(deadline) {
+		gen.Next()
+		time.Sleep(time.Millisecond)
+	}
+	if gen.Config().ShardID != 11 {
+		t.Errorf("Expected the refreshed shard 11, got %d", gen.Config().ShardID)
+	}
+}
+
+// TestAutoShardDebug tests explaining the auto-shard derivation
+func TestAutoShardDebug(t *testing.T) {
+	noNet := func() ([]net.Interface, error) { return nil, errors.New("net error") }
+	noHost := func() (string, error) { return "", errors.New("host error") }
+
+	// Test case 1: MAC address, reported with its interface
+	d := deps{
+		ifacesFunc: func() ([]net.Interface, error) {
+			return []net.Interface{{
+				Name:         "eth0",
+				HardwareAddr: net.HardwareAddr{0x01, 0x02, 0x03, 0x04, 0x05, 0x06},
+			}}, nil
+		},
+	}
+	want, _ := autoShardWithDeps(d)
+	shard, source, detail, err := autoShardExplain(d)
+	if err != nil || shard != want || source != "mac" || detail != "01:02:03:04:05:06 (eth0)" {
+		t.Errorf("Unexpected MAC explanation: %d %q %q %v", shard, source, detail, err)
+	}
+
+	// Test case 2: Hostname
+	d = deps{ifacesFunc: noNet, hostFunc: func() (string, error) { return "test-host", nil }}
+	want, _ = autoShardWithDeps(d)
+	shard, source, detail, err = autoShardExplain(d)
+	if err != nil || shard != want || source != "hostname" || detail != "test-host" {
+		t.Errorf("Unexpected hostname explanation: %d %q %q %v", shard, source, detail, err)
+	}
+
+	// Test case 3: Random fallback
+	d = newDeps(&Config{RandReader: bytes.NewReader([]byte{0x12, 0x34})})
+	d.ifacesFunc, d.hostFunc = noNet, noHost
+	shard, source, detail, err = autoShardExplain(d)
+	if err != nil || shard != 0x1234&0x3FF || source != "random" || detail != "0x1234" {
+		t.Errorf("Unexpected random explanation: %d %q %q %v", shard, source, detail, err)
+	}
+
+	// Test case 4: No source available
+	d = deps{ifacesFunc: noNet, hostFunc: noHost, randFunc: func([]byte) (int, error) { return 0, errors.New("rand error") }}
+	if _, source, _, err := autoShardExplain(d); err == nil || source != "" {
+		t.Errorf("Expected error and empty source, got %q, %v", source, err)
+	}
+
+	// Test case 5: AutoShardDebug matches what New(nil) picks
+	shard, source, _, err = AutoShardDebug()
+	if err != nil {
+		t.Fatalf("AutoShardDebug failed: %v", err)
+	}
+	gen, err := New(nil)
+	if err != nil {
+		t.Fatalf("New(nil) failed: %v", err)
+	}
+	if source != "random" && gen.shard != shard {
+		t.Errorf("Expected New(nil) shard %d to match AutoShardDebug (%s), got %d", shard, source, gen.shard)
+	}
+}
+
+// TestRandReader tests using a caller-supplied io.Reader for entropy
+func TestRandReader(t *testing.T) {
+	noNet := func() ([]net.Interface, error) { return nil, errors.New("net error") }
+	noHost := func() (string, error) { return "", errors.New("host error") }
+
+	// Test case 1: Deterministic reader drives the random fallback
+	d := newDeps(&Config{RandReader: bytes.NewReader([]byte{0x12, 0x34})})
+	d.ifacesFunc, d.hostFunc = noNet, noHost
+	shard, err := autoShardWithDeps(d)
+	if err != nil {
+		t.Fatalf("autoShardWithDeps failed: %v", err)
+	}
+	if shard != 0x1234&0x3FF {
+		t.Errorf("Expected shard %d, got %d", 0x1234&0x3FF, shard)
+	}
+
+	// Test case 2: A short read is an error, so auto-sharding fails
+	d = newDeps(&Config{RandReader: bytes.NewReader([]byte{0x12})})
+	d.ifacesFunc, d.hostFunc = noNet, noHost
+	if _, err := autoShardWithDeps(d); err == nil {
+		t.Error("Expected error from short RandReader, got nil")
+	}
+
+	// Test case 3: The reader is only a fallback; other sources still win
+	d = newDeps(&Config{RandReader: bytes.NewReader(nil)})
+	d.ifacesFunc = noNet
+	d.hostFunc = func() (string, error) { return "test-host", nil }
+	if _, err := autoShardWithDeps(d); err != nil {
+		t.Errorf("Expected hostname fallback to succeed, got %v", err)
+	}
+
+	// Test case 4: ByteOrder controls how the random bytes are decoded
+	d = newDeps(&Config{RandReader: bytes.NewReader([]byte{0x12, 0x34}), ByteOrder: binary.LittleEndian})
+	d.ifacesFunc, d.hostFunc = noNet, noHost
+	shard, err = autoShardWithDeps(d)
+	if err != nil {
+		t.Fatalf("autoShardWithDeps failed: %v", err)
+	}
+	if shard != 0x3412&0x3FF {
+		t.Errorf("Expected shard %d, got %d", 0x3412&0x3FF, shard)
+	}
+}
+
 // TestNextIDGeneration tests the Next() method
 func TestNextIDGeneration(t *testing.T) {
 	gen, _ := New(&Config{ShardID: 1})
@@ -238,13 +620,11 @@ func TestNextIDGeneration(t *testing.T) {
 
 // TestSequenceRollover tests the sequence number rolling over
 func TestSequenceRollover(t *testing.T) {
-	mockTime := time.Now().UnixMilli()
-	mockNowFunc := func() int64 {
-		return mockTime
-	}
+	var mockTime atomic.Int64
+	mockTime.Store(time.Now().UnixMilli())
 
 	gen, _ := New(&Config{ShardID: 1})
-	gen.deps.nowFunc = mockNowFunc
+	gen.deps.nowFunc = mockTime.Load
 
 	// Exhaust the sequence
 	for i := 0; i < 1<<15; i++ {
@@ -257,13 +637,13 @@ func TestSequenceRollover(t *testing.T) {
 	go func() {
 		defer wg.Done()
 		time.Sleep(5 * time.Millisecond) // Give the spin loop time to start
-		mockTime++
+		mockTime.Add(1)
 	}()
 
 	_ = gen.Next() // This will block until mockTime is incremented
 	wg.Wait()
 
-	if gen.lastMs != mockTime-gen.baseEpoch {
+	if gen.lastMs != mockTime.Load()-gen.baseEpoch {
 		t.Errorf("Expected lastMs to be updated after sequence rollover")
 	}
 	if gen.seq != 0 {
@@ -271,6 +651,511 @@ func TestSequenceRollover(t *testing.T) {
 	}
 }
 
+// TestTryNext tests the non-blocking variant of Next
+func TestTryNext(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	gen, _ := New(&Config{ShardID: 1})
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	id, err := gen.TryNext()
+	if err != nil {
+		t.Fatalf("TryNext failed: %v", err)
+	}
+	if len(id) != 11 {
+		t.Errorf("Expected ID length 11, got %d", len(id))
+	}
+
+	// Exhaust the sequence with a frozen clock
+	for i := 1; i < 1<<15; i++ {
+		if _, err := gen.TryNext(); err != nil {
+			t.Fatalf("TryNext failed at seq %d: %v", i, err)
+		}
+	}
+
+	done := make(chan error, 1)
+	go func() {
+		_, err := gen.TryNext()
+		done <- err
+	}()
+	select {
+	case err := <-done:
+		if !errors.Is(err, ErrSequenceExhausted) {
+			t.Errorf("Expected ErrSequenceExhausted, got %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("TryNext blocked on an exhausted sequence")
+	}
+	if s := gen.Stats(); s.Generated != 1<<15 {
+		t.Errorf("Expected failed call not to count as generated, got %d", s.Generated)
+	}
+
+	// Once the clock advances generation resumes
+	mockTime++
+	if _, err := gen.TryNext(); err != nil {
+		t.Errorf("TryNext failed after clock advanced: %v", err)
+	}
+}
+
+// TestNextIf tests generating only while a time predicate holds
+func TestNextIf(t *testing.T) {
+	base := time.Date(2025, 6, 1, 12, 0, 0, 0, time.UTC)
+	mockTime := base.UnixMilli()
+	gen, _ := New(&Config{ShardID: 1})
+	gen.deps.nowFunc = func() int64 { return mockTime }
+	notMinute1 := func(t time.Time) bool { return t.Minute() != 1 }
+
+	// Test case 1: Predicate holds, ID carries the checked time
+	var seen time.Time
+	id, ok := gen.NextIf(func(t time.Time) bool { seen = t; return true })
+	if !ok {
+		t.Fatal("Expected NextIf to generate")
+	}
+	parts, _ := gen.Parse(id)
+	if !parts.Time.Equal(seen) {
+		t.Errorf("Expected ID time %v to match predicate time %v", parts.Time, seen)
+	}
+
+	// Test case 2: Rejected minute consumes no sequence slot
+	mockTime = base.Add(time.Minute).UnixMilli()
+	for i := 0; i < 3; i++ {
+		if id, ok := gen.NextIf(notMinute1); ok || id != "" {
+			t.Errorf("Expected rejection in minute 1, got %q, %v", id, ok)
+		}
+	}
+	if s := gen.Stats(); s.Generated != 1 {
+		t.Errorf("Expected rejected calls not to generate, got %d", s.Generated)
+	}
+	if gen.lastMs != base.UnixMilli()-gen.baseEpoch {
+		t.Error("Expected rejected calls not to advance the generator")
+	}
+
+	// Test case 3: Generation resumes once the window is left
+	mockTime = base.Add(2 * time.Minute).UnixMilli()
+	id, ok = gen.NextIf(notMinute1)
+	if !ok {
+		t.Fatal("Expected NextIf to generate in minute 2")
+	}
+	if parts, _ := gen.Parse(id); parts.Seq != 0 {
+		t.Errorf("Expected sequence 0 in a new millisecond, got %d", parts.Seq)
+	}
+}
+
+// TestTimestampUnit tests coarser timestamp ticks
+func TestTimestampUnit(t *testing.T) {
+	// Test case 1: Unit must be a positive multiple of 1ms
+	for _, unit := range []time.Duration{-time.Millisecond, 1500 * time.Microsecond} {
+		if _, err := New(&Config{ShardID: 1, TimestampUnit: unit}); err == nil {
+			t.Errorf("Expected error for TimestampUnit %v, got nil", unit)
+		}
+	}
+
+	// Test case 2: Decoded times quantize to 4ms and IDs stay unique
+	start := defaultEpochMs + 1_000_000_003
+	mockTime := start
+	gen, _ := New(&Config{ShardID: 1, TimestampUnit: 4 * time.Millisecond})
+	gen.deps.nowFunc = func() int64 { return mockTime }
+	seen := make(map[string]bool)
+	for ; mockTime < start+12; mockTime++ {
+		for i := 0; i < 3; i++ {
+			id := gen.Next()
+			if seen[id] {
+				t.Fatalf("Duplicate ID %q", id)
+			}
+			seen[id] = true
+			parts, err := gen.Parse(id)
+			if err != nil {
+				t.Fatalf("Parse failed: %v", err)
+			}
+			want := mockTime - (mockTime-defaultEpochMs)%4
+			if parts.Time.UnixMilli() != want {
+				t.Errorf("Expected time %d quantized to %d, got %d", mockTime, want, parts.Time.UnixMilli())
+			}
+		}
+	}
+
+	// Test case 3: The sequence is shared by every millisecond of a tick
+	mockTime = start + 17 // first millisecond of a tick
+	for {
+		if _, err := gen.TryNext(); err != nil {
+			break
+		}
+	}
+	if _, err := gen.TryNext(); !errors.Is(err, ErrSequenceExhausted) {
+		t.Errorf("Expected ErrSequenceExhausted within the tick, got %v", err)
+	}
+	mockTime = start + 20
+	if _, err := gen.TryNext(); !errors.Is(err, ErrSequenceExhausted) {
+		t.Errorf("Expected ErrSequenceExhausted later in the same tick, got %v", err)
+	}
+	mockTime = start + 21
+	if _, err := gen.TryNext(); err != nil {
+		t.Errorf("Expected generation to resume in the next tick, got %v", err)
+	}
+
+	// Test case 4: The effective unit is reported and defaults to 1ms
+	if u := gen.Config().TimestampUnit; u != 4*time.Millisecond {
+		t.Errorf("Expected TimestampUnit 4ms, got %v", u)
+	}
+	def, _ := New(&Config{ShardID: 1})
+	if u := def.Config().TimestampUnit; u != time.Millisecond {
+		t.Errorf("Expected default TimestampUnit 1ms, got %v", u)
+	}
+}
+
+// TestNextExcluding tests skipping IDs the caller has already seen
+func TestNextExcluding(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	gen, _ := New(&Config{ShardID: 1})
+	gen.deps.nowFunc = func() int64 { return mockTime }
+	_ = gen.Next()
+
+	upcoming := gen.Peek()
+
+	seen := map[string]struct{}{upcoming: {}}
+	id := gen.NextExcluding(seen)
+	if id == upcoming {
+		t.Errorf("NextExcluding returned excluded ID %q", id)
+	}
+	if len(id) != 11 {
+		t.Errorf("Expected ID length 11, got %d", len(id))
+	}
+
+	// With nothing excluded it behaves like Next
+	if id := gen.NextExcluding(nil); len(id) != 11 {
+		t.Errorf("Expected ID length 11, got %d", len(id))
+	}
+}
+
+// TestPeek tests predicting the next ID without issuing it
+func TestPeek(t *testing.T) {
+	mockTime := int64(1_800_000_000_000)
+	for _, cfg := range []*Config{
+		{ShardID: 1},
+		{ShardID: 1, Layout: Layout{TimestampBits: 39, ShardBits: 10, SequenceBits: 2, ReservedBits: 2}, BurstOverflow: true},
+		{ShardID: 1, Layout: Layout{TimestampBits: 39, ShardBits: 4, SequenceBits: 15, CounterBits: 6}, BannedSubstrings: LookAlikeRuns},
+		{ShardID: 1, LockFree: true},
+	} {
+		gen, err := New(cfg)
+		if err != nil {
+			t.Fatalf("New failed: %v", err)
+		}
+		gen.deps.nowFunc = func() int64 { return mockTime }
+
+		// Test case 1: Peek matches the following Next, and does not
+		// consume it
+		for i := 0; i < 200; i++ {
+			if i%5 == 0 {
+				mockTime++
+			}
+			want := gen.Peek()
+			if again := gen.Peek(); again != want {
+				t.Fatalf("Peek changed from %q to %q", want, again)
+			}
+			if got := gen.Next(); got != want {
+				t.Fatalf("%+v: Peek returned %q, Next %q", cfg, want, got)
+			}
+		}
+
+		// Test case 2: A rolled-back slot is predicted as reissued
+		if cfg.LockFree {
+			continue
+		}
+		id, _, rollback := gen.Speculate()
+		rollback()
+		if got := gen.Peek(); got != id || gen.Next() != id {
+			t.Errorf("Expected Peek to return the rolled-back ID %q, got %q", id, got)
+		}
+	}
+}
+
+// TestAdvanceTo tests fast-forwarding the generator's clock
+func TestAdvanceTo(t *testing.T) {
+	gen, _ := New(&Config{ShardID: 1})
+	_ = gen.Next()
+
+	// Advancing to a future time is reflected in the next ID
+	future := time.Date(2030, 1, 1, 0, 0, 0, 0, time.UTC).UnixMilli()
+	gen.AdvanceTo(future)
+	p, _ := gen.Parse(gen.Next())
+	if p.Time.UnixMilli() != future || p.Seq != 0 {
+		t.Errorf("Expected ID at %d seq 0, got %+v", future, p)
+	}
+
+	// The clock is frozen and never moves back
+	gen.AdvanceTo(future - 1000)
+	p, _ = gen.Parse(gen.Next())
+	if p.Time.UnixMilli() != future || p.Seq != 1 {
+		t.Errorf("Expected ID at %d seq 1, got %+v", future, p)
+	}
+
+	// A call waiting for the next millisecond resumes after advancing
+	for i := 2; i < 1<<15; i++ {
+		_ = gen.Next()
+	}
+	done := make(chan string)
+	go func() { done <- gen.Next() }()
+	time.Sleep(5 * time.Millisecond)
+	gen.AdvanceTo(future + 1)
+	select {
+	case id := <-done:
+		p, _ = gen.Parse(id)
+		if p.Time.UnixMilli() != future+1 || p.Seq != 0 {
+			t.Errorf("Expected ID at %d seq 0, got %+v", future+1, p)
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Next did not resume after AdvanceTo")
+	}
+}
+
+// TestSpeculate tests reserving IDs that may be rolled back
+func TestSpeculate(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	gen, _ := New(&Config{ShardID: 1})
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	// Test case 1: Rollback succeeds when no later ID was issued
+	id, _, rollback := gen.Speculate()
+	rollback()
+	if next := gen.Next(); next != id {
+		t.Errorf("Expected rolled-back ID %q to be reused, got %q", id, next)
+	}
+
+	// Test case 2: Rollback is a no-op once a later ID was generated
+	id, _, rollback = gen.Speculate()
+	later := gen.Next()
+	rollback()
+	next := gen.Next()
+	if next == id || next == later {
+		t.Errorf("Expected a fresh ID after no-op rollback, got %q", next)
+	}
+
+	// Test case 3: Rollback after commit is a no-op
+	id, commit, rollback := gen.Speculate()
+	commit()
+	rollback()
+	if next := gen.Next(); next == id {
+		t.Errorf("Committed ID %q was reissued", id)
+	}
+
+	// Test case 4: A rolled-back slot is dropped once the clock moves on
+	_, _, rollback = gen.Speculate()
+	rollback()
+	mockTime++
+	p, _ := gen.Parse(gen.Next())
+	if p.Time.UnixMilli() != mockTime || p.Seq != 0 {
+		t.Errorf("Expected first ID of the new millisecond, got %+v", p)
+	}
+}
+
+// TestNextN tests batch generation and its clock re-read setting
+func TestNextN(t *testing.T) {
+	if ids := (&Generator{}).NextN(0); ids != nil {
+		t.Errorf("Expected nil for empty batch, got %v", ids)
+	}
+
+	// The mock clock advances 1ms on every read
+	const batch = 40000
+	distinctMs := func(every int) (int, []string) {
+		mockTime := time.Now().UnixMilli()
+		gen, _ := New(&Config{ShardID: 1, BatchClockEvery: every})
+		gen.deps.nowFunc = func() int64 {
+			mockTime++
+			return mockTime
+		}
+		ids := gen.NextN(batch)
+		ms := make(map[int64]struct{})
+		var prev uint64
+		for i, id := range ids {
+			val, _ := decode(id, DefaultLayout)
+			if i > 0 && val <= prev {
+				t.Fatalf("Batch not monotonic at index %d", i)
+			}
+			prev = val
+			p, _ := gen.Parse(id)
+			ms[p.Time.UnixMilli()] = struct{}{}
+		}
+		return len(ms), ids
+	}
+
+	// Test case 1: Clock read once, re-read only when the sequence runs out
+	n, ids := distinctMs(0)
+	if len(ids) != batch {
+		t.Fatalf("Expected %d IDs, got %d", batch, len(ids))
+	}
+	if n != 2 {
+		t.Errorf("Expected 2 distinct timestamps when reading once per batch, got %d", n)
+	}
+
+	// Test case 2: Clock re-read every 1000 IDs
+	if n, _ := distinctMs(1000); n != batch/1000 {
+		t.Errorf("Expected %d distinct timestamps, got %d", batch/1000, n)
+	}
+}
+
+// TestReserveBlock tests reserving blocks of consecutive IDs
+func TestReserveBlock(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	gen, _ := New(&Config{ShardID: 1, MaxBlockSize: 100000})
+	gen.deps.nowFunc = func() int64 {
+		mockTime++
+		return mockTime
+	}
+
+	// Test case 1: A block larger than one millisecond spills over, in order
+	const size = 3*(1<<15) + 10
+	block, err := gen.ReserveBlock(size)
+	if err != nil {
+		t.Fatalf("ReserveBlock failed: %v", err)
+	}
+	if len(block) != size {
+		t.Fatalf("Expected %d IDs, got %d", size, len(block))
+	}
+	seen := make(map[string]bool, size)
+	var prev uint64
+	for i, id := range block {
+		if seen[id] {
+			t.Fatalf("Duplicate ID %q in block", id)
+		}
+		seen[id] = true
+		val, _ := decode(id, DefaultLayout)
+		if i > 0 && val <= prev {
+			t.Fatalf("Block not ordered at index %d", i)
+		}
+		prev = val
+	}
+
+	// Test case 2: IDs after the block do not overlap it
+	for i := 0; i < 100; i++ {
+		id := gen.Next()
+		if seen[id] {
+			t.Fatalf("Next returned %q from the reserved block", id)
+		}
+		if val, _ := decode(id, DefaultLayout); val <= prev {
+			t.Fatalf("Next returned an ID ordered before the block's end")
+		}
+	}
+
+	// Test case 3: Invalid sizes
+	for _, n := range []int{0, -1, 100001} {
+		if _, err := gen.ReserveBlock(n); err == nil {
+			t.Errorf("ReserveBlock(%d): expected error, got nil", n)
+		}
+	}
+	def, _ := New(&Config{ShardID: 1})
+	if _, err := def.ReserveBlock(1<<16 + 1); err == nil {
+		t.Error("Expected default maximum of 65536 to be enforced")
+	}
+	if _, err := New(&Config{ShardID: 1, MaxBlockSize: -1}); err == nil {
+		t.Error("Expected error for negative MaxBlockSize, got nil")
+	}
+}
+
+// TestWaitUntilEpoch tests waiting for a future epoch
+func TestWaitUntilEpoch(t *testing.T) {
+	start := time.Now().UnixMilli()
+	var mockTime atomic.Int64
+	mockTime.Store(start)
+	epoch := start + 5
+	gen, _ := New(&Config{ShardID: 1, CustomEpochMs: epoch})
+	gen.deps.nowFunc = mockTime.Load
+
+	// Test case 1: A cancelled context stops the wait
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	if err := gen.WaitUntilEpoch(ctx); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+
+	// Test case 2: The wait returns once the clock reaches the epoch
+	go func() {
+		for i := int64(1); i <= 5; i++ {
+			time.Sleep(2 * time.Millisecond)
+			mockTime.Store(start + i)
+		}
+	}()
+	ctx, cancel = context.WithTimeout(context.Background(), 5*time.Second)
+	defer cancel()
+	if err := gen.WaitUntilEpoch(ctx); err != nil {
+		t.Fatalf("WaitUntilEpoch failed: %v", err)
+	}
+	if now := mockTime.Load(); now < epoch {
+		t.Errorf("WaitUntilEpoch returned at %d, before epoch %d", now, epoch)
+	}
+	parts, err := gen.Parse(gen.Next())
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if parts.Time.UnixMilli() != epoch {
+		t.Errorf("Expected ID at the epoch, got %v", parts.Time.UnixMilli())
+	}
+
+	// Test case 3: A past epoch returns immediately
+	if err := gen.WaitUntilEpoch(context.Background()); err != nil {
+		t.Errorf("Expected no wait after the epoch, got %v", err)
+	}
+}
+
+// TestBeforeEpoch tests that a clock before the epoch is waited out
+// rather than reported as clock drift
+func TestBeforeEpoch(t *testing.T) {
+	const epoch = 1_800_000_000_000
+	var drifts atomic.Int32
+	for _, lockFree := range []bool{false, true} {
+		var mockTime atomic.Int64
+		mockTime.Store(epoch - 3)
+		gen, _ := New(&Config{
+			ShardID:          1,
+			CustomEpochMs:    epoch,
+			LockFree:         lockFree,
+			ClockDriftPolicy: ClockDriftError,
+			OnClockDrift:     func(time.Duration) { drifts.Add(1) },
+		})
+		gen.deps.nowFunc = mockTime.Load
+
+		// Test case 1: Failing calls report ErrBeforeEpoch
+		if _, err := gen.TryNext(); err != ErrBeforeEpoch {
+			t.Errorf("Expected ErrBeforeEpoch from TryNext, got %v", err)
+		}
+		ctx, cancel := context.WithCancel(context.Background())
+		cancel()
+		if _, err := gen.NextCtx(ctx); !errors.Is(err, context.Canceled) {
+			t.Errorf("Expected context.Canceled, got %v", err)
+		}
+
+		// Test case 2: NextE waits for the epoch
+		go func() {
+			time.Sleep(2 * time.Millisecond)
+			mockTime.Store(epoch)
+		}()
+		id, err := gen.NextE()
+		if err != nil {
+			t.Fatalf("NextE failed: %v", err)
+		}
+		if p, _ := gen.Parse(id); p.Time.UnixMilli() != epoch {
+			t.Errorf("Expected an ID at the epoch, got %v", p.Time)
+		}
+		if s := gen.Stats(); s.ClockBackwards != 0 || s.Generated != 1 {
+			t.Errorf("Unexpected stats %+v with lockFree %v", s, lockFree)
+		}
+	}
+
+	// Test case 3: Keyed IDs also wait without counting drift
+	var mockTime atomic.Int64
+	mockTime.Store(epoch - 3)
+	gen, _ := New(&Config{ShardID: 1, CustomEpochMs: epoch})
+	gen.deps.nowFunc = mockTime.Load
+	go func() {
+		time.Sleep(2 * time.Millisecond)
+		mockTime.Store(epoch)
+	}()
+	if p, _ := gen.Parse(gen.NextForKey([]byte("k"))); p.Time.UnixMilli() != epoch {
+		t.Errorf("Expected a keyed ID at the epoch, got %v", p.Time)
+	}
+	if s := gen.Stats(); s.ClockBackwards != 0 || drifts.Load() != 0 {
+		t.Errorf("Unexpected stats %+v after %d drift callbacks", s, drifts.Load())
+	}
+}
+
 // TestClockDrift tests handling of the system clock moving backwards
 func TestClockDrift(t *testing.T) {
 	mockTime := time.Now().UnixMilli()
@@ -297,24 +1182,136 @@ func TestClockDrift(t *testing.T) {
 func TestSpinUntilNextMs(t *testing.T) {
 	baseEpoch := int64(1000)
 	lastMs := int64(500)
-	now := lastMs + baseEpoch
-
-	nowFunc := func() int64 {
-		return now
-	}
+	var now atomic.Int64
+	now.Store(lastMs + baseEpoch)
 
 	go func() {
 		time.Sleep(15 * time.Millisecond)
-		now++
+		now.Add(1)
 	}()
 
-	spinUntilNextMs(baseEpoch, lastMs, nowFunc)
+	spinUntilNextMs(baseEpoch, lastMs, now.Load, defaultSpinSleep)
 
-	if now-baseEpoch <= lastMs {
+	if now.Load()-baseEpoch <= lastMs {
 		t.Error("spinUntilNextMs returned before time advanced")
 	}
 }
 
+// TestSpinSleep tests the configurable sleep granularity of the spin loop
+func TestSpinSleep(t *testing.T) {
+	// Resolution of the configured value
+	cases := []struct {
+		in   time.Duration
+		goos string
+		want time.Duration
+	}{
+		{0, "linux", defaultSpinSleep},
+		{0, "windows", 0},
+		{-1, "linux", 0},
+		{time.Millisecond, "windows", time.Millisecond},
+	}
+	for _, c := range cases {
+		if got := resolveSpinSleep(c.in, c.goos); got != c.want {
+			t.Errorf("resolveSpinSleep(%v, %q) = %v, want %v", c.in, c.goos, got, c.want)
+		}
+	}
+
+	gen, _ := New(&Config{ShardID: 1, SpinSleep: -1})
+	if gen.spinSleep != 0 {
+		t.Errorf("Expected spin sleep disabled, got %v", gen.spinSleep)
+	}
+
+	// With the sleep disabled the loop exits as soon as the clock advances
+	var polls int
+	nowFunc := func() int64 {
+		polls++
+		if polls > 3 {
+			return 1001
+		}
+		return 1000
+	}
+	start := time.Now()
+	spinUntilNextMs(0, 1000, nowFunc, 0)
+	if polls != 4 {
+		t.Errorf("Expected loop to exit on the first advanced reading, polled %d times", polls)
+	}
+	if elapsed := time.Since(start); elapsed > 100*time.Millisecond {
+		t.Errorf("Spin loop took too long to exit: %v", elapsed)
+	}
+}
+
+// TestState tests the debugging snapshot of the generator's position
+func TestState(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	gen, _ := New(&Config{ShardID: 1})
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	// Test case 1: Before the first ID the state is at the epoch
+	if ms, seq := gen.State(); ms != defaultEpochMs || seq != 0 {
+		t.Errorf("Expected initial state (%d, 0), got (%d, %d)", defaultEpochMs, ms, seq)
+	}
+
+	// Test case 2: The sequence counts IDs within the millisecond
+	for i := 0; i < 5; i++ {
+		gen.Next()
+	}
+	if ms, seq := gen.State(); ms != mockTime || seq != 4 {
+		t.Errorf("Expected state (%d, 4), got (%d, %d)", mockTime, ms, seq)
+	}
+
+	// Test case 3: A new millisecond resets the sequence
+	mockTime += 3
+	gen.Next()
+	if ms, seq := gen.State(); ms != mockTime || seq != 0 {
+		t.Errorf("Expected state (%d, 0), got (%d, %d)", mockTime, ms, seq)
+	}
+}
+
+// TestStats tests the activity counters exposed by Stats
+func TestStats(t *testing.T) {
+	var mockTime atomic.Int64
+	mockTime.Store(time.Now().UnixMilli())
+	gen, _ := New(&Config{ShardID: 1, Name: "test"})
+	gen.deps.nowFunc = mockTime.Load
+
+	if gen.Name() != "test" {
+		t.Errorf("Expected name 'test', got '%s'", gen.Name())
+	}
+
+	_ = gen.Next()
+	_ = gen.Next()
+
+	// Move clock backwards
+	mockTime.Add(-1)
+	_ = gen.Next()
+
+	s := gen.Stats()
+	if s.Generated != 3 {
+		t.Errorf("Expected 3 generated, got %d", s.Generated)
+	}
+	if s.ClockBackwards != 1 {
+		t.Errorf("Expected 1 clock-backwards event, got %d", s.ClockBackwards)
+	}
+	if s.Rollovers != 0 {
+		t.Errorf("Expected 0 rollovers, got %d", s.Rollovers)
+	}
+
+	// Exhaust the sequence at a fresh millisecond and force a rollover
+	mockTime.Add(2)
+	for i := 0; i < 1<<15; i++ {
+		_ = gen.Next()
+	}
+	go func() {
+		time.Sleep(5 * time.Millisecond)
+		mockTime.Add(1)
+	}()
+	_ = gen.Next()
+
+	if s := gen.Stats(); s.Rollovers != 1 {
+		t.Errorf("Expected 1 rollover, got %d", s.Rollovers)
+	}
+}
+
 func BenchmarkNextID(b *testing.B) {
 	gen, _ := New(&Config{ShardID: 1})
 	b.ResetTimer()
@@ -322,3 +1319,107 @@ func BenchmarkNextID(b *testing.B) {
 		_ = gen.Next()
 	}
 }
+
+// TestBurstOverflow tests absorbing bursts in the reserved bits without waiting
+func TestBurstOverflow(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	layout := Layout{TimestampBits: 39, ShardBits: 10, SequenceBits: 4, ReservedBits: 8}
+	gen, err := New(&Config{ShardID: 3, Layout: layout, BurstOverflow: true})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	// Test case 1: A concurrent burst beyond the sequence never waits.
+	// The clock is frozen, so any wait would hang; TryNext fails
+	// instead of waiting.
+	capacity := layout.MaxSequence() + 1 + int(layout.MaxSalt())
+	ids := make(chan string, capacity)
+	var wg sync.WaitGroup
+	for w := 0; w < 8; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for {
+				id, err := gen.TryNext()
+				if err != nil {
+					return
+				}
+				ids <- id
+			}
+		}()
+	}
+	wg.Wait()
+	close(ids)
+
+	seen := make(map[string]bool)
+	overflowed := 0
+	for id := range ids {
+		if seen[id] {
+			t.Errorf("Duplicate ID %q", id)
+		}
+		seen[id] = true
+		p, err := gen.Parse(id)
+		if err != nil {
+			t.Fatalf("Parse(%q) failed: %v", id, err)
+		}
+		if p.Overflow != 0 {
+			overflowed++
+			if int(p.Seq) != layout.MaxSequence() {
+				t.Errorf("Overflow ID %q has seq %d, expected %d", id, p.Seq, layout.MaxSequence())
+			}
+		}
+		if p.Shard != 3 || p.Salt != 0 || p.Time.UnixMilli() != mockTime {
+			t.Errorf("Unexpected parts for %q: %+v", id, p)
+		}
+	}
+	if len(seen) != capacity || overflowed != int(layout.MaxSalt()) {
+		t.Errorf("Expected %d IDs with %d overflowed, got %d and %d", capacity, layout.MaxSalt(), len(seen), overflowed)
+	}
+	if s := gen.Stats(); s.Rollovers != 0 || s.Overflows != layout.MaxSalt() {
+		t.Errorf("Unexpected stats after burst: %+v", s)
+	}
+
+	// Test case 2: Overflow IDs sort after the rest of their millisecond
+	mockTime++
+	var last uint64
+	for i := 0; i < capacity; i++ {
+		id := gen.Next()
+		val, err := gen.codec.decode(id, layout)
+		if err != nil {
+			t.Fatalf("decode(%q) failed: %v", id, err)
+		}
+		if i > 0 && val <= last {
+			t.Fatalf("ID %d (%q) does not sort after its predecessor", i, id)
+		}
+		last = val
+	}
+
+	// Test case 3: Exported state keeps the spent overflow count
+	js, err := gen.ExportJSON()
+	if err != nil {
+		t.Fatalf("ExportJSON failed: %v", err)
+	}
+	imported, err := ImportJSON(js)
+	if err != nil {
+		t.Fatalf("ImportJSON failed: %v", err)
+	}
+	imported.deps.nowFunc = func() int64 { return mockTime }
+	if _, err := imported.TryNext(); err != ErrSequenceExhausted {
+		t.Errorf("Expected ErrSequenceExhausted after import, got %v", err)
+	}
+
+	// Test case 4: The overflow count resets on the next millisecond
+	mockTime++
+	if p, _ := gen.Parse(gen.Next()); p.Overflow != 0 || p.Seq != 0 {
+		t.Errorf("Expected overflow to reset, got %+v", p)
+	}
+
+	// Test case 5: Invalid combinations
+	if _, err := New(&Config{ShardID: 1, BurstOverflow: true}); err == nil {
+		t.Error("Expected error for BurstOverflow without reserved bits, got nil")
+	}
+	if _, err := New(&Config{ShardID: 1, Layout: layout, BurstOverflow: true, Salt: 1}); err == nil {
+		t.Error("Expected error for BurstOverflow with Salt, got nil")
+	}
+}
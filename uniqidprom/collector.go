@@ -0,0 +1,65 @@
+// Package uniqidprom exports uniqid generator counters as Prometheus
+// metrics.
+//
+// It lives in its own module so that the core uniqid module stays
+// free of third-party dependencies.
+//
+// Example:
+//
+//	gen, _ := uniqid.New(&uniqid.Config{ShardID: 1, Name: "orders"})
+//	prometheus.MustRegister(uniqidprom.NewCollector(gen))
+package uniqidprom
+
+import (
+	"github.com/aprakasa/uniqid"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+var (
+	generatedDesc = prometheus.NewDesc(
+		"uniqid_generated_total",
+		"Total number of IDs generated.",
+		[]string{"generator"}, nil,
+	)
+	rolloversDesc = prometheus.NewDesc(
+		"uniqid_sequence_rollovers_total",
+		"Times the per-millisecond sequence was exhausted.",
+		[]string{"generator"}, nil,
+	)
+	clockBackwardsDesc = prometheus.NewDesc(
+		"uniqid_clock_backwards_total",
+		"Times the system clock was observed moving backwards.",
+		[]string{"generator"}, nil,
+	)
+)
+
+// Collector implements prometheus.Collector for one or more generators.
+// Each generator is labeled by its Name.
+type Collector struct {
+	gens []*uniqid.Generator
+}
+
+// NewCollector returns a Collector reporting the counters of gens.
+// Generators should have distinct names; Prometheus rejects duplicate
+// label sets at scrape time.
+func NewCollector(gens ...*uniqid.Generator) *Collector {
+	return &Collector{gens: gens}
+}
+
+// Describe implements prometheus.Collector.
+func (c *Collector) Describe(ch chan<- *prometheus.Desc) {
+	ch <- generatedDesc
+	ch <- rolloversDesc
+	ch <- clockBackwardsDesc
+}
+
+// Collect implements prometheus.Collector.
+func (c *Collector) Collect(ch chan<- prometheus.Metric) {
+	for _, g := range c.gens {
+		s := g.Stats()
+		name := g.Name()
+		ch <- prometheus.MustNewConstMetric(generatedDesc, prometheus.CounterValue, float64(s.Generated), name)
+		ch <- prometheus.MustNewConstMetric(rolloversDesc, prometheus.CounterValue, float64(s.Rollovers), name)
+		ch <- prometheus.MustNewConstMetric(clockBackwardsDesc, prometheus.CounterValue, float64(s.ClockBackwards), name)
+	}
+}
@@ -0,0 +1,48 @@
+package uniqidprom
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/aprakasa/uniqid"
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/testutil"
+)
+
+// TestCollector scrapes the collector after generating IDs
+func TestCollector(t *testing.T) {
+	gen, err := uniqid.New(&uniqid.Config{ShardID: 1, Name: "orders"})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	for i := 0; i < 5; i++ {
+		_ = gen.Next()
+	}
+
+	reg := prometheus.NewPedanticRegistry()
+	if err := reg.Register(NewCollector(gen)); err != nil {
+		t.Fatalf("Register failed: %v", err)
+	}
+
+	expected := `
+# HELP uniqid_clock_backwards_total Times the system clock was observed moving backwards.
+# TYPE uniqid_clock_backwards_total counter
+uniqid_clock_backwards_total{generator="orders"} 0
+# HELP uniqid_generated_total Total number of IDs generated.
+# TYPE uniqid_generated_total counter
+uniqid_generated_total{generator="orders"} 5
+# HELP uniqid_sequence_rollovers_total Times the per-millisecond sequence was exhausted.
+# TYPE uniqid_sequence_rollovers_total counter
+uniqid_sequence_rollovers_total{generator="orders"} 0
+`
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected)); err != nil {
+		t.Errorf("Unexpected metrics: %v", err)
+	}
+
+	// A second scrape reflects IDs generated since the first one
+	_ = gen.Next()
+	expected = strings.Replace(expected, `{generator="orders"} 5`, `{generator="orders"} 6`, 1)
+	if err := testutil.GatherAndCompare(reg, strings.NewReader(expected)); err != nil {
+		t.Errorf("Unexpected metrics after second scrape: %v", err)
+	}
+}
@@ -0,0 +1,56 @@
+package uniqid
+
+import (
+	"encoding/binary"
+	"encoding/hex"
+)
+
+// NextUUIDv7 generates a new ID as an RFC 9562 version 7 UUID, for
+// native UUID columns and ORMs that expect standard v7 values. Like
+// NextULID it is built from the generator's clock and sequence: the
+// 48-bit Unix millisecond timestamp, then, around the version and
+// variant bits, the shard and sequence in 16 bits each and 42 random
+// bits from the generator's random source. UUIDs from one generator
+// are unique and strictly increasing, byte-wise and as strings.
+//
+// Unlike NextUUIDBytes, the result is a valid UUID, but it cannot be
+// converted back to an ID of this package.
+//
+// It returns an error only if the random source fails.
+func (g *Generator) NextUUIDv7() ([16]byte, error) {
+	var u [16]byte
+	var random [6]byte
+	p, err := g.nextRandomized(random[:])
+	if err != nil {
+		return u, err
+	}
+	r := uint64(random[0])<<40 | uint64(random[1])<<32 | uint64(random[2])<<24 |
+		uint64(random[3])<<16 | uint64(random[4])<<8 | uint64(random[5])
+	// unix_ts_ms, version 7, then the top 12 shard bits as rand_a.
+	binary.BigEndian.PutUint64(u[:8], uint64(p.Time.UnixMilli())<<16|0x7<<12|uint64(p.Shard>>4))
+	// Variant 10, then the low 4 shard bits, the sequence and 42
+	// random bits as rand_b.
+	binary.BigEndian.PutUint64(u[8:], 0x2<<62|uint64(p.Shard&0xF)<<58|uint64(p.Seq)<<42|r>>6)
+	return u, nil
+}
+
+// NextUUIDv7String is like NextUUIDv7 but returns the UUID in its
+// canonical 36-character text form, e.g.
+// "0190163d-8694-739b-aea5-966c26f8ad91".
+func (g *Generator) NextUUIDv7String() (string, error) {
+	u, err := g.NextUUIDv7()
+	if err != nil {
+		return "", err
+	}
+	var out [36]byte
+	hex.Encode(out[0:8], u[0:4])
+	out[8] = '-'
+	hex.Encode(out[9:13], u[4:6])
+	out[13] = '-'
+	hex.Encode(out[14:18], u[6:8])
+	out[18] = '-'
+	hex.Encode(out[19:23], u[8:10])
+	out[23] = '-'
+	hex.Encode(out[24:], u[10:])
+	return string(out[:]), nil
+}
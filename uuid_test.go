@@ -0,0 +1,72 @@
+package uniqid
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"regexp"
+	"testing"
+	"time"
+)
+
+// TestNextUUIDv7 tests generating RFC 9562 version 7 UUIDs
+func TestNextUUIDv7(t *testing.T) {
+	mockTime := time.Now().UnixMilli()
+	gen, err := New(&Config{ShardID: 1000})
+	if err != nil {
+		t.Fatalf("New failed: %v", err)
+	}
+	gen.deps.nowFunc = func() int64 { return mockTime }
+
+	// Test case 1: Version, variant and timestamp follow RFC 9562, and
+	// UUIDs increase
+	var prev [16]byte
+	for i := 0; i < 2000; i++ {
+		if i%500 == 0 {
+			mockTime++
+		}
+		u, err := gen.NextUUIDv7()
+		if err != nil {
+			t.Fatalf("NextUUIDv7 failed: %v", err)
+		}
+		if u[6]>>4 != 7 || u[8]>>6 != 2 {
+			t.Fatalf("UUID %x has wrong version or variant", u)
+		}
+		if ms := int64(binary.BigEndian.Uint64(u[:8]) >> 16); ms != mockTime {
+			t.Fatalf("UUID %x has time %d, expected %d", u, ms, mockTime)
+		}
+		if bytes.Compare(u[:], prev[:]) <= 0 {
+			t.Fatalf("UUID %x does not sort after %x", u, prev)
+		}
+		prev = u
+	}
+
+	// Test case 2: Shard and sequence are embedded
+	zero, _ := New(&Config{ShardID: 1000, RandReader: bytes.NewReader(make([]byte, 12))})
+	zero.deps.nowFunc = gen.deps.nowFunc
+	zero.NextUUIDv7()
+	u, _ := zero.NextUUIDv7()
+	shard := binary.BigEndian.Uint16(u[6:8])&0xFFF<<4 | uint16(u[8]>>2&0xF)
+	seq := binary.BigEndian.Uint64(u[8:]) >> 42 & 0xFFFF
+	if shard != 1000 || seq != 1 {
+		t.Errorf("Expected shard 1000 and seq 1, got %d and %d", shard, seq)
+	}
+	if rnd := binary.BigEndian.Uint64(u[8:]) & (1<<42 - 1); rnd != 0 {
+		t.Errorf("Expected zero random bits, got %x", rnd)
+	}
+
+	// Test case 3: The string form is canonical
+	s, err := gen.NextUUIDv7String()
+	if err != nil {
+		t.Fatalf("NextUUIDv7String failed: %v", err)
+	}
+	if !regexp.MustCompile(`^[0-9a-f]{8}-[0-9a-f]{4}-7[0-9a-f]{3}-[89ab][0-9a-f]{3}-[0-9a-f]{12}$`).MatchString(s) {
+		t.Errorf("Invalid UUID string %q", s)
+	}
+
+	// Test case 4: Random source failures are reported
+	gen.deps.randFunc = func([]byte) (int, error) { return 0, errors.New("rand error") }
+	if _, err := gen.NextUUIDv7String(); err == nil {
+		t.Error("Expected error from failing random source, got nil")
+	}
+}
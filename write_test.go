@@ -0,0 +1,87 @@
+package uniqid
+
+import (
+	"bytes"
+	"errors"
+	"testing"
+)
+
+// TestLen tests the reported ID length
+func TestLen(t *testing.T) {
+	cases := []struct {
+		cfg  *Config
+		want int
+	}{
+		{&Config{ShardID: 1}, 11},
+		{&Config{ShardID: 1, VersionPrefix: 'v'}, 12},
+		{&Config{NoShard: true}, 10},
+	}
+	for _, c := range cases {
+		gen, _ := New(c.cfg)
+		if got := gen.Len(); got != c.want || len(gen.Next()) != c.want {
+			t.Errorf("Expected length %d for %+v, got %d", c.want, c.cfg, got)
+		}
+	}
+}
+
+// failingWriter accepts limit bytes, then fails.
+type failingWriter struct{ limit int }
+
+func (w *failingWriter) Write(p []byte) (int, error) {
+	if len(p) > w.limit {
+		n := w.limit
+		w.limit = 0
+		return n, errors.New("disk full")
+	}
+	w.limit -= len(p)
+	return len(p), nil
+}
+
+// TestWriteFixed tests writing fixed-width ID records
+func TestWriteFixed(t *testing.T) {
+	gen, _ := New(&Config{ShardID: 1, VersionPrefix: 'v'})
+
+	// Test case 1: Records are fixed-width and readable by offset
+	var buf bytes.Buffer
+	const n = 2500
+	written, err := gen.WriteFixed(&buf, n)
+	if err != nil {
+		t.Fatalf("WriteFixed failed: %v", err)
+	}
+	size := gen.Len()
+	if written != int64(n*size) || buf.Len() != n*size {
+		t.Fatalf("Expected %d bytes, wrote %d (buffer %d)", n*size, written, buf.Len())
+	}
+	data := buf.Bytes()
+	var prev Parts
+	for _, k := range []int{0, 1, 1023, 1024, 2499} {
+		rec := string(data[k*size : (k+1)*size])
+		p, err := gen.Parse(rec)
+		if err != nil {
+			t.Fatalf("Record %d (%q) failed to parse: %v", k, rec, err)
+		}
+		if k > 0 && p.Time.Before(prev.Time) {
+			t.Errorf("Record %d is earlier than a previous record", k)
+		}
+		prev = p
+	}
+	seen := make(map[string]bool, n)
+	for k := 0; k < n; k++ {
+		rec := string(data[k*size : (k+1)*size])
+		if seen[rec] {
+			t.Fatalf("Duplicate record %q", rec)
+		}
+		seen[rec] = true
+	}
+
+	// Test case 2: Nothing is written for n <= 0
+	if written, err := gen.WriteFixed(&buf, 0); written != 0 || err != nil {
+		t.Errorf("Expected no-op for n=0, got %d, %v", written, err)
+	}
+
+	// Test case 3: Write errors stop the stream
+	written, err = gen.WriteFixed(&failingWriter{limit: 1500 * size}, n)
+	if err == nil || written != int64(1500*size) {
+		t.Errorf("Expected error after %d bytes, got %d, %v", 1500*size, written, err)
+	}
+}